@@ -0,0 +1,75 @@
+package byteair
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// DataCallback reports the result of data written by DataBufferedWriter,
+// err is nil if the data is accepted by server
+type DataCallback func(data map[string]interface{}, err error)
+
+// NewDataBufferedWriter creates a writer that buffers single data of topic,
+// and writes them in batch through client.WriteData.
+// The writer is drained when client released, or it could be closed by caller.
+// The opts are used by every flush, so it should not contain request id.
+func NewDataBufferedWriter(client Client, topic string, config *BufferedWriterConfig,
+	callback DataCallback, opts ...option.Option) *DataBufferedWriter {
+	writer := &DataBufferedWriter{client: client, topic: topic, opts: opts}
+	var recordCallback RecordCallback
+	if callback != nil {
+		recordCallback = func(record interface{}, err error) {
+			callback(record.(map[string]interface{}), err)
+		}
+	}
+	writer.writer = NewBufferedWriter(config, MaxWriteItemCount, writer.flush, sizeOfData, recordCallback)
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer
+}
+
+type DataBufferedWriter struct {
+	client Client
+	topic  string
+	opts   []option.Option
+	writer *BufferedWriter
+}
+
+// Write puts the data into buffer, it blocks when buffer is full
+func (receiver *DataBufferedWriter) Write(data map[string]interface{}) error {
+	return receiver.writer.Write(data)
+}
+
+// Close flushes all buffered data, and stops accepting new ones
+func (receiver *DataBufferedWriter) Close() {
+	receiver.writer.Close()
+}
+
+func (receiver *DataBufferedWriter) flush(records []interface{}) []error {
+	dataList := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		dataList[i] = record.(map[string]interface{})
+	}
+	// records are no more than one chunk, chunk error is also reported in response errors
+	response, _ := ChunkWriteData(receiver.client, dataList, receiver.topic, 1, receiver.opts...)
+	errs := make([]error, len(records))
+	for _, dataError := range response.Errors {
+		if dataError.Index < 0 {
+			logs.Warn("data error can't match any written one, msg:%s data:%s",
+				dataError.Message, dataError.Data)
+			continue
+		}
+		errs[dataError.Index] = errors.New(dataError.Message)
+	}
+	return errs
+}
+
+func sizeOfData(record interface{}) int {
+	bytes, _ := json.Marshal(record)
+	return len(bytes)
+}
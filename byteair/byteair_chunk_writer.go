@@ -0,0 +1,64 @@
+package byteair
+
+import (
+	"encoding/json"
+
+	. "github.com/byteplus-sdk/sdk-go/byteair/protocol"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// IndexedDataError is DataError with the index of data in the
+// caller's dataList, Index is -1 if it can't be matched
+type IndexedDataError struct {
+	Index int
+	*DataError
+}
+
+type ChunkWriteDataResponse struct {
+	// The first failure status of chunks, or success status if all chunks succeed
+	Status *Status
+	Errors []*IndexedDataError
+}
+
+// ChunkWriteData
+//
+// Splits dataList into chunks of at most 100 data, writes the chunks with at most
+// `concurrency` goroutines, then merges the responses of all chunks.
+// If a whole chunk fails, every data of it is reported in response errors,
+// and the first chunk error is returned together with the merged response.
+func ChunkWriteData(client Client, dataList []map[string]interface{}, topic string,
+	concurrency int, opts ...option.Option) (*ChunkWriteDataResponse, error) {
+	responses := make([]*WriteResponse, ChunkCount(len(dataList), MaxWriteItemCount))
+	result := &ChunkWriteDataResponse{}
+	var err error
+	result.Status, err = WriteChunks(len(dataList), MaxWriteItemCount, concurrency, opts, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*Status, int, error) {
+			response, err := client.WriteData(dataList[chunk.Start:chunk.End], topic, opts...)
+			responses[chunk.Index] = response
+			return response.GetStatus(), len(response.GetErrors()), err
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			for i := chunk.Start; i < chunk.End; i++ {
+				data, _ := json.Marshal(dataList[i])
+				dataError := &DataError{Message: message, Data: string(data)}
+				result.Errors = append(result.Errors, &IndexedDataError{Index: i, DataError: dataError})
+			}
+		},
+		CollectErrors: func(chunk Chunk) {
+			dataErrors := responses[chunk.Index].GetErrors()
+			failedData := make([]string, len(dataErrors))
+			for i, dataError := range dataErrors {
+				failedData[i] = dataError.GetData()
+			}
+			indexes := MatchJsonIndexes(chunk, failedData, func(itemIdx int) interface{} {
+				return NormalizeJson(dataList[itemIdx])
+			})
+			for i, dataError := range dataErrors {
+				result.Errors = append(result.Errors, &IndexedDataError{Index: indexes[i], DataError: dataError})
+			}
+		},
+	})
+	return result, err
+}
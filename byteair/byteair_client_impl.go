@@ -1,7 +1,7 @@
 package byteair
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 
@@ -19,18 +19,52 @@ const (
 
 var (
 	errMsgFormat    = "Only can receive max to %d items in one request"
-	TooManyItemsErr = errors.New(fmt.Sprintf(errMsgFormat, MaxImportItemCount))
+	TooManyItemsErr = NewPermanentError(fmt.Sprintf(errMsgFormat, MaxImportItemCount))
 )
 
+func init() {
+	common.RegisterOperationResponseType(&ImportResponse{})
+}
+
 type clientImpl struct {
 	common.Client
 	hCaller *HttpCaller
 	gu      *byteairURL
 	hostAva *HostAvailabler
+	scenes  *SceneRegistry
+	schemas map[string]*TopicSchema
+	// drain the components depending on client when released
+	hooks ReleaseHooks
 }
 
 func (c *clientImpl) Release() {
-	c.hostAva.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReleaseTimeout)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		logs.Warn("release client fail, err:%s", err.Error())
+	}
+}
+
+func (c *clientImpl) Close(ctx context.Context) error {
+	return CloseClient(ctx, &c.hooks, c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) Diagnostics() *Diagnostics {
+	return NewDiagnostics(c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) SceneRegistry() *SceneRegistry {
+	return c.scenes
+}
+
+// coerceData checks and converts data by the schema of topic,
+// the data is returned as it is if topic has no schema
+func (c *clientImpl) coerceData(dataList []map[string]interface{}, topic string) ([]map[string]interface{}, error) {
+	schema, ok := c.schemas[topic]
+	if !ok {
+		return dataList, nil
+	}
+	return schema.CoerceAll(dataList)
 }
 
 func (c *clientImpl) WriteData(dataList []map[string]interface{}, topic string,
@@ -41,10 +75,14 @@ func (c *clientImpl) WriteData(dataList []map[string]interface{}, topic string,
 			return nil, TooManyItemsErr
 		}
 	}
+	dataList, err := c.coerceData(dataList, topic)
+	if err != nil {
+		return nil, err
+	}
 	urlFormat := c.gu.writeDataURLFormat
 	url := strings.ReplaceAll(urlFormat, "{}", topic)
 	response := &WriteResponse{}
-	err := c.hCaller.DoJsonRequest(url, dataList, response, option.Conv2Options(opts...))
+	err = c.hCaller.DoJsonRequest(url, dataList, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -54,16 +92,33 @@ func (c *clientImpl) WriteData(dataList []map[string]interface{}, topic string,
 
 func (c *clientImpl) Predict(request *PredictRequest,
 	opts ...option.Option) (*PredictResponse, error) {
-	urlFormat := c.gu.predictUrlFormat
 	//The options conversion should be placed in xxx_client_impl,
 	//so that each client_impl could do some special processing according to options
-	options := option.Conv2Options(opts...)
-	scene := options.Scene
-	// If predict scene option is not filled, add default value
+	scene := c.hCaller.Options(opts...).Scene
+	// If predict scene option is not filled, add default value,
+	// which should also be declared if scenes are declared
 	if scene == "" {
 		scene = DefaultPredictScene
 	}
-	url := strings.ReplaceAll(urlFormat, "{}", scene)
+	var response *PredictResponse
+	err := c.scenes.Execute(scene, func(sceneConf *SceneConfig) error {
+		var err error
+		response, err = c.doPredict(ApplySceneDefaults(request, sceneConf).(*PredictRequest), sceneConf, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *clientImpl) doPredict(request *PredictRequest, scene *SceneConfig,
+	opts []option.Option) (*PredictResponse, error) {
+	urlFormat := c.gu.predictUrlFormat
+	options := c.hCaller.Options(scene.WithOptions(opts)...)
+	// Scene option is overwritten by the resolved one, it may be a fallback scene
+	options.Scene = scene.Name
+	url := strings.ReplaceAll(urlFormat, "{}", scene.Name)
 	response := &PredictResponse{}
 	err := c.hCaller.DoPbRequest(url, request, response, options)
 	if err != nil {
@@ -81,7 +136,7 @@ func (c *clientImpl) Callback(request *CallbackRequest,
 	if request.Scene == "" {
 		request.Scene = DefaultCallbackScene
 	}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package byteair
+
+import (
+	"encoding/json"
+
+	. "github.com/byteplus-sdk/sdk-go/byteair/protocol"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// NewDataDeduper
+//
+// Creates a deduper dropping the data sent within the window of config,
+// which is used for the user event topics, as the server doesn't deduplicate them.
+// Key is DataKeyFields(DefaultDataKeyFields...) if nil, the data of different
+// topics never duplicate each other.
+func NewDataDeduper(client Client, config *DedupConfig, key DataKeyFunc) *DataDeduper {
+	if key == nil {
+		key = DataKeyFields(DefaultDataKeyFields...)
+	}
+	return &DataDeduper{client: client, key: key, deduplicator: NewDeduplicator(config)}
+}
+
+// DataDeduper drops duplicated data before WriteData,
+// the data failed to write are forgotten, so that they could be retried
+type DataDeduper struct {
+	client       Client
+	key          DataKeyFunc
+	deduplicator *Deduplicator
+}
+
+// WriteData writes the data not sent before,
+// success is returned without calling server if all of them are duplicated
+func (receiver *DataDeduper) WriteData(dataList []map[string]interface{}, topic string,
+	opts ...option.Option) (*WriteResponse, error) {
+	keys := make([]string, len(dataList))
+	for i, data := range dataList {
+		keys[i] = JoinDedupKey(topic, receiver.key(data))
+	}
+	var response *WriteResponse
+	sent, err := receiver.deduplicator.Write(keys, func(kept []int) (*Status, []string, error) {
+		keptData := make([]map[string]interface{}, len(kept))
+		for i, idx := range kept {
+			keptData[i] = dataList[idx]
+		}
+		var err error
+		response, err = receiver.client.WriteData(keptData, topic, opts...)
+		var failedKeys []string
+		for _, dataError := range response.GetErrors() {
+			var data map[string]interface{}
+			if json.Unmarshal([]byte(dataError.GetData()), &data) == nil {
+				failedKeys = append(failedKeys, JoinDedupKey(topic, receiver.key(data)))
+			}
+		}
+		return response.GetStatus(), failedKeys, err
+	})
+	if !sent {
+		return &WriteResponse{Status: &Status{Code: StatusCodeSuccess}}, nil
+	}
+	return response, err
+}
+
+// Dropped returns the count of duplicated data dropped
+func (receiver *DataDeduper) Dropped() int64 {
+	return receiver.deduplicator.Dropped()
+}
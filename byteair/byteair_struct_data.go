@@ -0,0 +1,24 @@
+package byteair
+
+import (
+	. "github.com/byteplus-sdk/sdk-go/byteair/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// WriteStructs encodes a slice of structs by their `data` tags,
+// then writes them into topic, see core.EncodeData for the tags
+func WriteStructs(client Client, items interface{}, topic string,
+	opts ...option.Option) (*WriteResponse, error) {
+	dataList, err := EncodeData(items)
+	if err != nil {
+		return nil, err
+	}
+	return client.WriteData(dataList, topic, opts...)
+}
+
+// DecodeDataError decodes the failed data into item,
+// which should be a pointer of the struct written
+func DecodeDataError(dataError *DataError, item interface{}) error {
+	return DecodeData(dataError.GetData(), item)
+}
@@ -0,0 +1,49 @@
+package byteair
+
+import (
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/byteair/protocol"
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+func NewSyncSession(client Client, topic string, date time.Time,
+	store common.SyncStateStore) (*SyncSession, error) {
+	session, err := common.NewSyncSession(client, topic, date, store)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncSession{SyncSession: session, client: client}, nil
+}
+
+// SyncSession stamps the writes of a topic with the date,
+// the end of date is only sent by Finalize.
+type SyncSession struct {
+	*common.SyncSession
+	client Client
+}
+
+func (receiver *SyncSession) WriteData(dataList []map[string]interface{},
+	opts ...option.Option) (*WriteResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return receiver.client.WriteData(dataList, receiver.Topic(), opts...)
+}
+
+// Finalize sends an empty write request with the date end header,
+// then calls Done, see common.SyncSession.Finalize
+func (receiver *SyncSession) Finalize(policy *common.WaitPolicy) error {
+	return receiver.SyncSession.Finalize(func(opts []option.Option) (*OperationResponse, error) {
+		response, err := receiver.client.WriteData([]map[string]interface{}{}, receiver.Topic(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		// write has no operation to wait for
+		return &OperationResponse{Status: response.GetStatus()}, nil
+	}, policy)
+}
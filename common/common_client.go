@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"time"
 
 	. "github.com/byteplus-sdk/sdk-go/common/protocol"
@@ -18,6 +19,20 @@ type Client interface {
 	// Lists operations that match the specified filter in the request.
 	ListOperations(request *ListOperationsRequest, opts ...option.Option) (*ListOperationsResponse, error)
 
+	// WaitOperation
+	//
+	// Polls the operation by GetOperation until it's done, the interval
+	// between pollings grows according to policy, DefaultWaitPolicy if nil.
+	// Operation.response is unpacked into its registered type, if it fails,
+	// the done operation is returned together with the error.
+	WaitOperation(name string, policy *WaitPolicy, opts ...option.Option) (*OperationResult, error)
+
+	// WaitOperationContext
+	//
+	// Same as WaitOperation, but the waiting stops with ctx.Err() once ctx is done.
+	WaitOperationContext(ctx context.Context, name string, policy *WaitPolicy,
+		opts ...option.Option) (*OperationResult, error)
+
 	// Done
 	//
 	// When the data of a day is imported completely,
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -26,7 +27,7 @@ func (c *clientImpl) GetOperation(request *GetOperationRequest,
 	opts ...option.Option) (*OperationResponse, error) {
 	url := c.cu.getOperationUrl
 	response := &OperationResponse{}
-	err := c.cli.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.cli.DoPbRequest(url, request, response, c.cli.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +39,7 @@ func (c *clientImpl) ListOperations(request *ListOperationsRequest,
 	opts ...option.Option) (*ListOperationsResponse, error) {
 	url := c.cu.listOperationsUrl
 	response := &ListOperationsResponse{}
-	err := c.cli.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.cli.DoPbRequest(url, request, response, c.cli.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +47,16 @@ func (c *clientImpl) ListOperations(request *ListOperationsRequest,
 	return response, nil
 }
 
+func (c *clientImpl) WaitOperation(name string, policy *WaitPolicy,
+	opts ...option.Option) (*OperationResult, error) {
+	return c.WaitOperationContext(context.Background(), name, policy, opts...)
+}
+
+func (c *clientImpl) WaitOperationContext(ctx context.Context, name string, policy *WaitPolicy,
+	opts ...option.Option) (*OperationResult, error) {
+	return waitOperation(ctx, c.GetOperation, name, policy, opts)
+}
+
 func (c *clientImpl) Done(dateList []time.Time, topic string, opts ...option.Option) (*DoneResponse, error) {
 	var dates []*Date
 	for _, date := range dateList {
@@ -56,7 +67,7 @@ func (c *clientImpl) Done(dateList []time.Time, topic string, opts ...option.Opt
 		DataDates: dates,
 	}
 	response := &DoneResponse{}
-	err := c.cli.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.cli.DoPbRequest(url, request, response, c.cli.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
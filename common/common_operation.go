@@ -0,0 +1,170 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The polling fails after such count of consecutive GetOperation failures,
+// only the transport failures and retryable status codes are counted
+const maxWaitOperationErrors = 5
+
+var DefaultWaitPolicy = &WaitPolicy{
+	Interval:    time.Second,
+	MaxInterval: 30 * time.Second,
+	Multiplier:  1.5,
+}
+
+type WaitPolicy struct {
+	// Interval before the second polling, default 1s
+	Interval time.Duration
+
+	// Max interval between two pollings, default 30s
+	MaxInterval time.Duration
+
+	// The interval is multiplied by it after every polling,
+	// 1 means polling at fixed interval, default 1.5
+	Multiplier float64
+
+	// Overall time of waiting, 0 means no limit
+	Timeout time.Duration
+}
+
+func (receiver *WaitPolicy) backoff() *core.Backoff {
+	policy := *DefaultWaitPolicy
+	if receiver != nil {
+		policy = *receiver
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultWaitPolicy.Interval
+	}
+	if policy.MaxInterval <= 0 {
+		policy.MaxInterval = DefaultWaitPolicy.MaxInterval
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = DefaultWaitPolicy.Multiplier
+	}
+	return &core.Backoff{Initial: policy.Interval, Max: policy.MaxInterval, Multiplier: policy.Multiplier}
+}
+
+func (receiver *WaitPolicy) timeout() time.Duration {
+	if receiver == nil {
+		return DefaultWaitPolicy.Timeout
+	}
+	return receiver.Timeout
+}
+
+type getOperationFunc func(request *GetOperationRequest, opts ...option.Option) (*OperationResponse, error)
+
+// waitOperation polls the operation by get until it's done, policy timeout or ctx done
+func waitOperation(ctx context.Context, get getOperationFunc, name string,
+	policy *WaitPolicy, opts []option.Option) (*OperationResult, error) {
+	var deadline time.Time
+	if policy.timeout() > 0 {
+		deadline = time.Now().Add(policy.timeout())
+	}
+	backoff := policy.backoff()
+	request := &GetOperationRequest{Name: name}
+	failures := 0
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		response, err := get(request, opts...)
+		if errors.Is(err, core.ErrClientClosed) || core.IsPermanentError(err) {
+			return nil, err
+		}
+		if code := response.GetStatus().GetCode(); err == nil && code != core.StatusCodeSuccess {
+			err = errors.New(fmt.Sprintf("[WaitOperation] fail, name:%s code:%d msg:%s",
+				name, code, response.GetStatus().GetMessage()))
+			if !core.IsRetryableStatus(code) {
+				return nil, err
+			}
+		}
+		if err != nil {
+			failures++
+			if failures >= maxWaitOperationErrors {
+				return nil, err
+			}
+			logs.Warn("[WaitOperation] poll fail, name:%s err:%s", name, err.Error())
+		} else if response.GetOperation().GetDone() {
+			operation := response.GetOperation()
+			unpacked, err := UnpackOperationResponse(operation)
+			return &OperationResult{Operation: operation, Response: unpacked}, err
+		} else {
+			failures = 0
+		}
+		delay := backoff.Delay(attempt)
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return nil, errors.New(fmt.Sprintf("[WaitOperation] timeout, name:%s", name))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type OperationResult struct {
+	Operation *Operation
+
+	// Operation.response unpacked into its registered type,
+	// such as ImportUsersResponse, nil if operation has no response
+	Response proto.Message
+}
+
+var (
+	responseTypesLock sync.RWMutex
+	responseTypes     = make(map[protoreflect.FullName]protoreflect.MessageType)
+)
+
+// RegisterOperationResponseType declares the types that Operation.response
+// could be unpacked into, products register their import responses when loaded
+func RegisterOperationResponseType(messages ...proto.Message) {
+	responseTypesLock.Lock()
+	defer responseTypesLock.Unlock()
+	for _, message := range messages {
+		messageType := message.ProtoReflect().Type()
+		responseTypes[messageType.Descriptor().FullName()] = messageType
+	}
+}
+
+// UnpackOperationResponse unmarshals Operation.response into the registered type,
+// the types known by protobuf global registry are used if it's not registered.
+// Nil is returned if operation has no response.
+func UnpackOperationResponse(operation *Operation) (proto.Message, error) {
+	response := operation.GetResponse()
+	if response == nil {
+		return nil, nil
+	}
+	name := response.MessageName()
+	responseTypesLock.RLock()
+	messageType, ok := responseTypes[name]
+	responseTypesLock.RUnlock()
+	if !ok {
+		var err error
+		messageType, err = protoregistry.GlobalTypes.FindMessageByName(name)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("[UnpackOperationResponse] unknown type:%s", response.GetTypeUrl()))
+		}
+	}
+	message := messageType.New().Interface()
+	if err := response.UnmarshalTo(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
@@ -0,0 +1,204 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+const operationDateLayout = "2006-01-02"
+
+func NewOperationFilter() *OperationFilter {
+	return &OperationFilter{}
+}
+
+// OperationFilter builds the filter expression of ListOperationsRequest.
+// Server only supports filtering by date, worksOn and done, the conditions
+// of submit time and name prefix are checked by OperationIterator locally.
+type OperationFilter struct {
+	conditions   []string
+	submitAfter  time.Time
+	submitBefore time.Time
+	namePrefix   string
+}
+
+func (receiver *OperationFilter) Done(done bool) *OperationFilter {
+	receiver.conditions = append(receiver.conditions, fmt.Sprintf("done=%t", done))
+	return receiver
+}
+
+// WorksOn filters the operations of method, such as "ImportUsers"
+func (receiver *OperationFilter) WorksOn(method string) *OperationFilter {
+	receiver.conditions = append(receiver.conditions, "worksOn="+method)
+	return receiver
+}
+
+func (receiver *OperationFilter) Date(date time.Time) *OperationFilter {
+	receiver.conditions = append(receiver.conditions, "date="+date.Format(operationDateLayout))
+	return receiver
+}
+
+// DateRange filters the operations whose date is in [from, to],
+// zero time means no limit on that side
+func (receiver *OperationFilter) DateRange(from time.Time, to time.Time) *OperationFilter {
+	if !from.IsZero() {
+		receiver.conditions = append(receiver.conditions, "date>="+from.Format(operationDateLayout))
+	}
+	if !to.IsZero() {
+		receiver.conditions = append(receiver.conditions, "date<="+to.Format(operationDateLayout))
+	}
+	return receiver
+}
+
+// SubmitTimeRange filters the operations submitted in [from, to),
+// zero time means no limit on that side
+func (receiver *OperationFilter) SubmitTimeRange(from time.Time, to time.Time) *OperationFilter {
+	receiver.submitAfter, receiver.submitBefore = from, to
+	return receiver
+}
+
+func (receiver *OperationFilter) NamePrefix(prefix string) *OperationFilter {
+	receiver.namePrefix = prefix
+	return receiver
+}
+
+// String renders the conditions supported by server
+func (receiver *OperationFilter) String() string {
+	if receiver == nil {
+		return ""
+	}
+	return strings.Join(receiver.conditions, " and ")
+}
+
+// Match checks the conditions not supported by server
+func (receiver *OperationFilter) Match(operation *Operation) bool {
+	if receiver == nil {
+		return true
+	}
+	if !strings.HasPrefix(operation.GetName(), receiver.namePrefix) {
+		return false
+	}
+	if receiver.submitAfter.IsZero() && receiver.submitBefore.IsZero() {
+		return true
+	}
+	submitTime, err := time.Parse(time.RFC3339, operation.GetMetadata().GetSubmitTime())
+	if err != nil {
+		return false
+	}
+	if !receiver.submitAfter.IsZero() && submitTime.Before(receiver.submitAfter) {
+		return false
+	}
+	return receiver.submitBefore.IsZero() || submitTime.Before(receiver.submitBefore)
+}
+
+func (receiver *OperationFilter) clone() *OperationFilter {
+	result := &OperationFilter{}
+	if receiver != nil {
+		*result = *receiver
+		result.conditions = append([]string(nil), receiver.conditions...)
+	}
+	return result
+}
+
+// NewOperationIterator lists the operations matching filter page by page,
+// nil filter means all operations, pageSize 0 means server default.
+func NewOperationIterator(client Client, filter *OperationFilter,
+	pageSize int32, opts ...option.Option) *OperationIterator {
+	return &OperationIterator{
+		client:   client,
+		filter:   filter,
+		pageSize: pageSize,
+		opts:     opts,
+	}
+}
+
+// OperationIterator fetches the next page when the current one is consumed.
+//
+// Usage:
+//
+//	iterator := common.NewOperationIterator(client, filter, 100)
+//	for iterator.Next() {
+//		operation := iterator.Operation()
+//	}
+//	if err := iterator.Err(); err != nil {
+//	}
+type OperationIterator struct {
+	client    Client
+	filter    *OperationFilter
+	pageSize  int32
+	opts      []option.Option
+	page      []*Operation
+	current   *Operation
+	pageToken string
+	started   bool
+	err       error
+}
+
+// Next advances to the next operation, false is returned
+// when all operations are iterated or an error occurred
+func (receiver *OperationIterator) Next() bool {
+	for {
+		if receiver.err != nil {
+			return false
+		}
+		for len(receiver.page) > 0 {
+			receiver.current, receiver.page = receiver.page[0], receiver.page[1:]
+			if receiver.filter.Match(receiver.current) {
+				return true
+			}
+		}
+		receiver.current = nil
+		if receiver.started && receiver.pageToken == "" {
+			return false
+		}
+		receiver.fetch()
+	}
+}
+
+func (receiver *OperationIterator) fetch() {
+	request := &ListOperationsRequest{
+		Filter:    receiver.filter.String(),
+		PageSize:  receiver.pageSize,
+		PageToken: receiver.pageToken,
+	}
+	response, err := receiver.client.ListOperations(request, receiver.opts...)
+	if err == nil && response.GetStatus().GetCode() != StatusCodeSuccess {
+		err = errors.New(fmt.Sprintf("[ListOperations] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	if err != nil {
+		receiver.err = err
+		return
+	}
+	receiver.started = true
+	receiver.page = response.GetOperations()
+	receiver.pageToken = response.GetNextPageToken()
+}
+
+// Operation returns the current operation
+func (receiver *OperationIterator) Operation() *Operation {
+	return receiver.current
+}
+
+// Err returns the error which stopped iterating
+func (receiver *OperationIterator) Err() error {
+	return receiver.err
+}
+
+// ListRunningOperations collects all operations matching filter which are not done yet
+func ListRunningOperations(client Client, filter *OperationFilter, opts ...option.Option) ([]*Operation, error) {
+	iterator := NewOperationIterator(client, filter.clone().Done(false), 0, opts...)
+	var operations []*Operation
+	for iterator.Next() {
+		// the done state is checked again in case server ignores the filter
+		if !iterator.Operation().GetDone() {
+			operations = append(operations, iterator.Operation())
+		}
+	}
+	return operations, iterator.Err()
+}
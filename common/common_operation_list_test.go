@@ -0,0 +1,66 @@
+package common
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// fakeListClient serves operations in pages of two
+type fakeListClient struct {
+	Client
+	operations []*Operation
+	filters    []string
+}
+
+func (c *fakeListClient) ListOperations(request *ListOperationsRequest,
+	opts ...option.Option) (*ListOperationsResponse, error) {
+	c.filters = append(c.filters, request.Filter)
+	start := 0
+	if request.PageToken != "" {
+		start, _ = strconv.Atoi(request.PageToken)
+	}
+	end := start + 2
+	response := &ListOperationsResponse{Status: &Status{Code: 0}}
+	if end < len(c.operations) {
+		response.NextPageToken = strconv.Itoa(end)
+	} else {
+		end = len(c.operations)
+	}
+	response.Operations = c.operations[start:end]
+	return response, nil
+}
+
+func TestOperationIterator(t *testing.T) {
+	client := &fakeListClient{}
+	for i := 0; i < 5; i++ {
+		client.operations = append(client.operations, &Operation{
+			Name:     "import-" + strconv.Itoa(i),
+			Done:     i%2 == 0,
+			Metadata: &Metadata{SubmitTime: time.Date(2021, 6, 10, i, 0, 0, 0, time.UTC).Format(time.RFC3339)},
+		})
+	}
+	client.operations = append(client.operations, &Operation{Name: "other"})
+	date := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	filter := NewOperationFilter().Date(date).WorksOn("ImportUsers").NamePrefix("import-").
+		SubmitTimeRange(date.Add(time.Hour), time.Time{})
+	if filter.String() != "date=2021-06-10 and worksOn=ImportUsers" {
+		t.Errorf("unexpected filter: %s", filter.String())
+	}
+	running, err := ListRunningOperations(client, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(running) != 2 || running[0].Name != "import-1" || running[1].Name != "import-3" {
+		t.Errorf("unexpected running operations: %v", running)
+	}
+	if len(client.filters) != 3 || client.filters[0] != filter.String()+" and done=false" {
+		t.Errorf("unexpected requests: %v", client.filters)
+	}
+	if filter.String() != "date=2021-06-10 and worksOn=ImportUsers" {
+		t.Errorf("filter should not be modified by ListRunningOperations")
+	}
+}
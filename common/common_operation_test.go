@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestWaitOperation(t *testing.T) {
+	packed, err := anypb.New(&DoneResponse{Status: &Status{Code: 1001, Message: "partial"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// not done, fails, then done
+	var polls int
+	get := func(request *GetOperationRequest, opts ...option.Option) (*OperationResponse, error) {
+		polls++
+		switch polls {
+		case 1:
+			return &OperationResponse{Status: &Status{Code: 0}, Operation: &Operation{Name: request.Name}}, nil
+		case 2:
+			return nil, errors.New("network error")
+		}
+		operation := &Operation{Name: request.Name, Done: true, Response: packed}
+		return &OperationResponse{Status: &Status{Code: 0}, Operation: operation}, nil
+	}
+	policy := &WaitPolicy{Interval: time.Millisecond}
+	result, err := waitOperation(context.Background(), get, "op-1", policy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, ok := result.Response.(*DoneResponse)
+	if polls != 3 || !ok || response.GetStatus().GetMessage() != "partial" {
+		t.Errorf("unexpected result after %d polls: %v", polls, result.Response)
+	}
+
+	// closed client, permanent errors and non-retryable codes are returned at once
+	failures := []func() (*OperationResponse, error){
+		func() (*OperationResponse, error) { return nil, core.ErrClientClosed },
+		func() (*OperationResponse, error) { return nil, core.NewPermanentError("unknown operation") },
+		func() (*OperationResponse, error) {
+			return &OperationResponse{Status: &Status{Code: 404, Message: "not found"}}, nil
+		},
+	}
+	for i, failure := range failures {
+		polls = 0
+		failing := func(request *GetOperationRequest, opts ...option.Option) (*OperationResponse, error) {
+			polls++
+			return failure()
+		}
+		if _, err = waitOperation(context.Background(), failing, "op-1", policy, nil); err == nil || polls != 1 {
+			t.Errorf("case %d: expect failing after 1 poll, got %d polls, err:%v", i, polls, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running := func(request *GetOperationRequest, opts ...option.Option) (*OperationResponse, error) {
+		cancel()
+		return &OperationResponse{Status: &Status{Code: 0}, Operation: &Operation{Name: request.Name}}, nil
+	}
+	start := time.Now()
+	_, err = waitOperation(ctx, running, "op-2", &WaitPolicy{Interval: time.Hour}, nil)
+	if err != context.Canceled || time.Since(start) > time.Second {
+		t.Errorf("expect canceled at once, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestUnpackOperationResponse(t *testing.T) {
+	response, err := UnpackOperationResponse(&Operation{Name: "op-1", Done: true})
+	if response != nil || err != nil {
+		t.Errorf("expect nothing unpacked without response, got %v %v", response, err)
+	}
+	RegisterOperationResponseType(&DoneResponse{})
+	packed, err := anypb.New(&DoneResponse{Status: &Status{Code: 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = UnpackOperationResponse(&Operation{Response: packed})
+	if err != nil || !proto.Equal(response, &DoneResponse{Status: &Status{Code: 0}}) {
+		t.Errorf("unexpected unpacked response %v, err:%v", response, err)
+	}
+	packed.TypeUrl = "type.googleapis.com/unknown.Response"
+	if _, err = UnpackOperationResponse(&Operation{Response: packed}); err == nil {
+		t.Errorf("expect error of unknown response type")
+	}
+}
@@ -0,0 +1,316 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+)
+
+// TrackedOperation is the operation returned by Import* calls,
+// with the information to identify the imported data
+type TrackedOperation struct {
+	Name  string `json:"name"`
+	Topic string `json:"topic,omitempty"`
+	Date  string `json:"date,omitempty"`
+	// Index of the chunk in the imported items, and the [ChunkStart, ChunkEnd) range
+	ChunkIndex int               `json:"chunk_index"`
+	ChunkStart int               `json:"chunk_start"`
+	ChunkEnd   int               `json:"chunk_end"`
+	Extra      map[string]string `json:"extra,omitempty"`
+	SubmitTime time.Time         `json:"submit_time"`
+	// Completed is true once the operation is done
+	Completed bool `json:"completed"`
+	// CallbackFired is true once the completion callback returned,
+	// then the operation is deleted from store
+	CallbackFired bool `json:"callback_fired"`
+}
+
+func (receiver *TrackedOperation) GetName() string {
+	if receiver == nil {
+		return ""
+	}
+	return receiver.Name
+}
+
+// OperationStore persists tracked operations, it should be safe for concurrent use
+type OperationStore interface {
+	// Save inserts the operation or replaces the one with the same name
+	Save(operation *TrackedOperation) error
+
+	Delete(name string) error
+
+	List() ([]*TrackedOperation, error)
+}
+
+// OperationCallback is called when the operation is done, err is not nil
+// if Operation.response can't be unpacked, in which case result.Response is nil
+type OperationCallback func(operation *TrackedOperation, result *OperationResult, err error)
+
+// NewOperationTracker loads the operations in store, resumes polling the ones
+// whose callback is not fired, and deletes the ones whose callback was fired.
+func NewOperationTracker(client Client, store OperationStore, policy *WaitPolicy,
+	callback OperationCallback) (*OperationTracker, error) {
+	if store == nil {
+		return nil, errors.New("operation store is null")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker := &OperationTracker{
+		client:   client,
+		store:    store,
+		policy:   policy,
+		callback: callback,
+		polling:  make(map[string]*TrackedOperation),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	operations, err := store.List()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, operation := range operations {
+		if operation.CallbackFired {
+			// the process exited before the operation deleted
+			tracker.delete(operation.Name)
+			continue
+		}
+		// the completed operation is polled again for its result,
+		// as the process exited before its callback returned
+		logs.Info("resume polling operation, name:%s completed:%v", operation.Name, operation.Completed)
+		tracker.startPolling(operation)
+	}
+	return tracker, nil
+}
+
+// OperationTracker persists the submitted operations before polling them,
+// so that the polling is resumed after process restarted.
+// The fired state is persisted after callback returned, so the callback is
+// fired at least once per operation, even across restarts. If the process
+// exits while the callback is running, the callback is fired again by the
+// next tracker, so it should be idempotent.
+type OperationTracker struct {
+	client   Client
+	store    OperationStore
+	policy   *WaitPolicy
+	callback OperationCallback
+	lock     sync.Mutex
+	polling  map[string]*TrackedOperation
+	closed   bool
+	wg       sync.WaitGroup
+	// canceled by Close to stop the pollings
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Track persists a copy of operation and polls it until done
+func (receiver *OperationTracker) Track(operation *TrackedOperation) error {
+	if operation.GetName() == "" {
+		return errors.New("operation name is empty")
+	}
+	tracked := *operation
+	if tracked.SubmitTime.IsZero() {
+		tracked.SubmitTime = time.Now()
+	}
+	tracked.Completed = false
+	tracked.CallbackFired = false
+	if err := receiver.store.Save(&tracked); err != nil {
+		return err
+	}
+	receiver.startPolling(&tracked)
+	return nil
+}
+
+// Pending returns the copies of the operations being polled
+func (receiver *OperationTracker) Pending() []*TrackedOperation {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	operations := make([]*TrackedOperation, 0, len(receiver.polling))
+	for _, operation := range receiver.polling {
+		copied := *operation
+		operations = append(operations, &copied)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].SubmitTime.Before(operations[j].SubmitTime)
+	})
+	return operations
+}
+
+// Wait blocks until all tracked operations completed or tracker closed
+func (receiver *OperationTracker) Wait() {
+	receiver.wg.Wait()
+}
+
+// Close stops the pollings and callbacks, the operations not completed
+// yet are kept in store and resumed by the next tracker.
+// Call Wait after Close to wait for the pollings returned.
+func (receiver *OperationTracker) Close() {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	receiver.closed = true
+	receiver.cancel()
+}
+
+func (receiver *OperationTracker) startPolling(operation *TrackedOperation) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.closed {
+		return
+	}
+	if _, exist := receiver.polling[operation.Name]; exist {
+		return
+	}
+	receiver.polling[operation.Name] = operation
+	receiver.wg.Add(1)
+	AsyncExecute(func() {
+		defer receiver.wg.Done()
+		receiver.poll(operation)
+	})
+}
+
+func (receiver *OperationTracker) poll(operation *TrackedOperation) {
+	backoff := receiver.policy.backoff()
+	for attempt := 0; !receiver.isClosed(); attempt++ {
+		result, err := receiver.client.WaitOperationContext(receiver.ctx, operation.Name, receiver.policy)
+		if err != nil && result == nil {
+			if receiver.ctx.Err() != nil {
+				return
+			}
+			// GetOperation keeps failing or timeout, try again until closed
+			logs.Warn("wait operation fail, name:%s err:%s", operation.Name, err.Error())
+			timer := time.NewTimer(backoff.Delay(attempt))
+			select {
+			case <-receiver.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		receiver.complete(operation, result, err)
+		return
+	}
+}
+
+func (receiver *OperationTracker) complete(operation *TrackedOperation, result *OperationResult, err error) {
+	receiver.lock.Lock()
+	if receiver.closed {
+		receiver.lock.Unlock()
+		return
+	}
+	completed := *operation
+	completed.Completed = true
+	saveErr := receiver.store.Save(&completed)
+	delete(receiver.polling, operation.Name)
+	receiver.lock.Unlock()
+	if saveErr != nil {
+		// fire the callback anyway, it fires again after restart
+		logs.Error("save completed operation fail, name:%s err:%s", operation.Name, saveErr.Error())
+	}
+	if receiver.callback != nil {
+		receiver.callback(&completed, result, err)
+	}
+	completed.CallbackFired = true
+	if saveErr := receiver.store.Save(&completed); saveErr != nil {
+		// the callback may fire again after restart
+		logs.Error("save fired operation fail, name:%s err:%s", operation.Name, saveErr.Error())
+		return
+	}
+	receiver.delete(operation.Name)
+}
+
+func (receiver *OperationTracker) delete(name string) {
+	if err := receiver.store.Delete(name); err != nil {
+		// it's deleted again when the next tracker loads it
+		logs.Warn("delete completed operation fail, name:%s err:%s", name, err.Error())
+	}
+}
+
+func (receiver *OperationTracker) isClosed() bool {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.closed
+}
+
+func NewFileOperationStore(path string) (*FileOperationStore, error) {
+	store := &FileOperationStore{path: path, operations: make(map[string]*TrackedOperation)}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var operations []*TrackedOperation
+	if err := json.Unmarshal(content, &operations); err != nil {
+		return nil, err
+	}
+	for _, operation := range operations {
+		store.operations[operation.Name] = operation
+	}
+	return store, nil
+}
+
+// FileOperationStore keeps all operations in one json file,
+// which is replaced atomically on every change
+type FileOperationStore struct {
+	path       string
+	lock       sync.Mutex
+	operations map[string]*TrackedOperation
+}
+
+func (receiver *FileOperationStore) Save(operation *TrackedOperation) error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	saved := *operation
+	receiver.operations[operation.Name] = &saved
+	return receiver.flush()
+}
+
+func (receiver *FileOperationStore) Delete(name string) error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	delete(receiver.operations, name)
+	return receiver.flush()
+}
+
+func (receiver *FileOperationStore) List() ([]*TrackedOperation, error) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.sorted(), nil
+}
+
+func (receiver *FileOperationStore) sorted() []*TrackedOperation {
+	operations := make([]*TrackedOperation, 0, len(receiver.operations))
+	for _, operation := range receiver.operations {
+		copied := *operation
+		operations = append(operations, &copied)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].SubmitTime.Before(operations[j].SubmitTime)
+	})
+	return operations
+}
+
+func (receiver *FileOperationStore) flush() error {
+	content, err := json.MarshalIndent(receiver.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(receiver.path), 0755); err != nil {
+		return err
+	}
+	tmpPath := receiver.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, receiver.path)
+}
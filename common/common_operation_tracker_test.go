@@ -0,0 +1,173 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// fakeWaitClient finishes the operation when it's released
+type fakeWaitClient struct {
+	Client
+	release chan struct{}
+}
+
+func (c *fakeWaitClient) WaitOperationContext(ctx context.Context, name string, policy *WaitPolicy,
+	opts ...option.Option) (*OperationResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.release:
+	}
+	return &OperationResult{Operation: &Operation{Name: name, Done: true}}, nil
+}
+
+// failingWaitClient fails every wait at once
+type failingWaitClient struct {
+	Client
+	lock  sync.Mutex
+	waits int
+}
+
+func (c *failingWaitClient) WaitOperationContext(ctx context.Context, name string, policy *WaitPolicy,
+	opts ...option.Option) (*OperationResult, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.waits++
+	return nil, errors.New("network error")
+}
+
+func TestOperationTrackerResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operations.json")
+	store, err := NewFileOperationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked := &fakeWaitClient{release: make(chan struct{})}
+	tracker, err := NewOperationTracker(blocked, store, nil, func(*TrackedOperation, *OperationResult, error) {
+		t.Errorf("callback should not fire after tracker closed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := &TrackedOperation{Name: "op-1", Topic: "user", Date: "2021-06-10"}
+	if err := tracker.Track(operation); err != nil {
+		t.Fatal(err)
+	}
+	if !operation.SubmitTime.IsZero() {
+		t.Errorf("Track() should not modify the operation of caller")
+	}
+	tracker.Pending()[0].Topic = "product"
+	if pending := tracker.Pending(); len(pending) != 1 || pending[0].Topic != "user" {
+		t.Errorf("Pending() should return copies, got %v", pending)
+	}
+	// simulates the redeployment during import, the polling stops without released
+	tracker.Close()
+	tracker.Wait()
+	close(blocked.release)
+
+	var (
+		lock  sync.Mutex
+		fired []string
+	)
+	callback := func(operation *TrackedOperation, result *OperationResult, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		fired = append(fired, operation.Name)
+	}
+	for i := 0; i < 2; i++ {
+		store, err = NewFileOperationStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracker, err = NewOperationTracker(blocked, store, nil, callback)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracker.Wait()
+	}
+	if len(fired) != 1 || fired[0] != "op-1" {
+		t.Errorf("expect callback fired once after resumed, got %v", fired)
+	}
+	if operations, _ := store.List(); len(operations) != 0 {
+		t.Errorf("expect completed operations deleted, got %v", operations)
+	}
+}
+
+func TestOperationTrackerCloseWhileFailing(t *testing.T) {
+	store, err := NewFileOperationStore(filepath.Join(t.TempDir(), "operations.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &failingWaitClient{}
+	policy := &WaitPolicy{Interval: 20 * time.Millisecond, Multiplier: 1}
+	tracker, err := NewOperationTracker(client, store, policy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Track(&TrackedOperation{Name: "op-1"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	tracker.Close()
+	tracker.Wait()
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.waits < 1 || client.waits > 4 {
+		t.Errorf("expect the failed waits retried with backoff, got %d waits", client.waits)
+	}
+	if operations, _ := store.List(); len(operations) != 1 || operations[0].Completed {
+		t.Errorf("expect the operation kept for the next tracker, got %v", operations)
+	}
+}
+
+func TestOperationTrackerRestartDuringCallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operations.json")
+	store, err := NewFileOperationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the process exited while the callback of op-1 was running,
+	// and before op-2 was deleted after its callback returned
+	if err := store.Save(&TrackedOperation{Name: "op-1", Completed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&TrackedOperation{Name: "op-2", Completed: true, CallbackFired: true}); err != nil {
+		t.Fatal(err)
+	}
+	released := &fakeWaitClient{release: make(chan struct{})}
+	close(released.release)
+	var (
+		lock  sync.Mutex
+		fired []string
+	)
+	callback := func(operation *TrackedOperation, result *OperationResult, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if result.Operation.GetName() != operation.Name || !operation.Completed {
+			t.Errorf("unexpected callback of %v with result %v", operation, result)
+		}
+		fired = append(fired, operation.Name)
+	}
+	store, err = NewFileOperationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := NewOperationTracker(released, store, nil, callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Wait()
+	if len(fired) != 1 || fired[0] != "op-1" {
+		t.Errorf("expect only the callback not returned fired again, got %v", fired)
+	}
+	if operations, _ := store.List(); len(operations) != 0 {
+		t.Errorf("expect fired operations deleted, got %v", operations)
+	}
+}
@@ -0,0 +1,352 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+var ErrSyncSessionFinalized = errors.New("data of the date is finalized")
+
+var ErrSyncSessionFinalizing = errors.New("data of the date is being finalized")
+
+// SyncSessionState is persisted after every change,
+// so that the finalization is not repeated after restart
+type SyncSessionState struct {
+	Topic string `json:"topic"`
+	Date  string `json:"date"`
+	// The import operations not known to be done
+	Operations []string `json:"operations,omitempty"`
+	EndSent    bool     `json:"end_sent"`
+	// The operation of the is_end request, Done is sent after it's done
+	EndOperation string `json:"end_operation,omitempty"`
+	DoneSent     bool   `json:"done_sent"`
+}
+
+// SyncStateStore persists the state of sync sessions
+type SyncStateStore interface {
+	// Load returns nil if the state of topic and date not exists
+	Load(topic string, date string) (*SyncSessionState, error)
+
+	Save(state *SyncSessionState) error
+}
+
+// SyncEndFunc sends the request which marks the data of date transmitted
+// completely, such as an empty import request with DateConfig.is_end.
+// The opts carry the data date and a request id stable across restarts.
+type SyncEndFunc func(opts []option.Option) (*OperationResponse, error)
+
+// NewSyncSession loads the state of topic and date from store, nil
+// store means the state is only kept in memory
+func NewSyncSession(client Client, topic string, date time.Time, store SyncStateStore) (*SyncSession, error) {
+	session := &SyncSession{
+		client: client,
+		topic:  topic,
+		date:   date,
+		store:  store,
+	}
+	dateStr := date.Format("2006-01-02")
+	if store != nil {
+		state, err := store.Load(topic, dateStr)
+		if err != nil {
+			return nil, err
+		}
+		session.state = state
+	}
+	if session.state == nil {
+		session.state = &SyncSessionState{Topic: topic, Date: dateStr}
+	}
+	return session, nil
+}
+
+// SyncSession coordinates the data transmission of one topic in one date.
+// It stamps every write and import with the date, tracks the import operations,
+// and finalizes the date by sending is_end and Done once all imports are done.
+// The data of a date is frozen after finalized, later writes are rejected.
+type SyncSession struct {
+	client Client
+	topic  string
+	date   time.Time
+	store  SyncStateStore
+	lock   sync.Mutex
+	state  *SyncSessionState
+	// set while Finalize checks the imports and sends is_end,
+	// so that no new import starts after the check
+	finalizing bool
+	// count of the writes and imports got the dated options
+	// and not released yet, Finalize is refused while it's above 0
+	inFlight int
+}
+
+func (receiver *SyncSession) Topic() string {
+	return receiver.topic
+}
+
+func (receiver *SyncSession) Date() time.Time {
+	return receiver.date
+}
+
+// DateString returns date formatted like DateConfig.date, such as "2021-06-10"
+func (receiver *SyncSession) DateString() string {
+	return receiver.state.Date
+}
+
+// Options appends the data date to opts, ErrSyncSessionFinalized
+// is returned if the date was finalized, and ErrSyncSessionFinalizing
+// if Finalize is running.
+// The call is counted in flight until release is called, which should be
+// after the call returns and its operation is tracked, Finalize is refused
+// until all calls are released.
+func (receiver *SyncSession) Options(opts []option.Option) ([]option.Option, func(), error) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.state.EndSent {
+		return nil, nil, ErrSyncSessionFinalized
+	}
+	if receiver.finalizing {
+		return nil, nil, ErrSyncSessionFinalizing
+	}
+	receiver.inFlight++
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			receiver.lock.Lock()
+			receiver.inFlight--
+			receiver.lock.Unlock()
+		})
+	}
+	return receiver.withDate(opts), release, nil
+}
+
+func (receiver *SyncSession) withDate(opts []option.Option) []option.Option {
+	result := make([]option.Option, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, option.WithDataDate(receiver.date))
+}
+
+// Track records the operation returned by import, the date
+// can't be finalized until the operation is done. Tracking the same
+// operation again is ignored.
+func (receiver *SyncSession) Track(response *OperationResponse) error {
+	name := response.GetOperation().GetName()
+	if name == "" {
+		return nil
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for _, tracked := range receiver.state.Operations {
+		if tracked == name {
+			return nil
+		}
+	}
+	receiver.state.Operations = append(receiver.state.Operations, name)
+	return receiver.save()
+}
+
+// Running polls the tracked operations, and returns the ones not done yet
+func (receiver *SyncSession) Running() ([]string, error) {
+	receiver.lock.Lock()
+	operations := append([]string(nil), receiver.state.Operations...)
+	receiver.lock.Unlock()
+	done := make(map[string]bool)
+	var running []string
+	for _, name := range operations {
+		response, err := receiver.client.GetOperation(&GetOperationRequest{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		if response.GetStatus().GetCode() != StatusCodeSuccess {
+			return nil, errors.New(fmt.Sprintf("[GetOperation] fail, name:%s code:%d msg:%s",
+				name, response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+		}
+		if response.GetOperation().GetDone() {
+			done[name] = true
+			continue
+		}
+		running = append(running, name)
+	}
+	if len(done) == 0 {
+		return running, nil
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	remained := receiver.state.Operations[:0]
+	for _, name := range receiver.state.Operations {
+		if !done[name] {
+			remained = append(remained, name)
+		}
+	}
+	receiver.state.Operations = remained
+	return running, receiver.save()
+}
+
+// Finalize sends the is_end request through end, waits for its operation
+// done by policy, then calls Done. It refuses to finalize while the tracked
+// imports are still running, or the calls got Options are not released.
+// Every step is persisted, so calling it again, even after restart,
+// continues from the unfinished step.
+// Options is rejected while it checks the imports and sends is_end.
+func (receiver *SyncSession) Finalize(end SyncEndFunc, policy *WaitPolicy) error {
+	receiver.lock.Lock()
+	state := *receiver.state
+	if !state.EndSent {
+		if receiver.finalizing {
+			receiver.lock.Unlock()
+			return ErrSyncSessionFinalizing
+		}
+		if receiver.inFlight > 0 {
+			inFlight := receiver.inFlight
+			receiver.lock.Unlock()
+			return errors.New(fmt.Sprintf("[SyncSession] can't finalize while %d calls in flight", inFlight))
+		}
+		receiver.finalizing = true
+	}
+	receiver.lock.Unlock()
+	if state.DoneSent {
+		return nil
+	}
+	if !state.EndSent {
+		err := receiver.finalizeEnd(end)
+		receiver.lock.Lock()
+		receiver.finalizing = false
+		receiver.lock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	receiver.lock.Lock()
+	endOperation := receiver.state.EndOperation
+	receiver.lock.Unlock()
+	if endOperation != "" {
+		if _, err := receiver.client.WaitOperation(endOperation, policy); err != nil {
+			return err
+		}
+	}
+	return receiver.sendDone()
+}
+
+func (receiver *SyncSession) finalizeEnd(end SyncEndFunc) error {
+	running, err := receiver.Running()
+	if err != nil {
+		return err
+	}
+	if len(running) > 0 {
+		return errors.New(fmt.Sprintf("[SyncSession] can't finalize while imports running, operations:%s",
+			strings.Join(running, ",")))
+	}
+	return receiver.sendEnd(end)
+}
+
+func (receiver *SyncSession) sendEnd(end SyncEndFunc) error {
+	opts := receiver.withDate([]option.Option{
+		option.WithDateEnd(true),
+		// the server rejects the resent request after restart as idempotent
+		option.WithRequestId(receiver.requestId("end")),
+	})
+	response, err := end(opts)
+	if err != nil {
+		return err
+	}
+	code := response.GetStatus().GetCode()
+	if code != StatusCodeSuccess && code != StatusCodeIdempotent {
+		return errors.New(fmt.Sprintf("[SyncSession] send end fail, code:%d msg:%s",
+			code, response.GetStatus().GetMessage()))
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	receiver.state.EndSent = true
+	receiver.state.EndOperation = response.GetOperation().GetName()
+	return receiver.save()
+}
+
+func (receiver *SyncSession) sendDone() error {
+	opts := []option.Option{option.WithRequestId(receiver.requestId("done"))}
+	response, err := receiver.client.Done([]time.Time{receiver.date}, receiver.topic, opts...)
+	if err != nil {
+		return err
+	}
+	code := response.GetStatus().GetCode()
+	if code != StatusCodeSuccess && code != StatusCodeIdempotent {
+		return errors.New(fmt.Sprintf("[SyncSession] done fail, code:%d msg:%s",
+			code, response.GetStatus().GetMessage()))
+	}
+	logs.Info("data finalized, topic:%s date:%s", receiver.topic, receiver.state.Date)
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	receiver.state.DoneSent = true
+	return receiver.save()
+}
+
+func (receiver *SyncSession) requestId(step string) string {
+	return fmt.Sprintf("sync-%s-%s-%s", step, receiver.topic, receiver.state.Date)
+}
+
+// State returns a copy of the current state
+func (receiver *SyncSession) State() SyncSessionState {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	state := *receiver.state
+	state.Operations = append([]string(nil), state.Operations...)
+	return state
+}
+
+func (receiver *SyncSession) save() error {
+	if receiver.store == nil {
+		return nil
+	}
+	return receiver.store.Save(receiver.state)
+}
+
+func NewFileSyncStateStore(dir string) *FileSyncStateStore {
+	return &FileSyncStateStore{dir: dir}
+}
+
+// FileSyncStateStore keeps the state of every topic and date in a json file of dir
+type FileSyncStateStore struct {
+	dir string
+}
+
+func (receiver *FileSyncStateStore) Load(topic string, date string) (*SyncSessionState, error) {
+	content, err := ioutil.ReadFile(receiver.path(topic, date))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &SyncSessionState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (receiver *FileSyncStateStore) Save(state *SyncSessionState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(receiver.dir, 0755); err != nil {
+		return err
+	}
+	path := receiver.path(state.Topic, state.Date)
+	if err := ioutil.WriteFile(path+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (receiver *FileSyncStateStore) path(topic string, date string) string {
+	return filepath.Join(receiver.dir, fmt.Sprintf("%s-%s.json", topic, date))
+}
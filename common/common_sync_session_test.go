@@ -0,0 +1,119 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+type fakeSyncClient struct {
+	Client
+	done      map[string]bool
+	doneCalls int
+}
+
+func (c *fakeSyncClient) GetOperation(request *GetOperationRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	operation := &Operation{Name: request.Name, Done: c.done[request.Name]}
+	return &OperationResponse{Status: &Status{Code: 0}, Operation: operation}, nil
+}
+
+func (c *fakeSyncClient) WaitOperation(name string, policy *WaitPolicy,
+	opts ...option.Option) (*OperationResult, error) {
+	return &OperationResult{Operation: &Operation{Name: name, Done: true}}, nil
+}
+
+func (c *fakeSyncClient) Done(dateList []time.Time, topic string,
+	opts ...option.Option) (*DoneResponse, error) {
+	c.doneCalls++
+	return &DoneResponse{Status: &Status{Code: 0}}, nil
+}
+
+func TestSyncSessionFinalize(t *testing.T) {
+	client := &fakeSyncClient{done: make(map[string]bool)}
+	store := NewFileSyncStateStore(t.TempDir())
+	date := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	session, err := NewSyncSession(client, "user", date, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := session.Track(&OperationResponse{Operation: &Operation{Name: "op-1"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if operations := session.State().Operations; len(operations) != 1 {
+		t.Errorf("expect the operation tracked once, got %v", operations)
+	}
+	endCalls := 0
+	end := func(opts []option.Option) (*OperationResponse, error) {
+		endCalls++
+		if _, _, err := session.Options(nil); err != ErrSyncSessionFinalizing {
+			t.Errorf("expect ErrSyncSessionFinalizing while finalizing, got %v", err)
+		}
+		options := option.Conv2Options(opts...)
+		if !options.DataIsEnd || !options.DataDate.Equal(date) {
+			t.Errorf("unexpected options of end request: %+v", options)
+		}
+		return &OperationResponse{Status: &Status{Code: 0}, Operation: &Operation{Name: "op-end"}}, nil
+	}
+	if err := session.Finalize(end, nil); err == nil {
+		t.Errorf("Finalize() should be refused while imports running")
+	}
+	_, release, err := session.Options(nil)
+	if err != nil {
+		t.Fatalf("Options() should be allowed after Finalize() refused, got %v", err)
+	}
+	release()
+	client.done["op-1"] = true
+	if err := session.Finalize(end, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := session.Options(nil); err != ErrSyncSessionFinalized {
+		t.Errorf("expect ErrSyncSessionFinalized after finalized, got %v", err)
+	}
+	// the state is loaded after restart, nothing is sent again
+	session, err = NewSyncSession(client, "user", date, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Finalize(end, nil); err != nil {
+		t.Fatal(err)
+	}
+	if endCalls != 1 || client.doneCalls != 1 {
+		t.Errorf("expect end and done sent once, got end:%d done:%d", endCalls, client.doneCalls)
+	}
+}
+
+func TestSyncSessionFinalizeWithWriteInFlight(t *testing.T) {
+	client := &fakeSyncClient{done: make(map[string]bool)}
+	session, err := NewSyncSession(client, "user", time.Now(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := func(opts []option.Option) (*OperationResponse, error) {
+		return &OperationResponse{Status: &Status{Code: 0}}, nil
+	}
+	// the write got the dated options and is still waiting for response
+	_, release, err := session.Options(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Finalize(end, nil); err == nil {
+		t.Fatal("Finalize() should be refused while a write is in flight")
+	}
+	if session.State().EndSent {
+		t.Fatal("is_end should not be sent while a write is in flight")
+	}
+	release()
+	// releasing again doesn't count down twice
+	release()
+	if err := session.Finalize(end, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !session.State().DoneSent {
+		t.Error("expect finalized after the write released")
+	}
+}
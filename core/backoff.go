@@ -0,0 +1,54 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+var DefaultBackoff = &Backoff{
+	Initial:    500 * time.Millisecond,
+	Max:        30 * time.Second,
+	Multiplier: 2,
+}
+
+// Backoff calculates the delay before the next retry,
+// which grows exponentially from Initial to Max
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// Delay returns the delay before the retry of attempt, attempt starts from 0
+func (receiver *Backoff) Delay(attempt int) time.Duration {
+	if receiver == nil {
+		return DefaultBackoff.Delay(attempt)
+	}
+	multiplier := receiver.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(receiver.Initial) * math.Pow(multiplier, float64(attempt))
+	if receiver.Max > 0 && delay > float64(receiver.Max) {
+		return receiver.Max
+	}
+	return time.Duration(delay)
+}
+
+// Sleep waits the delay of attempt, returns false if abort closed before
+func (receiver *Backoff) Sleep(attempt int, abort <-chan struct{}) bool {
+	timer := time.NewTimer(receiver.Delay(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+// IsRetryableStatus reports whether the request could succeed
+// by retrying later according to the response status code
+func IsRetryableStatus(code int32) bool {
+	return code == StatusCodeTooManyRequest || code >= 500
+}
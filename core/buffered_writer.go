@@ -0,0 +1,197 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+)
+
+const (
+	defaultBufferMaxLatency      = time.Second
+	defaultBufferCapacityBatches = 10
+)
+
+var (
+	ErrBufferFull   = errors.New("buffer of writer is full")
+	ErrWriterClosed = errors.New("writer is closed")
+)
+
+type BufferedWriterConfig struct {
+	// Max count of records flushed in one request,
+	// it will be limited to the max item count of one write request
+	MaxBatchCount int
+
+	// Max bytes of records flushed in one request, 0 means no limit
+	MaxBatchBytes int
+
+	// Max time that a record stays in buffer before flushed, default 1s
+	MaxLatency time.Duration
+
+	// Max count of records waiting in buffer, default 10 times of MaxBatchCount.
+	// Write blocks when buffer is full
+	BufferCapacity int
+
+	// Max time that Write blocks when buffer is full, ErrBufferFull
+	// will be returned after timeout, 0 means blocking until buffer available
+	WriteTimeout time.Duration
+}
+
+// FlushFunc sends records in one request, and returns the error
+// of every record in the same order, nil error means success
+type FlushFunc func(records []interface{}) []error
+
+// RecordCallback reports the result of every written record
+type RecordCallback func(record interface{}, err error)
+
+func NewBufferedWriter(config *BufferedWriterConfig, maxBatchLimit int, flush FlushFunc,
+	sizeOf func(record interface{}) int, callback RecordCallback) *BufferedWriter {
+	writer := &BufferedWriter{
+		config:   fillBufferedWriterConfig(config, maxBatchLimit),
+		flush:    flush,
+		sizeOf:   sizeOf,
+		callback: callback,
+		done:     make(chan struct{}),
+	}
+	writer.records = make(chan interface{}, writer.config.BufferCapacity)
+	AsyncExecute(writer.flushLoop)
+	return writer
+}
+
+func fillBufferedWriterConfig(config *BufferedWriterConfig, maxBatchLimit int) BufferedWriterConfig {
+	var result BufferedWriterConfig
+	if config != nil {
+		result = *config
+	}
+	if result.MaxBatchCount <= 0 || result.MaxBatchCount > maxBatchLimit {
+		result.MaxBatchCount = maxBatchLimit
+	}
+	if result.MaxLatency <= 0 {
+		result.MaxLatency = defaultBufferMaxLatency
+	}
+	if result.BufferCapacity <= 0 {
+		result.BufferCapacity = defaultBufferCapacityBatches * result.MaxBatchCount
+	}
+	return result
+}
+
+// BufferedWriter collects single records, and flushes them in batch when
+// the count or bytes of batch reaches the limit, or the oldest record
+// has waited for MaxLatency.
+type BufferedWriter struct {
+	config   BufferedWriterConfig
+	flush    FlushFunc
+	sizeOf   func(record interface{}) int
+	callback RecordCallback
+	records  chan interface{}
+	// closed is guarded by lock, so that records is never sent after closed
+	lock   sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// Write puts record into buffer, it blocks when buffer is full,
+// until buffer available or WriteTimeout reached.
+// The result of record is reported by callback after flushed.
+func (receiver *BufferedWriter) Write(record interface{}) error {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	if receiver.closed {
+		return ErrWriterClosed
+	}
+	if receiver.config.WriteTimeout <= 0 {
+		receiver.records <- record
+		return nil
+	}
+	timer := time.NewTimer(receiver.config.WriteTimeout)
+	defer timer.Stop()
+	select {
+	case receiver.records <- record:
+		return nil
+	case <-timer.C:
+		return ErrBufferFull
+	}
+}
+
+// Buffered returns the count of records waiting in buffer
+func (receiver *BufferedWriter) Buffered() int {
+	return len(receiver.records)
+}
+
+// Close stops accepting records, and returns after all buffered records flushed.
+// It's safe to call Close more than once.
+func (receiver *BufferedWriter) Close() {
+	receiver.lock.Lock()
+	if !receiver.closed {
+		receiver.closed = true
+		close(receiver.records)
+	}
+	receiver.lock.Unlock()
+	<-receiver.done
+}
+
+func (receiver *BufferedWriter) flushLoop() {
+	defer close(receiver.done)
+	var (
+		batch      []interface{}
+		batchBytes int
+		timer      = time.NewTimer(receiver.config.MaxLatency)
+	)
+	timer.Stop()
+	doFlush := func() {
+		// drain the expired tick, avoid flushing the next batch too early
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if len(batch) > 0 {
+			receiver.flushBatch(batch)
+		}
+		batch, batchBytes = nil, 0
+	}
+	for {
+		select {
+		case record, ok := <-receiver.records:
+			if !ok {
+				doFlush()
+				return
+			}
+			recordBytes := 0
+			if receiver.config.MaxBatchBytes > 0 && receiver.sizeOf != nil {
+				recordBytes = receiver.sizeOf(record)
+				if len(batch) > 0 && batchBytes+recordBytes > receiver.config.MaxBatchBytes {
+					doFlush()
+				}
+			}
+			if len(batch) == 0 {
+				timer.Reset(receiver.config.MaxLatency)
+			}
+			batch = append(batch, record)
+			batchBytes += recordBytes
+			if len(batch) >= receiver.config.MaxBatchCount {
+				doFlush()
+			}
+		case <-timer.C:
+			doFlush()
+		}
+	}
+}
+
+func (receiver *BufferedWriter) flushBatch(batch []interface{}) {
+	start := time.Now()
+	errs := receiver.flush(batch)
+	logs.Debug("flush buffered records, count:%d cost:%s", len(batch), time.Now().Sub(start))
+	if receiver.callback == nil {
+		return
+	}
+	for i, record := range batch {
+		var err error
+		if i < len(errs) {
+			err = errs[i]
+		}
+		receiver.callback(record, err)
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	var (
+		lock       sync.Mutex
+		batchSizes []int
+		results    = make(map[int]error)
+	)
+	flush := func(records []interface{}) []error {
+		lock.Lock()
+		batchSizes = append(batchSizes, len(records))
+		lock.Unlock()
+		errs := make([]error, len(records))
+		for i, record := range records {
+			if record.(int)%10 == 0 {
+				errs[i] = errors.New("rejected")
+			}
+		}
+		return errs
+	}
+	callback := func(record interface{}, err error) {
+		lock.Lock()
+		results[record.(int)] = err
+		lock.Unlock()
+	}
+	config := &BufferedWriterConfig{MaxBatchCount: 200, MaxLatency: time.Hour}
+	writer := NewBufferedWriter(config, 30, flush, nil, callback)
+	for i := 1; i <= 70; i++ {
+		if err := writer.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.Close()
+	if err := writer.Write(71); err != ErrWriterClosed {
+		t.Errorf("Write() after closed err = %v, want %v", err, ErrWriterClosed)
+	}
+	// batch count is limited to 30, the rest is flushed by Close
+	if len(batchSizes) != 3 || batchSizes[0] != 30 || batchSizes[1] != 30 || batchSizes[2] != 10 {
+		t.Errorf("flushed batch sizes = %v", batchSizes)
+	}
+	if len(results) != 70 || results[10] == nil || results[11] != nil {
+		t.Errorf("callback results = %v", results)
+	}
+}
+
+func TestBufferedWriter_maxLatency(t *testing.T) {
+	flushed := make(chan int, 1)
+	flush := func(records []interface{}) []error {
+		flushed <- len(records)
+		return nil
+	}
+	config := &BufferedWriterConfig{MaxLatency: 10 * time.Millisecond}
+	writer := NewBufferedWriter(config, 100, flush, nil, nil)
+	defer writer.Close()
+	_ = writer.Write(1)
+	_ = writer.Write(2)
+	select {
+	case count := <-flushed:
+		if count != 2 {
+			t.Errorf("flushed count = %d, want 2", count)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("records not flushed after max latency")
+	}
+}
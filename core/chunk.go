@@ -0,0 +1,224 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// Chunk is a continuous part of the items passed by caller,
+// [Start, End) is its index range in the original items
+type Chunk struct {
+	Index int
+	Start int
+	End   int
+}
+
+func (receiver Chunk) Len() int {
+	return receiver.End - receiver.Start
+}
+
+// ChunkCount returns the count of chunks split by SplitChunks
+func ChunkCount(total int, chunkSize int) int {
+	if total <= 0 || chunkSize <= 0 {
+		return 0
+	}
+	return (total + chunkSize - 1) / chunkSize
+}
+
+func SplitChunks(total int, chunkSize int) []Chunk {
+	if total <= 0 || chunkSize <= 0 {
+		return nil
+	}
+	chunks := make([]Chunk, 0, ChunkCount(total, chunkSize))
+	for start := 0; start < total; start += chunkSize {
+		end := start + chunkSize
+		if end > total {
+			end = total
+		}
+		chunks = append(chunks, Chunk{Index: len(chunks), Start: start, End: end})
+	}
+	return chunks
+}
+
+// ExecuteChunks calls execute for every chunk with at most `concurrency`
+// goroutines, and returns after all chunks are finished.
+// The execute func should store its result by Chunk.Index to avoid data race.
+func ExecuteChunks(chunks []Chunk, concurrency int, execute func(chunk Chunk)) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if concurrency > len(chunks) {
+		concurrency = len(chunks)
+	}
+	chunkCh := make(chan Chunk, len(chunks))
+	for _, chunk := range chunks {
+		chunkCh <- chunk
+	}
+	close(chunkCh)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		AsyncExecute(func() {
+			defer wg.Done()
+			for chunk := range chunkCh {
+				execute(chunk)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// ChunkWriter holds the funcs of writing one kind of items by WriteChunks.
+// The funcs are called with the chunks in order, except Write, which is
+// called concurrently and should store its response by Chunk.Index.
+type ChunkWriter struct {
+	// Write sends the items of chunk, and returns the status and
+	// the count of failed items of the response
+	Write func(chunk Chunk, opts []option.Option) (status *protocol.Status, failedCount int, err error)
+
+	// FailChunk reports every item of the chunk failed as a whole
+	FailChunk func(chunk Chunk, message string)
+
+	// CollectErrors reports the failed items of the chunk accepted by server
+	CollectErrors func(chunk Chunk)
+}
+
+// WriteChunks splits total items into chunks of chunkSize, writes them with
+// at most `concurrency` goroutines, then merges the results of chunks in order.
+// The merged status, success if all chunks succeed, is returned together
+// with the first error of chunks.
+func WriteChunks(total int, chunkSize int, concurrency int, opts []option.Option,
+	writer *ChunkWriter) (*protocol.Status, error) {
+	chunks := SplitChunks(total, chunkSize)
+	statuses := make([]*protocol.Status, len(chunks))
+	failedCounts := make([]int, len(chunks))
+	errs := make([]error, len(chunks))
+	ExecuteChunks(chunks, concurrency, func(chunk Chunk) {
+		statuses[chunk.Index], failedCounts[chunk.Index], errs[chunk.Index] =
+			writer.Write(chunk, ChunkOptions(opts, chunk))
+	})
+	var (
+		merged   *protocol.Status
+		firstErr error
+	)
+	for _, chunk := range chunks {
+		status, err := statuses[chunk.Index], errs[chunk.Index]
+		if firstErr == nil {
+			firstErr = err
+		}
+		merged = MergeChunkStatus(merged, status)
+		if failMsg := ChunkFailureMessage(status, failedCounts[chunk.Index], err); failMsg != "" {
+			writer.FailChunk(chunk, failMsg)
+			continue
+		}
+		writer.CollectErrors(chunk)
+	}
+	if merged == nil {
+		merged = &protocol.Status{Code: StatusCodeSuccess}
+	}
+	return merged, firstErr
+}
+
+// ChunkOptions makes the request id of every chunk unique if caller specified one,
+// otherwise server will reject the later chunks as idempotent requests
+func ChunkOptions(opts []option.Option, chunk Chunk) []option.Option {
+	requestId := option.Conv2Options(opts...).RequestId
+	if requestId == "" {
+		return opts
+	}
+	result := make([]option.Option, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, option.WithRequestId(requestId+"-"+strconv.Itoa(chunk.Index)))
+}
+
+// MatchIndexes finds the index in original items of every failed item of chunk,
+// `match` reports whether the failed item is the item at original index.
+// One item is matched at most once, so duplicated items map to different indexes,
+// the failed item that can't be matched gets -1.
+func MatchIndexes(chunk Chunk, failedCount int, match func(failedIdx, itemIdx int) bool) []int {
+	matched := make([]bool, chunk.Len())
+	indexes := make([]int, failedCount)
+	for failedIdx := range indexes {
+		indexes[failedIdx] = -1
+		for itemIdx := chunk.Start; itemIdx < chunk.End; itemIdx++ {
+			if matched[itemIdx-chunk.Start] || !match(failedIdx, itemIdx) {
+				continue
+			}
+			matched[itemIdx-chunk.Start] = true
+			indexes[failedIdx] = itemIdx
+			break
+		}
+	}
+	return indexes
+}
+
+// MatchJsonIndexes is MatchIndexes for the items compared as json data,
+// normalizeItem returns the item at original index normalized by NormalizeJson.
+// Every failed data and item is normalized at most once.
+func MatchJsonIndexes(chunk Chunk, failedData []string, normalizeItem func(itemIdx int) interface{}) []int {
+	failed := make([]interface{}, len(failedData))
+	for i, data := range failedData {
+		failed[i] = NormalizeJsonString(data)
+	}
+	items := make([]interface{}, chunk.Len())
+	normalized := make([]bool, chunk.Len())
+	return MatchIndexes(chunk, len(failedData), func(failedIdx, itemIdx int) bool {
+		if failed[failedIdx] == nil {
+			return false
+		}
+		offset := itemIdx - chunk.Start
+		if !normalized[offset] {
+			items[offset] = normalizeItem(itemIdx)
+			normalized[offset] = true
+		}
+		return reflect.DeepEqual(failed[failedIdx], items[offset])
+	})
+}
+
+// ChunkFailureMessage returns the reason why the whole chunk failed,
+// empty string means the chunk was accepted by server,
+// though some items of it may still be rejected.
+func ChunkFailureMessage(status *protocol.Status, failedCount int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if status.GetCode() != StatusCodeSuccess && failedCount == 0 {
+		return status.GetMessage()
+	}
+	return ""
+}
+
+// MergeChunkStatus keeps the first failure status of chunks
+func MergeChunkStatus(merged *protocol.Status, status *protocol.Status) *protocol.Status {
+	if status == nil {
+		return merged
+	}
+	if merged == nil || (merged.GetCode() == StatusCodeSuccess && status.GetCode() != StatusCodeSuccess) {
+		return status
+	}
+	return merged
+}
+
+// NormalizeJson converts value into the generic form decoded by encoding/json,
+// so that the data could be compared regardless of go types and key order.
+// Nil is returned if value can't be converted.
+func NormalizeJson(value interface{}) interface{} {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return NormalizeJsonString(string(bytes))
+}
+
+func NormalizeJsonString(data string) interface{} {
+	var result interface{}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		chunkSize int
+		want      []Chunk
+	}{
+		{
+			name:      "empty",
+			total:     0,
+			chunkSize: 100,
+			want:      nil,
+		},
+		{
+			name:      "one_chunk",
+			total:     100,
+			chunkSize: 100,
+			want:      []Chunk{{Index: 0, Start: 0, End: 100}},
+		},
+		{
+			name:      "tail_chunk",
+			total:     250,
+			chunkSize: 100,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 100},
+				{Index: 1, Start: 100, End: 200},
+				{Index: 2, Start: 200, End: 250},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitChunks(tt.total, tt.chunkSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitChunks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchIndexes(t *testing.T) {
+	items := []string{"a", "b", "c", "b", "d", "e"}
+	chunk := Chunk{Index: 1, Start: 2, End: 6}
+	failed := []string{"b", "e", "b", "x"}
+	got := MatchIndexes(chunk, len(failed), func(failedIdx, itemIdx int) bool {
+		return failed[failedIdx] == items[itemIdx]
+	})
+	// the second "b" has no unmatched item left in chunk
+	want := []int{3, 5, -1, -1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchIndexes() = %v, want %v", got, want)
+	}
+}
+
+func TestChunkOptions(t *testing.T) {
+	opts := []option.Option{option.WithRequestId("req")}
+	got := option.Conv2Options(ChunkOptions(opts, Chunk{Index: 2})...).RequestId
+	if got != "req-2" {
+		t.Errorf("ChunkOptions() request id = %v, want %v", got, "req-2")
+	}
+	if got := ChunkOptions(nil, Chunk{Index: 2}); len(got) != 0 {
+		t.Errorf("ChunkOptions() should not add request id when caller not specified")
+	}
+}
+
+func TestExecuteChunks(t *testing.T) {
+	chunks := SplitChunks(1000, 7)
+	executed := make([]int, len(chunks))
+	ExecuteChunks(chunks, 4, func(chunk Chunk) {
+		executed[chunk.Index]++
+	})
+	for i, count := range executed {
+		if count != 1 {
+			t.Errorf("chunk %d executed %d times", i, count)
+		}
+	}
+}
+
+func TestMatchJsonIndexes(t *testing.T) {
+	items := []string{`{"id":1,"a":"x"}`, `{"id":2}`, `{"a":"x","id":1}`}
+	normalized := 0
+	got := MatchJsonIndexes(Chunk{Start: 0, End: 3}, []string{`{"a":"x","id":1}`, `{"id":1,"a":"x"}`, `invalid`},
+		func(itemIdx int) interface{} {
+			normalized++
+			return NormalizeJsonString(items[itemIdx])
+		})
+	if want := []int{0, 2, -1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchJsonIndexes() = %v, want %v", got, want)
+	}
+	if normalized != 3 {
+		t.Errorf("expect every item normalized once, got %d times", normalized)
+	}
+}
+
+func TestWriteChunks(t *testing.T) {
+	var failed, collected []int
+	status, err := WriteChunks(5, 2, 2, nil, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*protocol.Status, int, error) {
+			if chunk.Index == 1 {
+				return &protocol.Status{Code: 1001, Message: "rejected"}, 0, nil
+			}
+			return &protocol.Status{Code: StatusCodeSuccess}, 0, nil
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			failed = append(failed, chunk.Index)
+		},
+		CollectErrors: func(chunk Chunk) {
+			collected = append(collected, chunk.Index)
+		},
+	})
+	if err != nil || status.GetCode() != 1001 {
+		t.Errorf("expect the failure status merged, got %v err:%v", status, err)
+	}
+	if !reflect.DeepEqual(failed, []int{1}) || !reflect.DeepEqual(collected, []int{0, 2}) {
+		t.Errorf("unexpected merged chunks, failed:%v collected:%v", failed, collected)
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultReleaseTimeout is the deadline of draining client by Release
+const DefaultReleaseTimeout = 10 * time.Second
+
+var (
+	ErrClientClosed = errors.New("client is closed")
+	ErrCloseTimeout = errors.New("close timeout, the client is not drained")
+)
+
+// CloseTimeoutError tells the stage of CloseClient not drained in time,
+// errors.Is(err, ErrCloseTimeout) reports true for it
+type CloseTimeoutError struct {
+	Stage string
+}
+
+func (receiver *CloseTimeoutError) Error() string {
+	return fmt.Sprintf("close timeout, the %s of client is not drained", receiver.Stage)
+}
+
+func (receiver *CloseTimeoutError) Is(target error) bool {
+	return target == ErrCloseTimeout
+}
+
+type closeStage struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// CloseClient is the Close of product clients, which first drains the components
+// depending on client by hooks, e.g. BufferedWriter, then rejects the new calls,
+// and waits for the calls in flight and the ping goroutine until ctx is done.
+// Each stage gets an even share of the time left by ctx, so a stage timed out
+// doesn't leave the later ones an expired ctx. *CloseTimeoutError naming the
+// first stage timed out is returned if ctx is done before drained.
+func CloseClient(ctx context.Context, hooks *ReleaseHooks,
+	caller *HttpCaller, availabler *HostAvailabler) error {
+	stages := []closeStage{
+		{name: "release hooks", close: hooks.runContext},
+		{name: "calls in flight", close: caller.Close},
+		{name: "host availabler", close: availabler.Close},
+	}
+	var closeErr error
+	for i, stage := range stages {
+		stageCtx, cancel := stageContext(ctx, len(stages)-i)
+		err := stage.close(stageCtx)
+		cancel()
+		if err == ErrCloseTimeout && closeErr == nil {
+			closeErr = &CloseTimeoutError{Stage: stage.name}
+		}
+	}
+	return closeErr
+}
+
+// stageContext shares the time left by ctx evenly among the stages left
+func stageContext(ctx context.Context, stagesLeft int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(stagesLeft))
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+func TestCloseClient(t *testing.T) {
+	clientContext, err := NewContext(&ContextParam{Tenant: "t", TenantId: "1", Token: "token",
+		UseAirAuth: true, Region: RegionSg, Hosts: []string{"127.0.0.1:1", "127.0.0.1:2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := NewHttpCaller(clientContext)
+	availabler := NewHostAvailabler(&fakeURLCenter{}, clientContext)
+	hooks := &ReleaseHooks{}
+	hookCalls := 0
+	hooks.Add(func() { hookCalls++ })
+
+	if err := caller.beginCall(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = CloseClient(ctx, hooks, caller, availabler)
+	timeoutErr, ok := err.(*CloseTimeoutError)
+	if !ok || !errors.Is(err, ErrCloseTimeout) || timeoutErr.Stage != "calls in flight" {
+		t.Fatalf("expect timeout of calls in flight, got %v", err)
+	}
+	err = caller.DoPbRequest("http://127.0.0.1:1", &protocol.Status{}, &protocol.Status{}, &option.Options{})
+	if err != ErrClientClosed {
+		t.Fatalf("expect ErrClientClosed after close, got %v", err)
+	}
+
+	caller.endCall()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := CloseClient(ctx, hooks, caller, availabler); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-availabler.done:
+	default:
+		t.Fatal("ping goroutine should exit")
+	}
+	if hookCalls != 1 || caller.InFlight() != 0 {
+		t.Fatalf("unexpected hook calls %d, in flight %d", hookCalls, caller.InFlight())
+	}
+}
+
+func TestCloseClientHooksTimeout(t *testing.T) {
+	clientContext, err := NewContext(&ContextParam{Tenant: "t", TenantId: "1", Token: "token",
+		UseAirAuth: true, Region: RegionSg, Hosts: []string{"127.0.0.1:1", "127.0.0.1:2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := NewHttpCaller(clientContext)
+	availabler := NewHostAvailabler(&fakeURLCenter{}, clientContext)
+	hooks := &ReleaseHooks{}
+	blocked := make(chan struct{})
+	defer close(blocked)
+	hooks.Add(func() { <-blocked })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err = CloseClient(ctx, hooks, caller, availabler)
+	timeoutErr, ok := err.(*CloseTimeoutError)
+	if !ok || timeoutErr.Stage != "release hooks" {
+		t.Fatalf("expect timeout of release hooks, got %v", err)
+	}
+	// the later stages are drained within their own share of deadline
+	select {
+	case <-availabler.done:
+	default:
+		t.Fatal("ping goroutine should exit though hooks timed out")
+	}
+}
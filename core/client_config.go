@@ -0,0 +1,324 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+const DefaultConfigEnvPrefix = "BYTEPLUS_"
+
+// The keys of config file, the env names are the upper keys with prefix,
+// such as "BYTEPLUS_TENANT_ID". The secret could be read from the file
+// at "<key>_file" instead, such as "token_file".
+var clientConfigKeys = []string{
+	"tenant", "tenant_id", "token", "token_file", "ak", "ak_file", "sk", "sk_file",
+	"region", "hosts", "schema", "host_header", "headers", "timeout", "server_timeout",
+}
+
+// ClientConfig holds the settings of ClientBuilder loaded from file or env,
+// which is set into builder by its Config method.
+type ClientConfig struct {
+	Tenant     string
+	TenantId   string
+	Token      string
+	AK         string
+	SK         string
+	Region     Region
+	Hosts      []string
+	Schema     string
+	HostHeader string
+	Headers    map[string]string
+	// The default timeouts of calls
+	Timeout       time.Duration
+	ServerTimeout time.Duration
+}
+
+// LoadClientConfig
+//
+// Loads config from the JSON file, or the YAML file with ".yaml" or ".yml"
+// extension, the YAML file supports the scalars, lists and maps of one level.
+// Hosts is a list or a string separated by ',', headers is a map, timeouts are
+// durations like "800ms", or milliseconds if they're bare integers like 800,
+// which is the same for JSON, YAML and env. JSON numbers of ids are kept exactly.
+// The invalid values are returned as FieldErrors with the keys as path.
+func LoadClientConfig(path string) (*ClientConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		raw, err = parseYamlConfig(string(content))
+	default:
+		// numbers are decoded as json.Number, so the long ids keep all digits
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&raw)
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("parse config %s fail, %s", path, err.Error()))
+	}
+	for key := range raw {
+		if !isClientConfigKey(key) {
+			return nil, FieldErrors{{Index: -1, Path: key, Message: "is unknown"}}
+		}
+	}
+	// the secret files are relative to the config file
+	dir := filepath.Dir(path)
+	values := &configValues{raw: raw, name: func(key string) string { return key }, dir: dir}
+	return values.decode()
+}
+
+// LoadClientConfigFromEnv loads config from the env named by the upper keys
+// with prefix, DefaultConfigEnvPrefix is used if prefix is empty.
+// Hosts are separated by ',', headers are like "K1=V1,K2=V2", timeouts are like
+// the ones of LoadClientConfig.
+func LoadClientConfigFromEnv(prefix string) (*ClientConfig, error) {
+	if prefix == "" {
+		prefix = DefaultConfigEnvPrefix
+	}
+	envName := func(key string) string { return prefix + strings.ToUpper(key) }
+	raw := make(map[string]interface{})
+	for _, key := range clientConfigKeys {
+		if value, exist := os.LookupEnv(envName(key)); exist {
+			raw[key] = value
+		}
+	}
+	values := &configValues{raw: raw, name: envName}
+	return values.decode()
+}
+
+// FillContextParam sets the non-empty values of config into param,
+// the timeouts are added to the default options
+func (receiver *ClientConfig) FillContextParam(param *ContextParam) {
+	fillString := func(target *string, value string) {
+		if value != "" {
+			*target = value
+		}
+	}
+	fillString(&param.Tenant, receiver.Tenant)
+	fillString(&param.TenantId, receiver.TenantId)
+	fillString(&param.Token, receiver.Token)
+	fillString(&param.AK, receiver.AK)
+	fillString(&param.SK, receiver.SK)
+	fillString(&param.Schema, receiver.Schema)
+	fillString(&param.HostHeader, receiver.HostHeader)
+	if receiver.Region != RegionUnknown {
+		param.Region = receiver.Region
+	}
+	if len(receiver.Hosts) > 0 {
+		param.Hosts = receiver.Hosts
+	}
+	if len(receiver.Headers) > 0 {
+		param.Headers = receiver.Headers
+	}
+	param.DefaultOptions = append(param.DefaultOptions, receiver.Options()...)
+}
+
+// Options returns the timeouts of config as the options of calls
+func (receiver *ClientConfig) Options() []option.Option {
+	var opts []option.Option
+	if receiver.Timeout > 0 {
+		opts = append(opts, option.WithTimeout(receiver.Timeout))
+	}
+	if receiver.ServerTimeout > 0 {
+		opts = append(opts, option.WithServerTimeout(receiver.ServerTimeout))
+	}
+	return opts
+}
+
+func isClientConfigKey(key string) bool {
+	for _, configKey := range clientConfigKeys {
+		if key == configKey {
+			return true
+		}
+	}
+	return false
+}
+
+// configValues decodes the raw values of file or env,
+// and collects the invalid ones named as they're written
+type configValues struct {
+	raw map[string]interface{}
+	// the name of key in errors, which is env name for env
+	name func(key string) string
+	// the dir of relative secret files
+	dir    string
+	errors FieldErrors
+}
+
+func (receiver *configValues) decode() (*ClientConfig, error) {
+	config := &ClientConfig{
+		Tenant:        receiver.string("tenant"),
+		TenantId:      receiver.string("tenant_id"),
+		Token:         receiver.secret("token"),
+		AK:            receiver.secret("ak"),
+		SK:            receiver.secret("sk"),
+		Region:        receiver.region("region"),
+		Hosts:         receiver.list("hosts"),
+		Schema:        receiver.string("schema"),
+		HostHeader:    receiver.string("host_header"),
+		Headers:       receiver.dict("headers"),
+		Timeout:       receiver.duration("timeout"),
+		ServerTimeout: receiver.duration("server_timeout"),
+	}
+	if schema := strings.ToLower(config.Schema); schema != "" && schema != "http" && schema != "https" {
+		receiver.addError("schema", "should be http or https, got %q", config.Schema)
+	}
+	if len(receiver.errors) > 0 {
+		return nil, receiver.errors
+	}
+	return config, nil
+}
+
+func (receiver *configValues) addError(key string, format string, args ...interface{}) {
+	receiver.errors = append(receiver.errors,
+		&FieldError{Index: -1, Path: receiver.name(key), Message: fmt.Sprintf(format, args...)})
+}
+
+func (receiver *configValues) string(key string) string {
+	value, exist := receiver.raw[key]
+	if !exist || value == nil {
+		return ""
+	}
+	switch typed := value.(type) {
+	case string:
+		return strings.TrimSpace(typed)
+	case json.Number:
+		return typed.String()
+	case bool:
+		return fmt.Sprint(typed)
+	}
+	receiver.addError(key, "should be a string")
+	return ""
+}
+
+// secret reads the value of key, or the content of file at "<key>_file"
+func (receiver *configValues) secret(key string) string {
+	value, path := receiver.string(key), receiver.string(key+"_file")
+	if value != "" && path != "" {
+		receiver.addError(key+"_file", "conflicts with %s", receiver.name(key))
+		return ""
+	}
+	if path == "" {
+		return value
+	}
+	if !filepath.IsAbs(path) && receiver.dir != "" {
+		path = filepath.Join(receiver.dir, path)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		receiver.addError(key+"_file", "read fail, %s", err.Error())
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func (receiver *configValues) region(key string) Region {
+	name := receiver.string(key)
+	if name == "" {
+		receiver.addError(key, "is required")
+		return RegionUnknown
+	}
+	region, err := ParseRegion(name)
+	if err != nil {
+		receiver.addError(key, "%s", err.Error())
+	}
+	return region
+}
+
+// list accepts a list of strings, or a string separated by ','
+func (receiver *configValues) list(key string) []string {
+	value, exist := receiver.raw[key]
+	if !exist || value == nil {
+		return nil
+	}
+	var items []string
+	switch typed := value.(type) {
+	case string:
+		items = strings.Split(typed, ",")
+	case []interface{}:
+		for _, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				receiver.addError(key, "should be a list of strings")
+				return nil
+			}
+			items = append(items, text)
+		}
+	default:
+		receiver.addError(key, "should be a list of strings")
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// dict accepts a map of strings, or a string like "K1=V1,K2=V2"
+func (receiver *configValues) dict(key string) map[string]string {
+	value, exist := receiver.raw[key]
+	if !exist || value == nil {
+		return nil
+	}
+	result := make(map[string]string)
+	switch typed := value.(type) {
+	case string:
+		for _, pair := range strings.Split(typed, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			idx := strings.IndexByte(pair, '=')
+			if idx <= 0 {
+				receiver.addError(key, "should be like K1=V1,K2=V2, got %q", pair)
+				return nil
+			}
+			result[strings.TrimSpace(pair[:idx])] = strings.TrimSpace(pair[idx+1:])
+		}
+	case map[string]interface{}:
+		for name, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				receiver.addError(key, "value of %s should be a string", name)
+				return nil
+			}
+			result[name] = text
+		}
+	default:
+		receiver.addError(key, "should be a map of strings")
+		return nil
+	}
+	return result
+}
+
+// duration accepts a duration string, or a bare integer of milliseconds
+func (receiver *configValues) duration(key string) time.Duration {
+	text := receiver.string(key)
+	if text == "" {
+		return 0
+	}
+	if millis, err := strconv.ParseInt(text, 10, 64); err == nil && millis >= 0 {
+		return time.Duration(millis) * time.Millisecond
+	}
+	result, err := time.ParseDuration(text)
+	if err != nil || result < 0 {
+		receiver.addError(key, "invalid duration %q, should be like \"800ms\"", text)
+		return 0
+	}
+	return result
+}
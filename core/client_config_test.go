@@ -0,0 +1,115 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadClientConfigYaml(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	content := `# retail client
+tenant: demo
+tenant_id: "1234"
+token_file: token
+region: SG
+hosts: [a.com, b.com]
+headers:
+  X-Env: prod # inline comment
+timeout: 800ms
+`
+	path := filepath.Join(dir, "client.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadClientConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ClientConfig{
+		Tenant:   "demo",
+		TenantId: "1234",
+		Token:    "secret",
+		Region:   RegionSg,
+		Hosts:    []string{"a.com", "b.com"},
+		Headers:  map[string]string{"X-Env": "prod"},
+		Timeout:  800 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("unexpected config %+v", config)
+	}
+}
+
+func TestLoadClientConfigErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.json")
+	content := `{"tenant": "demo", "token": "t", "token_file": "token", "region": "mars", "timeout": "soon"}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadClientConfig(path)
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("expect FieldErrors, got %v", err)
+	}
+	var paths []string
+	for _, fieldError := range fieldErrors {
+		paths = append(paths, fieldError.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"token_file", "region", "timeout"}) {
+		t.Fatalf("unexpected error keys %v", paths)
+	}
+}
+
+func TestLoadClientConfigFromEnv(t *testing.T) {
+	envs := map[string]string{
+		"TEST_TENANT_ID": "1234",
+		"TEST_REGION":    "air_cn",
+		"TEST_HEADERS":   "K1=V1, K2=V2",
+		"TEST_SCHEMA":    "ftp",
+	}
+	for name, value := range envs {
+		_ = os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	_, err := LoadClientConfigFromEnv("TEST_")
+	if err == nil || err.Error() != `invalid fields: TEST_SCHEMA: should be http or https, got "ftp"` {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = os.Setenv("TEST_SCHEMA", "http")
+	config, err := LoadClientConfigFromEnv("TEST_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Region != RegionAirCn || !reflect.DeepEqual(config.Headers, map[string]string{"K1": "V1", "K2": "V2"}) {
+		t.Fatalf("unexpected config %+v", config)
+	}
+}
+
+func TestLoadClientConfigNumbers(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "client.json")
+	content := `{"tenant": "demo", "tenant_id": 1234567890123, "token": "t", "region": "SG", "timeout": 800}`
+	if err := ioutil.WriteFile(jsonPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	yamlPath := filepath.Join(dir, "client.yaml")
+	content = "tenant: demo\ntenant_id: 1234567890123\ntoken: t\nregion: SG\ntimeout: 800\n"
+	if err := ioutil.WriteFile(yamlPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{jsonPath, yamlPath} {
+		config, err := LoadClientConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.TenantId != "1234567890123" || config.Timeout != 800*time.Millisecond {
+			t.Fatalf("unexpected config of %s: %+v", filepath.Base(path), config)
+		}
+	}
+}
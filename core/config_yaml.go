@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYamlConfig parses the subset of YAML used by config file:
+// "key: value" at top level, whose value is a scalar, a flow list like "[a, b]",
+// or the indented block of list items "- a" or map entries "name: value".
+// The values are shaped like JSON, lists are []interface{}, maps are map[string]interface{}.
+func parseYamlConfig(content string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	// the key whose value is the indented block following
+	blockKey := ""
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line = strings.TrimRight(stripYamlComment(line), " \t\r")
+		if strings.TrimSpace(line) == "" || strings.TrimSpace(line) == "---" {
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		line = strings.TrimSpace(line)
+		if indented {
+			if blockKey == "" {
+				return nil, yamlLineError(lineNo, "unexpected indent")
+			}
+			if err := addYamlBlockLine(result, blockKey, line); err != nil {
+				return nil, yamlLineError(lineNo, err.Error())
+			}
+			continue
+		}
+		key, value, err := splitYamlEntry(line)
+		if err != nil {
+			return nil, yamlLineError(lineNo, err.Error())
+		}
+		if _, exist := result[key]; exist {
+			return nil, yamlLineError(lineNo, "duplicate key "+key)
+		}
+		blockKey = ""
+		if value == "" {
+			blockKey = key
+			result[key] = nil
+			continue
+		}
+		result[key], err = parseYamlValue(value)
+		if err != nil {
+			return nil, yamlLineError(lineNo, err.Error())
+		}
+	}
+	return result, nil
+}
+
+func addYamlBlockLine(result map[string]interface{}, key string, line string) error {
+	if strings.HasPrefix(line, "- ") || line == "-" {
+		list, ok := result[key].([]interface{})
+		if result[key] != nil && !ok {
+			return errors.New("list item in map")
+		}
+		item, err := unquoteYaml(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return err
+		}
+		result[key] = append(list, item)
+		return nil
+	}
+	dict, ok := result[key].(map[string]interface{})
+	if result[key] != nil && !ok {
+		return errors.New("map entry in list")
+	}
+	if dict == nil {
+		dict = make(map[string]interface{})
+		result[key] = dict
+	}
+	name, value, err := splitYamlEntry(line)
+	if err != nil {
+		return err
+	}
+	dict[name], err = unquoteYaml(value)
+	return err
+}
+
+func splitYamlEntry(line string) (string, string, error) {
+	idx := strings.Index(line, ":")
+	if idx <= 0 {
+		return "", "", errors.New("expect \"key: value\"")
+	}
+	key, err := unquoteYaml(strings.TrimSpace(line[:idx]))
+	if err != nil {
+		return "", "", err
+	}
+	return key, strings.TrimSpace(line[idx+1:]), nil
+}
+
+func parseYamlValue(value string) (interface{}, error) {
+	if !strings.HasPrefix(value, "[") {
+		return unquoteYaml(value)
+	}
+	if !strings.HasSuffix(value, "]") {
+		return nil, errors.New("unclosed list")
+	}
+	list := make([]interface{}, 0)
+	for _, item := range strings.Split(value[1:len(value)-1], ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		text, err := unquoteYaml(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, text)
+	}
+	return list, nil
+}
+
+func unquoteYaml(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	if len(value) >= 1 && value[0] == '"' {
+		return strconv.Unquote(value)
+	}
+	return value, nil
+}
+
+// stripYamlComment removes the comment starting with '#' outside of quotes,
+// which is at line start or follows a space
+func stripYamlComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func yamlLineError(lineNo int, message string) error {
+	return errors.New(fmt.Sprintf("line %d: %s", lineNo, message))
+}
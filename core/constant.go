@@ -1,31 +1,5 @@
 package core
 
-type Region int
-
-const (
-	RegionUnknown Region = iota
-	RegionCn
-	RegionSg
-	RegionUs
-	RegionAirCn
-	RegionAirSg
-	RegionSaasSg
-)
-
-var (
-	cnHosts = []string{"rec-b.volcengineapi.com", "rec.volcengineapi.com"}
-
-	sgHosts = []string{"rec-ap-singapore-1.byteplusapi.com"}
-
-	usHosts = []string{"rec-us-east-1.byteplusapi.com"}
-
-	airCnHosts = []string{"byteair-api-cn1.snssdk.com"}
-
-	airSgHosts = []string{"byteair-api-sg1.byteintlapi.com"}
-
-	saasSgHosts = []string{"rec-api-sg1.recplusapi.com"}
-)
-
 const (
 	MaxWriteItemCount = 100
 
@@ -51,6 +25,10 @@ const (
 	StatusCodeTooManyRequest = 429
 )
 
+// The topics of the user, product and user event data of retail and saas,
+// which are recorded in sync sessions and dead letters
 const (
-	volcAuthService = "air"
+	TopicUser      = "user"
+	TopicProduct   = "product"
+	TopicUserEvent = "user_event"
 )
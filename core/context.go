@@ -2,7 +2,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/byteplus-sdk/sdk-go/core/option"
 	"github.com/valyala/fasthttp"
 )
 
@@ -18,6 +20,13 @@ type ContextParam struct {
 	Headers    map[string]string
 	Region     Region
 	UseAirAuth bool
+	RecordMode RecordMode
+	RecordDir  string
+	// The options applied to all calls before the options of call
+	DefaultOptions []option.Option
+	// Shares the hosts, http clients and host health of transport,
+	// the host fields above are ignored if it's set
+	Transport *SharedTransport
 }
 
 func (receiver *ContextParam) checkRequiredField(param *ContextParam) error {
@@ -33,6 +42,12 @@ func (receiver *ContextParam) checkRequiredField(param *ContextParam) error {
 	if param.Region == RegionUnknown {
 		return errors.New("region is null")
 	}
+	if param.Region.config() == nil {
+		return errors.New(fmt.Sprintf("region %s is not registered", param.Region))
+	}
+	if param.RecordMode != RecordModeNone && param.RecordDir == "" {
+		return errors.New("record dir is null")
+	}
 	return nil
 }
 
@@ -62,18 +77,33 @@ func NewContext(param *ContextParam) (*Context, error) {
 		hosts:           param.Hosts,
 		customerHeaders: param.Headers,
 		useAirAuth:      param.UseAirAuth,
+		recordMode:      param.RecordMode,
+		recordDir:       param.RecordDir,
+		region:          param.Region,
+		defaultOptions:  append([]option.Option(nil), param.DefaultOptions...),
 	}
-	result.fillHosts(param)
 	result.fillVolcCredentials(param)
-	if param.HostHeader != "" {
-		result.hostHTTPCli = &fasthttp.HostClient{Addr: result.hosts[0]}
-	} else {
-		result.defaultHTTPCli = &fasthttp.Client{}
+	if param.Transport != nil {
+		if err := param.Transport.share(result); err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
+	result.fillHosts(param)
+	result.fillHttpClients()
 	result.fillDefault()
 	return result, nil
 }
 
+// httpClients is shared by the contexts of SharedTransport,
+// so that the host switched by HostAvailabler applies to all of them
+type httpClients struct {
+	// fasthttp default client not support define host
+	hostHTTPCli *fasthttp.HostClient
+
+	defaultHTTPCli *fasthttp.Client
+}
+
 type Context struct {
 	// A unique token assigned by bytedance, which is used to
 	// generate an authenticated signature when building a request.
@@ -106,13 +136,26 @@ type Context struct {
 	// Customer-defined http headers, all requests will include these headers
 	customerHeaders map[string]string
 
-	// fasthttp default client not support define host
-	hostHTTPCli *fasthttp.HostClient
+	// The options applied to all calls, see HttpCaller.Options
+	defaultOptions []option.Option
 
-	defaultHTTPCli *fasthttp.Client
+	httpClients *httpClients
+
+	// Not nil if the hosts and http clients are shared with other contexts
+	transport *SharedTransport
 
 	// use air auth, otherwise use volc auth
 	useAirAuth bool
+
+	// Record calls into recordDir, or replay calls from it without network
+	recordMode RecordMode
+
+	recordDir string
+
+	region Region
+
+	// Path of URL checking host health, see RegionConfig.PingPath
+	pingPath string
 }
 
 func (receiver *Context) Tenant() string {
@@ -159,59 +202,49 @@ func (receiver *Context) CustomerHeaders() map[string]string {
 	return receiver.customerHeaders
 }
 
+func (receiver *Context) Region() Region {
+	return receiver.region
+}
+
+func (receiver *Context) RecordMode() RecordMode {
+	return receiver.recordMode
+}
+
 func (receiver *Context) fillHosts(param *ContextParam) {
 	if len(param.Hosts) > 0 {
 		receiver.hosts = param.Hosts
 		return
 	}
-	if param.Region == RegionCn {
-		receiver.hosts = cnHosts
-		return
-	}
-	if param.Region == RegionSg {
-		receiver.hosts = sgHosts
-		return
-	}
-	if param.Region == RegionUs {
-		receiver.hosts = usHosts
-		return
-	}
-	if param.Region == RegionAirCn {
-		receiver.hosts = airCnHosts
-		return
-	}
-	if param.Region == RegionAirSg {
-		receiver.hosts = airSgHosts
-		return
-	}
-	if param.Region == RegionSaasSg {
-		receiver.hosts = saasSgHosts
-		return
+	receiver.hosts = param.Region.config().Hosts
+}
+
+func (receiver *Context) fillHttpClients() {
+	receiver.httpClients = &httpClients{}
+	if receiver.hostHeader != "" {
+		receiver.httpClients.hostHTTPCli = &fasthttp.HostClient{Addr: receiver.hosts[0]}
+	} else {
+		receiver.httpClients.defaultHTTPCli = &fasthttp.Client{}
 	}
 }
 
 func (receiver *Context) fillDefault() {
+	if receiver.schema == "" {
+		receiver.schema = receiver.region.config().Schema
+	}
 	if receiver.schema == "" {
 		receiver.schema = "https"
 	}
+	if receiver.pingPath == "" {
+		receiver.pingPath = receiver.region.config().PingPath
+	}
 }
 
 func (receiver *Context) fillVolcCredentials(param *ContextParam) {
-	c := Credential{
+	regionConfig := param.Region.config()
+	receiver.volcCredentials = Credential{
 		AccessKeyID:     param.AK,
 		SecretAccessKey: param.SK,
-		Service:         volcAuthService,
+		Region:          regionConfig.VolcRegion,
+		Service:         regionConfig.VolcService,
 	}
-
-	// fill region
-	switch param.Region {
-	case RegionSg, RegionAirSg, RegionSaasSg:
-		c.Region = "ap-singapore-1"
-	case RegionUs:
-		c.Region = "us-east-1"
-	default: //Region "CN" and "AIR_CN" belong to "cn-north-1"
-		c.Region = "cn-north-1"
-	}
-
-	receiver.volcCredentials = c
 }
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The tag of struct field for EncodeData and DecodeData, such as
+//
+//	type User struct {
+//		UserId   string    `data:"user_id"`
+//		Age      int       `data:"age,omitempty"`
+//		Register time.Time `data:"register_time,timestamp"`
+//		Birthday time.Time `data:"birthday,timestamp=2006-01-02"`
+//		Profile  *Profile  `data:"profile,json"`
+//		Secret   string    `data:"-"`
+//	}
+//
+// The options after name:
+//   - omitempty: the field is omitted if it's zero value
+//   - timestamp: time.Time is encoded as unix seconds, "timestamp=ms" as unix milliseconds,
+//     otherwise the value after '=' is the layout of the formatted string
+//   - json: the value is encoded as a json string, for the nested values stored in string field
+//
+// The field without tag uses the name of json tag, or the field name.
+const dataTagName = "data"
+
+const (
+	timestampSeconds = "s"
+	timestampMillis  = "ms"
+)
+
+type dataField struct {
+	index     int
+	name      string
+	omitEmpty bool
+	// empty means not a timestamp
+	timestamp string
+	asJson    bool
+}
+
+var dataFieldsCache sync.Map
+
+func dataFieldsOf(structType reflect.Type) ([]*dataField, error) {
+	if cached, ok := dataFieldsCache.Load(structType); ok {
+		return cached.([]*dataField), nil
+	}
+	var fields []*dataField
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		if structField.PkgPath != "" {
+			continue
+		}
+		tag, hasTag := structField.Tag.Lookup(dataTagName)
+		if tag == "-" {
+			continue
+		}
+		if !hasTag {
+			if jsonTag := structField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+				tag = strings.Split(jsonTag, ",")[0]
+			}
+		}
+		parts := strings.Split(tag, ",")
+		field := &dataField{index: i, name: parts[0]}
+		if field.name == "" {
+			field.name = structField.Name
+		}
+		for _, opt := range parts[1:] {
+			switch {
+			case opt == "omitempty":
+				field.omitEmpty = true
+			case opt == "json":
+				field.asJson = true
+			case opt == "timestamp":
+				field.timestamp = timestampSeconds
+			case strings.HasPrefix(opt, "timestamp="):
+				field.timestamp = strings.TrimPrefix(opt, "timestamp=")
+			default:
+				return nil, errors.New(fmt.Sprintf("unknown option %s of field %s.%s",
+					opt, structType.Name(), structField.Name))
+			}
+		}
+		if field.timestamp != "" && !isTimeType(structField.Type) {
+			return nil, errors.New(fmt.Sprintf("timestamp option requires time.Time, field %s.%s",
+				structType.Name(), structField.Name))
+		}
+		fields = append(fields, field)
+	}
+	dataFieldsCache.Store(structType, fields)
+	return fields, nil
+}
+
+func isTimeType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType == reflect.TypeOf(time.Time{})
+}
+
+// EncodeData converts a slice of structs or struct pointers into the data
+// of WriteData, the fields are encoded according to the `data` tag
+func EncodeData(items interface{}) ([]map[string]interface{}, error) {
+	slice := reflect.ValueOf(items)
+	if slice.Kind() != reflect.Slice {
+		return nil, errors.New(fmt.Sprintf("expect slice of struct, got %T", items))
+	}
+	dataList := make([]map[string]interface{}, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		data, err := encodeStruct(slice.Index(i))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("[%d]: %s", i, err.Error()))
+		}
+		dataList[i] = data
+	}
+	return dataList, nil
+}
+
+func encodeStruct(value reflect.Value) (map[string]interface{}, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, errors.New("item is nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf("expect struct, got %s", value.Type()))
+	}
+	fields, err := dataFieldsOf(value.Type())
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		encoded, err := encodeField(field, fieldValue)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("field %s: %s", field.name, err.Error()))
+		}
+		data[field.name] = encoded
+	}
+	return data, nil
+}
+
+func encodeField(field *dataField, value reflect.Value) (interface{}, error) {
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil, nil
+	}
+	switch {
+	case field.timestamp != "":
+		t := reflect.Indirect(value).Interface().(time.Time)
+		switch field.timestamp {
+		case timestampSeconds:
+			return t.Unix(), nil
+		case timestampMillis:
+			return t.UnixNano() / int64(time.Millisecond), nil
+		default:
+			return t.Format(field.timestamp), nil
+		}
+	case field.asJson:
+		bytes, err := json.Marshal(value.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return string(bytes), nil
+	default:
+		return value.Interface(), nil
+	}
+}
+
+// DecodeData decodes the data returned by server, such as DataError.data,
+// into item, which should be a pointer of the struct encoded by EncodeData
+func DecodeData(data string, item interface{}) error {
+	value := reflect.ValueOf(item)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf("expect pointer of struct, got %T", item))
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	var dataMap map[string]interface{}
+	if err := decoder.Decode(&dataMap); err != nil {
+		return err
+	}
+	value = value.Elem()
+	fields, err := dataFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		raw, ok := dataMap[field.name]
+		if !ok || raw == nil {
+			continue
+		}
+		if err := decodeField(field, raw, value.Field(field.index)); err != nil {
+			return errors.New(fmt.Sprintf("field %s: %s", field.name, err.Error()))
+		}
+	}
+	return nil
+}
+
+func decodeField(field *dataField, raw interface{}, target reflect.Value) error {
+	switch {
+	case field.timestamp != "":
+		t, err := decodeTimestamp(field.timestamp, raw)
+		if err != nil {
+			return err
+		}
+		if target.Kind() == reflect.Ptr {
+			target.Set(reflect.ValueOf(&t))
+		} else {
+			target.Set(reflect.ValueOf(t))
+		}
+		return nil
+	case field.asJson:
+		str, ok := raw.(string)
+		if !ok {
+			return errors.New(fmt.Sprintf("expect json string, got %T", raw))
+		}
+		return json.Unmarshal([]byte(str), target.Addr().Interface())
+	default:
+		// the decoded json value is converted into the type of field by json again
+		bytes, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(bytes, target.Addr().Interface())
+	}
+}
+
+func decodeTimestamp(format string, raw interface{}) (time.Time, error) {
+	if format != timestampSeconds && format != timestampMillis {
+		str, ok := raw.(string)
+		if !ok {
+			return time.Time{}, errors.New(fmt.Sprintf("expect time string, got %T", raw))
+		}
+		return time.Parse(format, str)
+	}
+	number, ok := raw.(json.Number)
+	if !ok {
+		return time.Time{}, errors.New(fmt.Sprintf("expect timestamp, got %T", raw))
+	}
+	timestamp, err := number.Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if format == timestampMillis {
+		return time.Unix(0, timestamp*int64(time.Millisecond)), nil
+	}
+	return time.Unix(timestamp, 0), nil
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type codecProfile struct {
+	City string `json:"city"`
+}
+
+type codecUser struct {
+	UserId   string        `data:"user_id"`
+	Age      int           `data:"age,omitempty"`
+	Register time.Time     `data:"register_time,timestamp"`
+	Birthday *time.Time    `data:"birthday,timestamp=2006-01-02,omitempty"`
+	Profile  *codecProfile `data:"profile,json"`
+	Tags     []string      `json:"tags"`
+	Secret   string        `data:"-"`
+}
+
+func TestEncodeDecodeData(t *testing.T) {
+	birthday := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	users := []*codecUser{{
+		UserId:   "u1",
+		Register: time.Unix(1623283200, 0),
+		Birthday: &birthday,
+		Profile:  &codecProfile{City: "SG"},
+		Tags:     []string{"a", "b"},
+		Secret:   "x",
+	}}
+	dataList, err := EncodeData(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := dataList[0]
+	if _, exist := data["age"]; exist {
+		t.Errorf("zero age should be omitted")
+	}
+	if _, exist := data["Secret"]; exist {
+		t.Errorf("ignored field should not be encoded")
+	}
+	if data["register_time"] != int64(1623283200) || data["birthday"] != "2000-01-02" ||
+		data["profile"] != `{"city":"SG"}` {
+		t.Errorf("unexpected encoded data: %v", data)
+	}
+	// the data in DataError is the json of written data
+	bytes, _ := json.Marshal(data)
+	decoded := &codecUser{}
+	if err := DecodeData(string(bytes), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.UserId != "u1" || !decoded.Register.Equal(users[0].Register) || !decoded.Birthday.Equal(birthday) ||
+		decoded.Profile.City != "SG" || len(decoded.Tags) != 2 {
+		t.Errorf("unexpected decoded user: %+v", decoded)
+	}
+}
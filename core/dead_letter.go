@@ -0,0 +1,112 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DeadLetter is an item that failed permanently, or still failed
+// after all the resubmissions
+type DeadLetter struct {
+	// The topic of item, such as "user_event"
+	Topic string
+	// The original item, proto message or json data
+	Item interface{}
+	// The error message of the last attempt
+	Message string
+	// The request id of the last attempt
+	RequestId string
+	// When the item was given up
+	Timestamp time.Time
+}
+
+// DeadLetterSink keeps the dead letters for later inspection or manual resending
+type DeadLetterSink interface {
+	Put(letters []*DeadLetter) error
+}
+
+var ErrDeadLetterSinkClosed = errors.New("dead letter sink is closed")
+
+// NewJsonlDeadLetterSink appends dead letters into the file at path, one json per line,
+// such as {"topic":"user","item":{"user_id":"1"},"message":"...","request_id":"...",
+// "timestamp":"2021-06-10T10:00:00Z"}
+func NewJsonlDeadLetterSink(path string) (*JsonlDeadLetterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JsonlDeadLetterSink{file: file}, nil
+}
+
+type JsonlDeadLetterSink struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+type jsonlDeadLetter struct {
+	Topic     string          `json:"topic"`
+	Item      json.RawMessage `json:"item"`
+	Message   string          `json:"message"`
+	RequestId string          `json:"request_id"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+func (receiver *JsonlDeadLetterSink) Put(letters []*DeadLetter) error {
+	var content []byte
+	for _, letter := range letters {
+		item, err := encodeDeadLetterItem(letter.Item)
+		if err != nil {
+			return err
+		}
+		line, err := json.Marshal(&jsonlDeadLetter{
+			Topic:     letter.Topic,
+			Item:      item,
+			Message:   letter.Message,
+			RequestId: letter.RequestId,
+			Timestamp: letter.Timestamp,
+		})
+		if err != nil {
+			return err
+		}
+		content = append(append(content, line...), '\n')
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.file == nil {
+		return ErrDeadLetterSinkClosed
+	}
+	// written at once, so that the lines of concurrent puts don't interleave
+	_, err := receiver.file.Write(content)
+	return err
+}
+
+func (receiver *JsonlDeadLetterSink) Close() error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.file == nil {
+		return nil
+	}
+	err := receiver.file.Close()
+	receiver.file = nil
+	return err
+}
+
+// encodeDeadLetterItem encodes message by proto field names,
+// and keeps the json string data as it is
+func encodeDeadLetterItem(item interface{}) (json.RawMessage, error) {
+	switch value := item.(type) {
+	case proto.Message:
+		return protojson.MarshalOptions{UseProtoNames: true}.Marshal(value)
+	case string:
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value), nil
+		}
+	}
+	return json.Marshal(item)
+}
@@ -0,0 +1,330 @@
+package core
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+)
+
+const (
+	defaultDedupWindow  = 10 * time.Minute
+	defaultDedupMaxKeys = 100000
+)
+
+// DefaultDataKeyFields identifies a user event in the data of saas, general and byteair,
+// the absent fields are taken as empty, so both product_id and item_id are listed
+var DefaultDataKeyFields = []string{"user_id", "event_type", "product_id", "item_id", "event_timestamp"}
+
+type DedupConfig struct {
+	// The event seen again within Window since it was sent is dropped, default 10min
+	Window time.Duration
+
+	// Max count of keys remembered, the oldest keys are forgotten
+	// when exceeded, which bounds the memory, default 100000
+	MaxKeys int
+}
+
+// DataKeyFunc returns the identity of data for deduplication
+type DataKeyFunc func(data map[string]interface{}) string
+
+// DataKeyFields identifies data by the values of fields
+func DataKeyFields(fields ...string) DataKeyFunc {
+	return func(data map[string]interface{}) string {
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			if value, exist := data[field]; exist && value != nil {
+				values[i] = dedupKeyValue(value)
+			}
+		}
+		return JoinDedupKey(values...)
+	}
+}
+
+// dedupKeyValue formats the integral numbers the same way regardless of
+// their types, as the data decoded from json has float64 or json.Number
+func dedupKeyValue(value interface{}) string {
+	switch number := value.(type) {
+	case json.Number:
+		if integer, err := number.Int64(); err == nil {
+			return strconv.FormatInt(integer, 10)
+		}
+		if float, err := number.Float64(); err == nil {
+			return dedupKeyValue(float)
+		}
+		return number.String()
+	case float32:
+		return dedupKeyValue(float64(number))
+	case float64:
+		if number == math.Trunc(number) && math.Abs(number) < 1e18 {
+			return strconv.FormatInt(int64(number), 10)
+		}
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// JoinDedupKey joins the identity fields into key, the separator
+// is not likely to appear in values
+func JoinDedupKey(values ...string) string {
+	return strings.Join(values, "\x00")
+}
+
+// NewDeduplicator
+//
+// Creates a deduplicator remembering the keys sent in a sliding window.
+// Keys are stored as 128-bit hashes, so the memory doesn't depend on key length.
+func NewDeduplicator(config *DedupConfig) *Deduplicator {
+	deduplicator := &Deduplicator{
+		window:  defaultDedupWindow,
+		maxKeys: defaultDedupMaxKeys,
+		entries: list.New(),
+		index:   make(map[dedupHash]*list.Element),
+		now:     time.Now,
+	}
+	if config != nil && config.Window > 0 {
+		deduplicator.window = config.Window
+	}
+	if config != nil && config.MaxKeys > 0 {
+		deduplicator.maxKeys = config.MaxKeys
+	}
+	return deduplicator
+}
+
+type dedupHash [16]byte
+
+type dedupEntry struct {
+	hash   dedupHash
+	seenAt time.Time
+	// count of the writes sending the key, the key is only
+	// remembered once confirmed by a write succeeded
+	pending   int
+	confirmed bool
+	// closed when pending drops to 0, waited by the duplicates
+	done chan struct{}
+}
+
+// Deduplicator is safe for concurrent use
+type Deduplicator struct {
+	window  time.Duration
+	maxKeys int
+	dropped int64
+
+	lock sync.Mutex
+	// entries in the order of seen time, the front is the oldest
+	entries *list.List
+	index   map[dedupHash]*list.Element
+	now     func() time.Time
+}
+
+// Filter returns the indexes of keys not seen within window, and remembers them
+// at once. The duplicated keys in the same batch are dropped except the first one.
+// The keys of items failed to send should be passed to Forget, so that their
+// retries will not be dropped. Write remembers keys only after they're sent.
+func (receiver *Deduplicator) Filter(keys []string) []int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	now := receiver.now()
+	receiver.expire(now)
+	kept := make([]int, 0, len(keys))
+	for i, key := range keys {
+		hash := hashDedupKey(key)
+		if element, exist := receiver.index[hash]; exist {
+			entry := element.Value.(*dedupEntry)
+			if entry.confirmed {
+				continue
+			}
+			// the key being sent by Write is kept
+			entry.confirmed = true
+			kept = append(kept, i)
+			continue
+		}
+		receiver.push(&dedupEntry{hash: hash, seenAt: now, confirmed: true})
+		kept = append(kept, i)
+	}
+	receiver.evict()
+	atomic.AddInt64(&receiver.dropped, int64(len(keys)-len(kept)))
+	return kept
+}
+
+// Forget removes keys, so that they could be sent again
+func (receiver *Deduplicator) Forget(keys ...string) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for _, key := range keys {
+		if element, exist := receiver.index[hashDedupKey(key)]; exist {
+			receiver.remove(element)
+		}
+	}
+}
+
+// DedupWriteFunc writes the items at the kept indexes, and returns
+// the response status with the keys of the items rejected in response
+type DedupWriteFunc func(kept []int) (status *protocol.Status, failedKeys []string, err error)
+
+// Write filters the items by keys, and writes the kept ones, write is not called
+// and false is returned if all items are duplicated.
+// The kept keys are pending until write returns, and only the keys of items
+// accepted are remembered, the others could be retried. A duplicate of a
+// pending key waits for the write sending it, and is kept if that write fails.
+func (receiver *Deduplicator) Write(keys []string, write DedupWriteFunc) (bool, error) {
+	receiver.waitPending(keys)
+	kept, entries := receiver.reserve(keys)
+	if len(kept) == 0 && len(keys) > 0 {
+		return false, nil
+	}
+	status, failedKeys, err := write(kept)
+	if err != nil || ChunkFailureMessage(status, len(failedKeys), nil) != "" {
+		receiver.settle(entries, nil)
+		return true, err
+	}
+	failed := make(map[dedupHash]bool, len(failedKeys))
+	for _, key := range failedKeys {
+		failed[hashDedupKey(key)] = true
+	}
+	receiver.settle(entries, failed)
+	return true, nil
+}
+
+// waitPending waits for the writes sending any of keys, no key
+// is reserved meanwhile, so the writes never wait for each other
+func (receiver *Deduplicator) waitPending(keys []string) {
+	receiver.lock.Lock()
+	var waits []chan struct{}
+	for _, key := range keys {
+		if element, exist := receiver.index[hashDedupKey(key)]; exist {
+			if entry := element.Value.(*dedupEntry); entry.pending > 0 {
+				waits = append(waits, entry.done)
+			}
+		}
+	}
+	receiver.lock.Unlock()
+	for _, done := range waits {
+		<-done
+	}
+}
+
+// reserve returns the indexes of keys not remembered, and marks them pending
+func (receiver *Deduplicator) reserve(keys []string) ([]int, []*dedupEntry) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	now := receiver.now()
+	receiver.expire(now)
+	kept := make([]int, 0, len(keys))
+	entries := make([]*dedupEntry, 0, len(keys))
+	reserved := make(map[dedupHash]bool, len(keys))
+	for i, key := range keys {
+		hash := hashDedupKey(key)
+		if reserved[hash] {
+			continue
+		}
+		var entry *dedupEntry
+		if element, exist := receiver.index[hash]; exist {
+			entry = element.Value.(*dedupEntry)
+			if entry.confirmed {
+				continue
+			}
+			// reserved by another write after waited, both are sent
+			if entry.pending == 0 {
+				entry.done = make(chan struct{})
+			}
+		} else {
+			entry = &dedupEntry{hash: hash, seenAt: now, done: make(chan struct{})}
+			receiver.push(entry)
+		}
+		entry.pending++
+		reserved[hash] = true
+		kept = append(kept, i)
+		entries = append(entries, entry)
+	}
+	receiver.evict()
+	atomic.AddInt64(&receiver.dropped, int64(len(keys)-len(kept)))
+	return kept, entries
+}
+
+// settle confirms the reserved entries not failed, nil failed means all
+// failed, the entries not confirmed by any write are removed
+func (receiver *Deduplicator) settle(entries []*dedupEntry, failed map[dedupHash]bool) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for _, entry := range entries {
+		if failed != nil && !failed[entry.hash] {
+			entry.confirmed = true
+		}
+		if entry.pending == 0 {
+			// removed by Forget or eviction meanwhile
+			continue
+		}
+		entry.pending--
+		if entry.pending > 0 {
+			continue
+		}
+		close(entry.done)
+		if !entry.confirmed {
+			if element, exist := receiver.index[entry.hash]; exist && element.Value == entry {
+				receiver.entries.Remove(element)
+				delete(receiver.index, entry.hash)
+			}
+		}
+	}
+}
+
+// Dropped returns the count of duplicates dropped
+func (receiver *Deduplicator) Dropped() int64 {
+	return atomic.LoadInt64(&receiver.dropped)
+}
+
+// Len returns the count of keys remembered
+func (receiver *Deduplicator) Len() int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.entries.Len()
+}
+
+func (receiver *Deduplicator) expire(now time.Time) {
+	for front := receiver.entries.Front(); front != nil; front = receiver.entries.Front() {
+		if now.Sub(front.Value.(*dedupEntry).seenAt) < receiver.window {
+			return
+		}
+		receiver.remove(front)
+	}
+}
+
+func (receiver *Deduplicator) push(entry *dedupEntry) {
+	receiver.index[entry.hash] = receiver.entries.PushBack(entry)
+}
+
+// evict removes the oldest keys when exceeding maxKeys
+func (receiver *Deduplicator) evict() {
+	for receiver.entries.Len() > receiver.maxKeys {
+		receiver.remove(receiver.entries.Front())
+	}
+}
+
+// remove releases the waiters of the pending entry
+func (receiver *Deduplicator) remove(element *list.Element) {
+	entry := element.Value.(*dedupEntry)
+	receiver.entries.Remove(element)
+	delete(receiver.index, entry.hash)
+	if entry.pending > 0 {
+		entry.pending = 0
+		close(entry.done)
+	}
+}
+
+func hashDedupKey(key string) dedupHash {
+	hasher := fnv.New128a()
+	_, _ = hasher.Write([]byte(key))
+	var hash dedupHash
+	copy(hash[:], hasher.Sum(nil))
+	return hash
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+)
+
+func TestDeduplicatorWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	deduplicator := NewDeduplicator(&DedupConfig{Window: time.Minute, MaxKeys: 3})
+	deduplicator.now = func() time.Time { return now }
+
+	kept := deduplicator.Filter([]string{"a", "b", "a"})
+	if !reflect.DeepEqual(kept, []int{0, 1}) {
+		t.Fatalf("unexpected kept %v", kept)
+	}
+	deduplicator.Forget("b")
+	now = now.Add(30 * time.Second)
+	kept = deduplicator.Filter([]string{"a", "b", "c"})
+	if !reflect.DeepEqual(kept, []int{1, 2}) {
+		t.Fatalf("unexpected kept %v", kept)
+	}
+	// "a" expires, the others are still in window
+	now = now.Add(40 * time.Second)
+	kept = deduplicator.Filter([]string{"a", "b", "d"})
+	if !reflect.DeepEqual(kept, []int{0, 2}) {
+		t.Fatalf("unexpected kept %v", kept)
+	}
+	if deduplicator.Len() != 3 {
+		t.Fatalf("keys should be bounded, got %d", deduplicator.Len())
+	}
+	if deduplicator.Dropped() != 3 {
+		t.Fatalf("unexpected dropped %d", deduplicator.Dropped())
+	}
+}
+
+func TestDataKeyFields(t *testing.T) {
+	key := DataKeyFields("user_id", "event_timestamp")
+	fromInt := key(map[string]interface{}{"user_id": "u1", "event_timestamp": int64(1600000000)})
+	fromFloat := key(map[string]interface{}{"user_id": "u1", "event_timestamp": float64(1600000000)})
+	fromNumber := key(map[string]interface{}{"user_id": "u1", "event_timestamp": json.Number("1600000000")})
+	if fromInt != fromFloat || fromInt != fromNumber {
+		t.Fatalf("keys should be same, got %q %q %q", fromInt, fromFloat, fromNumber)
+	}
+}
+
+func TestDeduplicatorConcurrentDuplicate(t *testing.T) {
+	deduplicator := NewDeduplicator(nil)
+	success := &protocol.Status{Code: StatusCodeSuccess}
+	firstSending := make(chan struct{})
+	firstFail := make(chan struct{})
+	firstDone := make(chan error)
+	go func() {
+		_, err := deduplicator.Write([]string{"e1"}, func(kept []int) (*protocol.Status, []string, error) {
+			close(firstSending)
+			<-firstFail
+			return nil, nil, errors.New("network error")
+		})
+		firstDone <- err
+	}()
+	<-firstSending
+
+	// the resend arrives while the first send is in flight
+	resendDone := make(chan bool)
+	go func() {
+		sent, err := deduplicator.Write([]string{"e1"}, func(kept []int) (*protocol.Status, []string, error) {
+			return success, nil, nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		resendDone <- sent
+	}()
+	select {
+	case <-resendDone:
+		t.Fatal("the resend should wait for the pending send")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(firstFail)
+	if err := <-firstDone; err == nil {
+		t.Fatal("expect the error of the first send")
+	}
+	if sent := <-resendDone; !sent || deduplicator.Dropped() != 0 {
+		t.Fatalf("expect the resend sent after the first failed, sent:%v dropped:%d", sent, deduplicator.Dropped())
+	}
+	sent, _ := deduplicator.Write([]string{"e1"}, func(kept []int) (*protocol.Status, []string, error) {
+		t.Error("the confirmed key should be dropped")
+		return success, nil, nil
+	})
+	if sent || deduplicator.Dropped() != 1 {
+		t.Fatalf("expect dropped after confirmed, sent:%v dropped:%d", sent, deduplicator.Dropped())
+	}
+}
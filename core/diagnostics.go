@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+)
+
+// Diagnostics is the snapshot of client state, which is rendered as JSON by DiagnosticsHandler
+type Diagnostics struct {
+	Tenant   string `json:"tenant"`
+	Region   string `json:"region"`
+	Schema   string `json:"schema"`
+	Closed   bool   `json:"closed"`
+	InFlight int    `json:"in_flight"`
+
+	// The host which requests are sent to
+	CurrentHost string `json:"current_host"`
+	// The hosts whose ping failure rate is under threshold, sorted by failure rate
+	AvailableHosts []string `json:"available_hosts"`
+	// Nil if host is never switched
+	LastSwitchTime *time.Time `json:"last_switch_time"`
+	// The hosts and their health, which are shared with other tenants if SharedTransport is true
+	Hosts           []*HostDiagnostics `json:"hosts"`
+	SharedTransport bool               `json:"shared_transport"`
+
+	// The recent errors of calls, the oldest first
+	RecentErrors []*CallError `json:"recent_errors"`
+}
+
+type HostDiagnostics struct {
+	Host string `json:"host"`
+	// Failure rate in the window of the last 60 pings,
+	// hosts are not pinged if there's only one
+	FailureRate float64 `json:"failure_rate"`
+	// Cost of the last ping in milliseconds
+	LatencyMillis float64 `json:"latency_ms"`
+}
+
+type CallError struct {
+	Time    time.Time `json:"time"`
+	Url     string    `json:"url"`
+	Message string    `json:"message"`
+}
+
+// DiagnosticsSource is implemented by the clients of all products
+type DiagnosticsSource interface {
+	Diagnostics() *Diagnostics
+}
+
+// NewDiagnostics takes the snapshot of the client made of caller and availabler,
+// which is called by the Diagnostics of product clients
+func NewDiagnostics(caller *HttpCaller, availabler *HostAvailabler) *Diagnostics {
+	context := caller.context
+	diagnostics := &Diagnostics{
+		Tenant:          context.tenant,
+		Region:          context.region.String(),
+		Schema:          context.schema,
+		SharedTransport: context.transport != nil,
+	}
+	caller.lock.Lock()
+	diagnostics.Closed = caller.closed
+	diagnostics.InFlight = caller.inFlightCount
+	diagnostics.RecentErrors = append([]*CallError(nil), caller.recentErrors...)
+	caller.lock.Unlock()
+	if availabler.transport != nil {
+		availabler = availabler.transport.availabler
+	}
+	availabler.diagnose(diagnostics)
+	return diagnostics
+}
+
+func (receiver *HostAvailabler) diagnose(diagnostics *Diagnostics) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	hosts := receiver.context.hosts
+	diagnostics.CurrentHost = receiver.currentHost
+	if diagnostics.CurrentHost == "" {
+		diagnostics.CurrentHost = hosts[0]
+	}
+	diagnostics.AvailableHosts = append([]string(nil), receiver.availableHosts...)
+	if len(diagnostics.AvailableHosts) == 0 && receiver.hostWindowMap == nil {
+		// the only host is not pinged
+		diagnostics.AvailableHosts = append(diagnostics.AvailableHosts, hosts...)
+	}
+	if !receiver.lastSwitchTime.IsZero() {
+		lastSwitchTime := receiver.lastSwitchTime
+		diagnostics.LastSwitchTime = &lastSwitchTime
+	}
+	for _, host := range hosts {
+		hostDiagnostics := &HostDiagnostics{Host: host}
+		if window, exist := receiver.hostWindowMap[host]; exist {
+			hostDiagnostics.FailureRate = window.failureRate()
+			hostDiagnostics.LatencyMillis = float64(receiver.hostLatencyMap[host]) / float64(time.Millisecond)
+		}
+		diagnostics.Hosts = append(diagnostics.Hosts, hostDiagnostics)
+	}
+}
+
+// DiagnosticsHandler renders the diagnostics of source as JSON for GET requests,
+// which could be mounted on the debug endpoint of service
+func DiagnosticsHandler(source DiagnosticsSource) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.Header().Set("Allow", http.MethodGet)
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := json.MarshalIndent(source.Diagnostics(), "", "  ")
+		if err != nil {
+			logs.Error("marshal diagnostics fail, err:%s", err.Error())
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write(body)
+	})
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDiagnosticsSource struct {
+	caller     *HttpCaller
+	availabler *HostAvailabler
+}
+
+func (receiver *fakeDiagnosticsSource) Diagnostics() *Diagnostics {
+	return NewDiagnostics(receiver.caller, receiver.availabler)
+}
+
+func TestDiagnosticsHandler(t *testing.T) {
+	clientContext, err := NewContext(&ContextParam{Tenant: "demo", TenantId: "1", Token: "token",
+		UseAirAuth: true, Region: RegionAirSg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := NewHttpCaller(clientContext)
+	source := &fakeDiagnosticsSource{caller: caller, availabler: NewHostAvailabler(&fakeURLCenter{}, clientContext)}
+	for i := 0; i < maxRecentErrors+2; i++ {
+		caller.recordError("https://host/predict", errors.New("timeout"))
+	}
+	_ = caller.beginCall()
+
+	recorder := httptest.NewRecorder()
+	DiagnosticsHandler(source).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/rec", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %v", recorder.Code, recorder.Header())
+	}
+	diagnostics := &Diagnostics{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	if diagnostics.Tenant != "demo" || diagnostics.Region != "air_sg" || diagnostics.Schema != "https" ||
+		diagnostics.CurrentHost != "byteair-api-sg1.byteintlapi.com" || diagnostics.InFlight != 1 ||
+		len(diagnostics.Hosts) != 1 || len(diagnostics.AvailableHosts) != 1 ||
+		len(diagnostics.RecentErrors) != maxRecentErrors || diagnostics.LastSwitchTime != nil {
+		t.Fatalf("unexpected diagnostics %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	DiagnosticsHandler(source).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/rec", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected code %d", recorder.Code)
+	}
+}
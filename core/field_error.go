@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError reports an invalid field of the item at Index of request,
+// Path locates the field in item, such as "price.current_price" or "tags[1]".
+// Index is -1 if the field belongs to request itself, such as "date_config.date"
+type FieldError struct {
+	Index   int
+	Path    string
+	Message string
+}
+
+func (receiver *FieldError) Error() string {
+	if receiver.Index < 0 {
+		return fmt.Sprintf("%s: %s", receiver.Path, receiver.Message)
+	}
+	return fmt.Sprintf("[%d].%s: %s", receiver.Index, receiver.Path, receiver.Message)
+}
+
+// FieldErrors is returned when any field is invalid, the request is not sent
+type FieldErrors []*FieldError
+
+func (receiver FieldErrors) Error() string {
+	const maxShown = 10
+	messages := make([]string, 0, maxShown+1)
+	for i, fieldError := range receiver {
+		if i == maxShown {
+			messages = append(messages, fmt.Sprintf("and %d more", len(receiver)-maxShown))
+			break
+		}
+		messages = append(messages, fieldError.Error())
+	}
+	return "invalid fields: " + strings.Join(messages, "; ")
+}
+
+// Indexes returns the indexes of the invalid items in order,
+// the errors of request fields are not included
+func (receiver FieldErrors) Indexes() []int {
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, fieldError := range receiver {
+		if fieldError.Index >= 0 && !seen[fieldError.Index] {
+			seen[fieldError.Index] = true
+			indexes = append(indexes, fieldError.Index)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SetFieldPath parses value by the kind of the field at path, and sets it into message.
+// Path is made of field names separated by '.', such as "price.current_price",
+// repeated message is indexed like "categories[0].category_depth",
+// and the key of map follows the map field, such as "extra.color".
+// The value of repeated scalar field is split by separator, it's
+// appended as one element if separator is empty.
+// The intermediate messages are created if they're not set.
+func SetFieldPath(message proto.Message, path string, value string, separator string) error {
+	return setFieldPath(message.ProtoReflect(), path, path, value, separator)
+}
+
+func setFieldPath(message protoreflect.Message, path string, remain string,
+	value string, separator string) error {
+	segment, rest := remain, ""
+	if idx := strings.IndexByte(remain, '.'); idx >= 0 {
+		segment, rest = remain[:idx], remain[idx+1:]
+	}
+	name, index, err := parsePathSegment(segment)
+	if err != nil {
+		return fieldPathError(path, err)
+	}
+	field := findField(message.Descriptor(), name)
+	if field == nil {
+		return fieldPathError(path, errors.New("unknown field "+name))
+	}
+	switch {
+	case field.IsMap():
+		if rest == "" || index >= 0 {
+			return fieldPathError(path, errors.New("map field requires a key, such as "+name+".key"))
+		}
+		key, err := parseMapKey(field.MapKey(), rest)
+		if err != nil {
+			return fieldPathError(path, err)
+		}
+		mapValue, err := parseScalar(field.MapValue(), value)
+		if err != nil {
+			return fieldPathError(path, err)
+		}
+		message.Mutable(field).Map().Set(key, mapValue)
+		return nil
+	case field.IsList() && field.Kind() == protoreflect.MessageKind:
+		if index < 0 {
+			return fieldPathError(path, errors.New("repeated message requires an index, such as "+name+"[0]"))
+		}
+		list := message.Mutable(field).List()
+		for list.Len() <= index {
+			list.Append(list.NewElement())
+		}
+		if rest == "" {
+			return fieldPathError(path, errors.New("message field can't be set by value"))
+		}
+		return setFieldPath(list.Get(index).Message(), path, rest, value, separator)
+	case field.IsList():
+		if rest != "" || index >= 0 {
+			return fieldPathError(path, errors.New("scalar field has no sub field"))
+		}
+		list := message.Mutable(field).List()
+		elements := []string{value}
+		if separator != "" {
+			elements = strings.Split(value, separator)
+		}
+		for _, element := range elements {
+			parsed, err := parseScalar(field, strings.TrimSpace(element))
+			if err != nil {
+				return fieldPathError(path, err)
+			}
+			list.Append(parsed)
+		}
+		return nil
+	case field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind:
+		if rest == "" || index >= 0 {
+			return fieldPathError(path, errors.New("message field can't be set by value"))
+		}
+		return setFieldPath(message.Mutable(field).Message(), path, rest, value, separator)
+	default:
+		if rest != "" || index >= 0 {
+			return fieldPathError(path, errors.New("scalar field has no sub field"))
+		}
+		parsed, err := parseScalar(field, value)
+		if err != nil {
+			return fieldPathError(path, err)
+		}
+		message.Set(field, parsed)
+		return nil
+	}
+}
+
+// parsePathSegment splits "name[index]", index is -1 if absent
+func parsePathSegment(segment string) (string, int, error) {
+	start := strings.IndexByte(segment, '[')
+	if start < 0 {
+		return segment, -1, nil
+	}
+	if !strings.HasSuffix(segment, "]") {
+		return "", 0, errors.New("invalid segment " + segment)
+	}
+	index, err := strconv.Atoi(segment[start+1 : len(segment)-1])
+	if err != nil || index < 0 {
+		return "", 0, errors.New("invalid index of segment " + segment)
+	}
+	return segment[:start], index, nil
+}
+
+// findField accepts both the proto name and the json name of field
+func findField(descriptor protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := descriptor.Fields()
+	if field := fields.ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+	return fields.ByJSONName(name)
+}
+
+func parseMapKey(field protoreflect.FieldDescriptor, key string) (protoreflect.MapKey, error) {
+	value, err := parseScalar(field, key)
+	if err != nil {
+		return protoreflect.MapKey{}, err
+	}
+	return value.MapKey(), nil
+}
+
+func parseScalar(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(value)), nil
+	case protoreflect.BoolKind:
+		parsed, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(parsed), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(parsed)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(parsed), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(parsed)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(parsed), err
+	case protoreflect.FloatKind:
+		parsed, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(parsed)), err
+	case protoreflect.DoubleKind:
+		parsed, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(parsed), err
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByName(protoreflect.Name(value)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(parsed)), err
+	default:
+		return protoreflect.Value{}, errors.New(fmt.Sprintf("unsupported kind %s", field.Kind()))
+	}
+}
+
+func fieldPathError(path string, err error) error {
+	return errors.New(fmt.Sprintf("field %s: %s", path, err.Error()))
+}
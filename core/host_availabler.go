@@ -1,9 +1,11 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/byteplus-sdk/sdk-go/core/logs"
@@ -11,20 +13,32 @@ import (
 )
 
 const (
-	pingUrlFormat        = "{}://%s/predict/api/ping"
 	pingInterval         = time.Second
 	windowSize           = 60
 	failureRateThreshold = 0.1
 	pingTimeout          = 200 * time.Millisecond
 )
 
+// NewHostAvailabler refreshes urlCenter with the healthy host, if context shares
+// SharedTransport, urlCenter is attached to the availabler of transport instead of
+// pinging hosts by itself, and Shutdown only detaches urlCenter.
 func NewHostAvailabler(urlCenter URLCenter, context *Context) *HostAvailabler {
+	if context.transport != nil {
+		return context.transport.attach(urlCenter)
+	}
+	return newHostAvailabler(context, urlCenter)
+}
+
+func newHostAvailabler(context *Context, urlCenters ...URLCenter) *HostAvailabler {
 	availabler := &HostAvailabler{
-		context:   context,
-		urlCenter: urlCenter,
+		context:    context,
+		urlCenters: urlCenters,
+		abort:      make(chan struct{}),
 	}
-	availabler.pingUrlFormat = strings.ReplaceAll(pingUrlFormat, "{}", context.Schema())
-	if len(context.hosts) <= 1 {
+	// host is filled by "%s"
+	availabler.pingUrlFormat = context.Schema() + "://%s" + strings.ReplaceAll(context.pingPath, "%", "%%")
+	// Replay mode works without network, host is no need to ping
+	if len(context.hosts) <= 1 || context.recordMode == RecordModeReplay {
 		return availabler
 	}
 	availabler.currentHost = context.hosts[0]
@@ -36,47 +50,118 @@ func NewHostAvailabler(urlCenter URLCenter, context *Context) *HostAvailabler {
 		hostHttpCliMap[host] = &fasthttp.HostClient{Addr: host}
 	}
 	availabler.hostWindowMap = hostWindowMap
+	availabler.hostLatencyMap = make(map[string]time.Duration, len(context.hosts))
 	availabler.hostHttpCliMap = hostHttpCliMap
+	availabler.done = make(chan struct{})
 	AsyncExecute(availabler.scheduleFunc())
 	return availabler
 }
 
 type HostAvailabler struct {
-	abort          bool
-	context        *Context
-	urlCenter      URLCenter
+	abortOnce sync.Once
+	abort     chan struct{}
+	// closed when the ping goroutine exits, nil if it's not started
+	done    chan struct{}
+	context *Context
+	// guards urlCenters and the host states read by Diagnostics
+	lock           sync.Mutex
+	urlCenters     []URLCenter
 	currentHost    string
+	lastSwitchTime time.Time
 	availableHosts []string
 	hostWindowMap  map[string]*window
+	// cost of the last ping
+	hostLatencyMap map[string]time.Duration
 	hostHttpCliMap map[string]*fasthttp.HostClient
 	pingUrlFormat  string
+	// Not nil if it only attaches urlCenter to the availabler of transport
+	transport *SharedTransport
 }
 
+// Shutdown stops the ping goroutine without waiting for it, see Close
 func (receiver *HostAvailabler) Shutdown() {
-	receiver.abort = true
+	if receiver.transport != nil {
+		receiver.transport.detach(receiver)
+		return
+	}
+	receiver.abortOnce.Do(func() {
+		close(receiver.abort)
+	})
+}
+
+// Close stops the ping goroutine, and waits for its exit until ctx is done,
+// then closes the idle connections of ping
+func (receiver *HostAvailabler) Close(ctx context.Context) error {
+	receiver.Shutdown()
+	if receiver.done == nil {
+		return nil
+	}
+	select {
+	case <-receiver.done:
+	case <-ctx.Done():
+		return ErrCloseTimeout
+	}
+	for _, httpCli := range receiver.hostHttpCliMap {
+		httpCli.CloseIdleConnections()
+	}
+	return nil
+}
+
+// addURLCenter refreshes urlCenter with the current host, which may
+// have been switched before urlCenter is added
+func (receiver *HostAvailabler) addURLCenter(urlCenter URLCenter) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	receiver.urlCenters = append(receiver.urlCenters, urlCenter)
+	if receiver.currentHost != "" {
+		urlCenter.Refresh(receiver.currentHost)
+	}
+}
+
+func (receiver *HostAvailabler) removeURLCenter(urlCenter URLCenter) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for i, center := range receiver.urlCenters {
+		if center == urlCenter {
+			receiver.urlCenters = append(receiver.urlCenters[:i], receiver.urlCenters[i+1:]...)
+			return
+		}
+	}
 }
 
 func (receiver *HostAvailabler) scheduleFunc() func() {
 	return func() {
 		ticker := time.NewTicker(pingInterval)
-		for true {
-			if receiver.abort {
-				ticker.Stop()
-				return
-			}
+		defer func() {
+			ticker.Stop()
+			close(receiver.done)
+		}()
+		for {
 			receiver.checkHost()
 			receiver.switchHost()
-			<-ticker.C
+			select {
+			case <-receiver.abort:
+				return
+			case <-ticker.C:
+			}
 		}
 	}
 }
 
 func (receiver *HostAvailabler) checkHost() {
+	for _, host := range receiver.context.hosts {
+		start := time.Now()
+		success := receiver.ping(host)
+		receiver.lock.Lock()
+		receiver.hostWindowMap[host].put(success)
+		receiver.hostLatencyMap[host] = time.Now().Sub(start)
+		receiver.lock.Unlock()
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
 	availableHosts := make([]string, 0, len(receiver.context.hosts))
 	for _, host := range receiver.context.hosts {
-		winObj := receiver.hostWindowMap[host]
-		winObj.put(receiver.ping(host))
-		if winObj.failureRate() < failureRateThreshold {
+		if receiver.hostWindowMap[host].failureRate() < failureRateThreshold {
 			availableHosts = append(availableHosts, host)
 		}
 	}
@@ -121,6 +206,8 @@ func (receiver *HostAvailabler) ping(host string) bool {
 }
 
 func (receiver *HostAvailabler) switchHost() {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
 	var newHost string
 	if len(receiver.availableHosts) == 0 {
 		newHost = receiver.context.hosts[0]
@@ -131,9 +218,12 @@ func (receiver *HostAvailabler) switchHost() {
 		logs.Warn("switch host to '%s', origin is '%s'",
 			newHost, receiver.currentHost)
 		receiver.currentHost = newHost
-		receiver.urlCenter.Refresh(newHost)
+		receiver.lastSwitchTime = time.Now()
+		for _, urlCenter := range receiver.urlCenters {
+			urlCenter.Refresh(newHost)
+		}
 		if receiver.context.hostHeader != "" {
-			receiver.context.hostHTTPCli = &fasthttp.HostClient{Addr: newHost}
+			receiver.context.httpClients.hostHTTPCli = &fasthttp.HostClient{Addr: newHost}
 		}
 	}
 }
@@ -2,12 +2,14 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/byteplus-sdk/sdk-go/core/logs"
@@ -17,18 +19,99 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-const netErrMark = "[netErr]"
+const (
+	netErrMark = "[netErr]"
+
+	maxRecentErrors = 10
+)
 
 func NewHttpCaller(context *Context) *HttpCaller {
-	return &HttpCaller{context: context}
+	return &HttpCaller{
+		context:  context,
+		recorder: newRecorder(context.recordMode, context.recordDir),
+	}
 }
 
 type HttpCaller struct {
 	context *Context
+	// persist or replay calls, nil if record mode is none
+	recorder *recorder
+	// guards closed, inFlightCount and recentErrors
+	lock          sync.Mutex
+	closed        bool
+	inFlightCount int
+	inFlight      sync.WaitGroup
+	// the oldest is dropped once exceeding maxRecentErrors
+	recentErrors []*CallError
+}
+
+// InFlight returns the count of calls not finished
+func (c *HttpCaller) InFlight() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.inFlightCount
+}
+
+// Close rejects the new calls with ErrClientClosed, and waits for the calls
+// in flight until ctx is done, then closes the idle connections unless
+// they're shared by SharedTransport. It could be called more than once.
+func (c *HttpCaller) Close(ctx context.Context) error {
+	c.lock.Lock()
+	c.closed = true
+	c.lock.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		logs.Warn("close http caller timeout, %d calls in flight", c.InFlight())
+		return ErrCloseTimeout
+	}
+	if c.context.transport != nil {
+		return nil
+	}
+	if c.context.httpClients.hostHTTPCli != nil {
+		c.context.httpClients.hostHTTPCli.CloseIdleConnections()
+	}
+	if c.context.httpClients.defaultHTTPCli != nil {
+		c.context.httpClients.defaultHTTPCli.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (c *HttpCaller) beginCall() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	c.inFlightCount++
+	c.inFlight.Add(1)
+	return nil
+}
+
+func (c *HttpCaller) endCall() {
+	c.lock.Lock()
+	c.inFlightCount--
+	c.lock.Unlock()
+	c.inFlight.Done()
+}
+
+// Options converts opts of call with the default options of client,
+// the fields set by opts override the defaults
+func (c *HttpCaller) Options(opts ...option.Option) *option.Options {
+	return option.Conv2Options(option.WithDefaults(c.context.defaultOptions, opts)...)
 }
 
 func (c *HttpCaller) DoJsonRequest(url string, request interface{},
 	response proto.Message, options *option.Options) error {
+	if err := c.beginCall(); err != nil {
+		return err
+	}
+	defer c.endCall()
 	reqBytes, err := c.jsonMarshal(request)
 	if err != nil {
 		logs.Error("json marshal request fail, err:%s url:%s", err.Error(), url)
@@ -36,7 +119,9 @@ func (c *HttpCaller) DoJsonRequest(url string, request interface{},
 	}
 	headers := c.buildHeaders(options, "application/json")
 	url = c.withOptionQueries(options, url)
-	rspBytes, err := c.doHttpRequest(url, headers, reqBytes, options.Timeout)
+	rspBytes, err := c.doRequest(url, headers, reqBytes, options.Timeout, func() (*recordRequest, error) {
+		return newJsonRecordRequest(url, headers, request)
+	})
 	if err != nil {
 		return err
 	}
@@ -59,6 +144,10 @@ func (c *HttpCaller) jsonMarshal(request interface{}) ([]byte, error) {
 
 func (c *HttpCaller) DoPbRequest(url string, request proto.Message,
 	response proto.Message, options *option.Options) error {
+	if err := c.beginCall(); err != nil {
+		return err
+	}
+	defer c.endCall()
 	reqBytes, err := c.marshal(request)
 	if err != nil {
 		logs.Error("marshal request fail, err:%s url:%s", err.Error(), url)
@@ -66,7 +155,9 @@ func (c *HttpCaller) DoPbRequest(url string, request proto.Message,
 	}
 	headers := c.buildHeaders(options, "application/x-protobuf")
 	url = c.withOptionQueries(options, url)
-	rspBytes, err := c.doHttpRequest(url, headers, reqBytes, options.Timeout)
+	rspBytes, err := c.doRequest(url, headers, reqBytes, options.Timeout, func() (*recordRequest, error) {
+		return newPbRecordRequest(url, headers, request)
+	})
 	if err != nil {
 		return err
 	}
@@ -188,8 +279,61 @@ func (c *HttpCaller) withOptionQueries(options *option.Options, url string) stri
 	return url
 }
 
+// The record request is only built when recorder is enabled,
+// avoid the extra marshal cost for normal calls
+func (c *HttpCaller) doRequest(url string, headers map[string]string, reqBytes []byte,
+	timeout time.Duration, buildRecordRequest func() (*recordRequest, error)) ([]byte, error) {
+	if c.recorder == nil {
+		rspBytes, err := c.doHttpRequest(url, headers, reqBytes, timeout)
+		c.recordError(url, err)
+		return rspBytes, err
+	}
+	recordReq, err := buildRecordRequest()
+	if err != nil {
+		logs.Error("build record request fail, err:%s url:%s", err.Error(), url)
+		return nil, err
+	}
+	rspBytes, err := c.recorder.do(recordReq, func() (int, []byte, error) {
+		return c.doHttpCall(url, headers, reqBytes, timeout)
+	})
+	c.recordError(url, err)
+	return rspBytes, err
+}
+
+// recordError keeps the recent errors of calls for Diagnostics
+func (c *HttpCaller) recordError(url string, err error) {
+	if err == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.recentErrors) >= maxRecentErrors {
+		c.recentErrors = c.recentErrors[1:]
+	}
+	c.recentErrors = append(c.recentErrors, &CallError{Time: time.Now(), Url: url, Message: err.Error()})
+}
+
 func (c *HttpCaller) doHttpRequest(url string, headers map[string]string,
 	reqBytes []byte, timeout time.Duration) ([]byte, error) {
+	statusCode, rspBytes, err := c.doHttpCall(url, headers, reqBytes, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return checkHttpStatus(statusCode, rspBytes)
+}
+
+// checkHttpStatus converts the response not 200 into net error, so that it could be retried
+func checkHttpStatus(statusCode int, rspBytes []byte) ([]byte, error) {
+	if statusCode != fasthttp.StatusOK {
+		return nil, errors.New(netErrMark + "http status not 200")
+	}
+	return rspBytes, nil
+}
+
+// doHttpCall returns the status code and the decompressed body of response,
+// the error is only returned if no response is received
+func (c *HttpCaller) doHttpCall(url string, headers map[string]string,
+	reqBytes []byte, timeout time.Duration) (int, []byte, error) {
 	request := c.acquireRequest(url, headers, reqBytes)
 	response := fasthttp.AcquireResponse()
 	defer func() {
@@ -206,15 +350,15 @@ func (c *HttpCaller) doHttpRequest(url string, headers map[string]string,
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "timeout") {
 			logs.Error("do http request timeout, msg:%s url:%s", err.Error(), url)
-			return nil, errors.New(netErrMark + " timeout")
+			return 0, nil, errors.New(netErrMark + " timeout")
 		}
 		logs.Error("do http request occur error, msg:%s url:%s", err.Error(), url)
-		return nil, err
+		return 0, nil, err
 	}
 	logs.Trace("http response headers:\n%s", string(response.Header.Header()))
-	if response.StatusCode() != fasthttp.StatusOK {
+	statusCode := response.StatusCode()
+	if statusCode != fasthttp.StatusOK {
 		c.logHttpResponse(url, response)
-		return nil, errors.New(netErrMark + "http status not 200")
 	}
 	rspEncoding := response.Header.Peek(fasthttp.HeaderContentEncoding)
 	if bytes.Contains(rspEncoding, []byte("gzip")) {
@@ -222,11 +366,12 @@ func (c *HttpCaller) doHttpRequest(url string, headers map[string]string,
 		if err != nil {
 			rspHeaders := string(response.Header.Header())
 			logs.Error("gzip decompress rsp err, url:%s header:\n%s", url, rspHeaders)
-			return nil, err
+			return 0, nil, err
 		}
-		return rspBytes, nil
+		return statusCode, rspBytes, nil
 	}
-	return response.Body(), nil
+	// the body is released with response
+	return statusCode, append([]byte(nil), response.Body()...), nil
 }
 
 func (c *HttpCaller) acquireRequest(url string,
@@ -248,14 +393,14 @@ func (c *HttpCaller) smartDoRequest(timeout time.Duration,
 	request *fasthttp.Request, response *fasthttp.Response) error {
 	var err error
 	if c.context.hostHeader != "" {
-		var httpCli = c.context.hostHTTPCli
+		var httpCli = c.context.httpClients.hostHTTPCli
 		if timeout > 0 {
 			err = httpCli.DoTimeout(request, response, timeout)
 		} else {
 			err = httpCli.Do(request, response)
 		}
 	} else {
-		var httpCli = c.context.defaultHTTPCli
+		var httpCli = c.context.httpClients.defaultHTTPCli
 		if timeout > 0 {
 			err = httpCli.DoTimeout(request, response, timeout)
 		} else {
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/byteplus-sdk/sdk-go/core/option"
 )
@@ -102,3 +104,25 @@ func TestHttpCaller_withOptionQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpCaller_Options(t *testing.T) {
+	defaultHeaders := map[string]string{"Env": "prod", "Team": "rec"}
+	caller := &HttpCaller{context: &Context{defaultOptions: []option.Option{
+		option.WithStage("pre"),
+		option.WithTimeout(time.Second),
+		option.WithHeaders(defaultHeaders),
+	}}}
+	callHeaders := map[string]string{"Team": "search"}
+	options := caller.Options(option.WithTimeout(time.Millisecond), option.WithHeaders(callHeaders),
+		option.WithQueries(map[string]string{"debug": "1"}))
+	if options.Stage != "pre" || options.Timeout != time.Millisecond {
+		t.Fatalf("unexpected options %+v", options)
+	}
+	if !reflect.DeepEqual(options.Headers, map[string]string{"Env": "prod", "Team": "search"}) {
+		t.Fatalf("headers should be merged, got %v", options.Headers)
+	}
+	options.Headers["Extra"] = "1"
+	if len(defaultHeaders) != 2 || len(callHeaders) != 1 || defaultHeaders["Team"] != "rec" {
+		t.Fatal("maps passed by caller should not be modified")
+	}
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type RecordMode int
+
+const (
+	// RecordModeNone sends requests to server without recording
+	RecordModeNone RecordMode = iota
+
+	// RecordModeRecord sends requests to server, and persists every call
+	// responded into the record directory, one file per call. The recordings
+	// of earlier runs are kept, the calls of later runs are appended after them
+	RecordModeRecord
+
+	// RecordModeReplay serves calls from the record directory without network,
+	// the call without matched recording will fail
+	RecordModeReplay
+)
+
+const (
+	recordFileSuffix = ".json"
+	recordHashLength = 16
+)
+
+var recordMethodCleaner = regexp.MustCompile(`[^0-9A-Za-z]+`)
+
+// The headers differ in each call, they're neither hashed nor persisted
+var recordIgnoredHeaders = map[string]bool{
+	"Request-Id": true,
+}
+
+// recording is the content of record file, the StatusCode
+// of recordings saved before it's added is 0, taken as 200
+type recording struct {
+	Method     string            `json:"method"`
+	Hash       string            `json:"hash"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Request    json.RawMessage   `json:"request"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Response   []byte            `json:"response"`
+	RecordTime string            `json:"record_time"`
+}
+
+type recordRequest struct {
+	url     string
+	headers map[string]string
+	// canonical bytes of request body, used to calculate the request hash
+	canonical []byte
+	// human readable request message persisted in the record file
+	message json.RawMessage
+}
+
+func newPbRecordRequest(url string, headers map[string]string,
+	request proto.Message) (*recordRequest, error) {
+	canonical, err := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	message, err := protojson.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return &recordRequest{url: url, headers: headers, canonical: canonical, message: message}, nil
+}
+
+func newJsonRecordRequest(url string, headers map[string]string,
+	request interface{}) (*recordRequest, error) {
+	// map keys are sorted by json marshal, so the result is canonical
+	message, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return &recordRequest{url: url, headers: headers, canonical: message, message: message}, nil
+}
+
+// method is generated by url path and query, the host and schema are excluded,
+// so that recordings could be replayed after the host switched
+func (receiver *recordRequest) method() string {
+	path := receiver.url
+	if idx := strings.Index(path, "://"); idx >= 0 {
+		path = path[idx+3:]
+	}
+	if idx := strings.Index(path, "/"); idx >= 0 {
+		path = path[idx:]
+	}
+	query := ""
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path, query = path[:idx], path[idx+1:]
+	}
+	// the order of option queries is random, sort them to keep method stable
+	queryParts := strings.Split(query, "&")
+	sort.Strings(queryParts)
+	method := path + "?" + strings.Join(queryParts, "&")
+	return strings.Trim(recordMethodCleaner.ReplaceAllString(method, "_"), "_")
+}
+
+// hash covers the headers, such as Content-Date, as
+// the same body may be handled differently by them
+func (receiver *recordRequest) hash() string {
+	shaHash := sha256.New()
+	shaHash.Write([]byte(receiver.method()))
+	headers := receiver.recordHeaders()
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		shaHash.Write([]byte(name + ":" + headers[name] + "\n"))
+	}
+	shaHash.Write(receiver.canonical)
+	return fmt.Sprintf("%x", shaHash.Sum(nil))[:recordHashLength]
+}
+
+func (receiver *recordRequest) recordHeaders() map[string]string {
+	headers := make(map[string]string, len(receiver.headers))
+	for name, value := range receiver.headers {
+		if !recordIgnoredHeaders[name] {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+func newRecorder(mode RecordMode, dir string) *recorder {
+	if mode == RecordModeNone {
+		return nil
+	}
+	return &recorder{
+		mode: mode,
+		dir:  dir,
+		seqs: make(map[string]int),
+	}
+}
+
+// recorder persists or replays calls of HttpCaller.
+// Identical requests, such as polling an operation, are distinguished
+// by their sequence, when replay runs out of the recorded sequence,
+// the last recording will be served repeatedly.
+// The responses not 200 are replayed as the same errors.
+type recorder struct {
+	mode RecordMode
+	dir  string
+	lock sync.Mutex
+	seqs map[string]int
+}
+
+// recordCall returns the status code and body of response,
+// the error is only returned if no response is received
+type recordCall func() (statusCode int, rspBytes []byte, err error)
+
+func (receiver *recorder) do(request *recordRequest, call recordCall) ([]byte, error) {
+	if receiver.mode == RecordModeReplay {
+		return receiver.replay(request)
+	}
+	statusCode, rspBytes, err := call()
+	if err != nil {
+		return nil, err
+	}
+	if err := receiver.record(request, statusCode, rspBytes); err != nil {
+		logs.Error("save recording fail, url:%s err:%s", request.url, err.Error())
+		return nil, err
+	}
+	return checkHttpStatus(statusCode, rspBytes)
+}
+
+// nextSeq starts after the recordings of earlier runs in record mode,
+// so that they're not overwritten
+func (receiver *recorder) nextSeq(key string) int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	seq, exist := receiver.seqs[key]
+	if !exist && receiver.mode == RecordModeRecord {
+		seq = receiver.recordedCount(key)
+	}
+	receiver.seqs[key] = seq + 1
+	return seq
+}
+
+// recordedCount returns the count of recordings of key in dir
+func (receiver *recorder) recordedCount(key string) int {
+	count := 0
+	for {
+		if _, err := os.Stat(receiver.filePath(key, count)); err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+func (receiver *recorder) filePath(key string, seq int) string {
+	return filepath.Join(receiver.dir, fmt.Sprintf("%s-%d%s", key, seq, recordFileSuffix))
+}
+
+func (receiver *recorder) record(request *recordRequest, statusCode int, rspBytes []byte) error {
+	method, hash := request.method(), request.hash()
+	key := method + "-" + hash
+	content, err := json.MarshalIndent(&recording{
+		Method:     method,
+		Hash:       hash,
+		URL:        request.url,
+		Headers:    request.recordHeaders(),
+		Request:    request.message,
+		StatusCode: statusCode,
+		Response:   rspBytes,
+		RecordTime: time.Now().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(receiver.dir, 0755); err != nil {
+		return err
+	}
+	path := receiver.filePath(key, receiver.nextSeq(key))
+	// write to temp file first, avoid leaving a broken recording
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	logs.Debug("save recording, url:%s path:%s", request.url, path)
+	return os.Rename(tmpPath, path)
+}
+
+func (receiver *recorder) replay(request *recordRequest) ([]byte, error) {
+	method, hash := request.method(), request.hash()
+	key := method + "-" + hash
+	for seq := receiver.nextSeq(key); seq >= 0; seq-- {
+		content, err := ioutil.ReadFile(receiver.filePath(key, seq))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec := &recording{}
+		if err := json.Unmarshal(content, rec); err != nil {
+			return nil, err
+		}
+		logs.Debug("replay recording, url:%s key:%s seq:%d", request.url, key, seq)
+		if rec.StatusCode == 0 {
+			return rec.Response, nil
+		}
+		return checkHttpStatus(rec.StatusCode, rec.Response)
+	}
+	logs.Error("no recording matched, url:%s method:%s hash:%s request:\n%s",
+		request.url, method, hash, string(request.message))
+	return nil, errors.New(fmt.Sprintf("[replay] no recording matched, method:%s hash:%s dir:%s",
+		method, hash, receiver.dir))
+}
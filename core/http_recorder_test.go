@@ -0,0 +1,108 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+)
+
+func TestRecorder_recordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "byteplus_recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	request := &protocol.GetOperationRequest{Name: "operation_1"}
+	recordReq, err := newPbRecordRequest("https://host1/data/api/demo/operation?method=get&stage=pre",
+		map[string]string{"Request-Id": "1"}, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := newRecorder(RecordModeRecord, dir)
+	for _, rsp := range []string{"running", "done"} {
+		_, err := rec.do(recordReq, func() (int, []byte, error) {
+			return 200, []byte(rsp), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// host and query order should not affect the matching
+	replayReq, err := newPbRecordRequest("http://host2/data/api/demo/operation?stage=pre&method=get",
+		map[string]string{"Request-Id": "2"}, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer := newRecorder(RecordModeReplay, dir)
+	for _, want := range []string{"running", "done", "done"} {
+		got, err := replayer.do(replayReq, func() (int, []byte, error) {
+			t.Fatal("replay mode should not call server")
+			return 0, nil, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("replay() = %v, want %v", string(got), want)
+		}
+	}
+
+	unmatchedReq, err := newPbRecordRequest("https://host1/data/api/demo/operation?method=get",
+		nil, &protocol.GetOperationRequest{Name: "operation_2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replayer.do(unmatchedReq, nil); err == nil {
+		t.Errorf("replay unmatched request should fail")
+	}
+}
+
+func TestRecorder_headersStatusAndLaterRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "byteplus_recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	url := "https://host1/data/api/demo/users?method=write"
+	request := &protocol.GetOperationRequest{Name: "users"}
+	newRequest := func(date string) *recordRequest {
+		recordReq, err := newPbRecordRequest(url, map[string]string{"Content-Date": date}, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return recordReq
+	}
+	if newRequest("2021-06-10").hash() == newRequest("2021-06-11").hash() {
+		t.Fatal("requests of different Content-Date should not share hash")
+	}
+
+	// every run records in a new recorder, like a new process
+	responses := []struct {
+		statusCode int
+		body       string
+	}{{500, "busy"}, {200, "ok"}}
+	for _, response := range responses {
+		rec := newRecorder(RecordModeRecord, dir)
+		_, err := rec.do(newRequest("2021-06-10"), func() (int, []byte, error) {
+			return response.statusCode, []byte(response.body), nil
+		})
+		if (err != nil) != (response.statusCode != 200) {
+			t.Fatalf("unexpected error of status %d: %v", response.statusCode, err)
+		}
+	}
+
+	replayer := newRecorder(RecordModeReplay, dir)
+	if _, err := replayer.do(newRequest("2021-06-10"), nil); err == nil || !IsNetError(err) {
+		t.Errorf("expect the recorded status 500 replayed as net error, got %v", err)
+	}
+	got, err := replayer.do(newRequest("2021-06-10"), nil)
+	if err != nil || string(got) != "ok" {
+		t.Errorf("expect the recording of the later run, got %s %v", got, err)
+	}
+	if _, err := replayer.do(newRequest("2021-06-11"), nil); err == nil {
+		t.Error("request of other Content-Date should not be matched")
+	}
+}
@@ -14,6 +14,29 @@ func Conv2Options(opts ...Option) *Options {
 
 type Option func(opts *Options)
 
+// WithDefaults returns the options applying defaults before opts,
+// so the fields set by opts override defaults, and the maps are merged
+func WithDefaults(defaults []Option, opts []Option) []Option {
+	if len(defaults) == 0 {
+		return opts
+	}
+	result := make([]Option, 0, len(defaults)+len(opts))
+	result = append(result, defaults...)
+	return append(result, opts...)
+}
+
+// mergeMap always returns a new map, as base may be the map passed by caller
+func mergeMap(base map[string]string, added map[string]string) map[string]string {
+	result := make(map[string]string, len(base)+len(added))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range added {
+		result[k] = v
+	}
+	return result
+}
+
 func WithRequestId(requestId string) Option {
 	return func(options *Options) {
 		options.RequestId = requestId
@@ -26,9 +49,11 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithHeaders merges headers into the headers set by the former options,
+// the same names are overridden, and the map passed is never modified
 func WithHeaders(headers map[string]string) Option {
 	return func(options *Options) {
-		options.Headers = headers
+		options.Headers = mergeMap(options.Headers, headers)
 	}
 }
 
@@ -56,9 +81,10 @@ func WithStage(stage string) Option {
 	}
 }
 
+// WithQueries merges queries like WithHeaders
 func WithQueries(queries map[string]string) Option {
 	return func(options *Options) {
-		options.Queries = queries
+		options.Queries = mergeMap(options.Queries, queries)
 	}
 }
 
@@ -0,0 +1,26 @@
+package core
+
+import "errors"
+
+// PermanentError is the error of request itself detected before sending,
+// such as too many items, which fails again however many times it's resent
+type PermanentError struct {
+	Message string
+}
+
+func (receiver *PermanentError) Error() string {
+	return receiver.Message
+}
+
+func NewPermanentError(message string) error {
+	return &PermanentError{Message: message}
+}
+
+// IsPermanentError reports whether err is caused by the request itself,
+// FieldErrors returned by validation are permanent too
+func IsPermanentError(err error) bool {
+	var permanentError *PermanentError
+	var fieldErrors FieldErrors
+	var fieldError *FieldError
+	return errors.As(err, &permanentError) || errors.As(err, &fieldErrors) || errors.As(err, &fieldError)
+}
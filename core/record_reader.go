@@ -0,0 +1,295 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultCsvListSeparator = "|"
+	maxJsonlLineBytes       = 16 << 20
+)
+
+// LineError is returned by MessageReader when a line can't be parsed,
+// the reader could still be read after it
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (receiver *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", receiver.Line, receiver.Err.Error())
+}
+
+// MessageReader reads messages one by one, io.EOF is returned after the last one.
+// *LineError is returned for the line can't be parsed, other errors stop reading.
+type MessageReader interface {
+	Read() (proto.Message, error)
+}
+
+// NewJsonlReader reads every line of reader as a json message,
+// blank lines are skipped, unknown fields are rejected
+func NewJsonlReader(reader io.Reader, newMessage func() proto.Message) *JsonlReader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJsonlLineBytes)
+	return &JsonlReader{scanner: scanner, newMessage: newMessage}
+}
+
+type JsonlReader struct {
+	scanner    *bufio.Scanner
+	newMessage func() proto.Message
+	line       int
+	// Ignore the fields not defined in message
+	DiscardUnknown bool
+}
+
+func (receiver *JsonlReader) Read() (proto.Message, error) {
+	for receiver.scanner.Scan() {
+		receiver.line++
+		content := bytes.TrimSpace(receiver.scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+		message := receiver.newMessage()
+		unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: receiver.DiscardUnknown}
+		if err := unmarshaler.Unmarshal(content, message); err != nil {
+			return nil, &LineError{Line: receiver.line, Err: err}
+		}
+		return message, nil
+	}
+	if err := receiver.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// CsvMapping declares how the columns of csv are set into message
+type CsvMapping struct {
+	// Column header to field path, see SetFieldPath for the syntax of path.
+	// The columns not declared are ignored, and empty cells are skipped.
+	Columns map[string]string
+
+	// Separator of the elements of repeated scalar field in a cell, default "|"
+	ListSeparator string
+
+	// Field delimiter, default ','
+	Comma rune
+}
+
+// NewCsvReader reads the first line of reader as column headers,
+// and every following record as a message. The Line of LineError
+// is the line where the record starts, as a quoted cell may span lines.
+func NewCsvReader(reader io.Reader, mapping *CsvMapping, newMessage func() proto.Message) *CsvReader {
+	lines := &lineReader{reader: bufio.NewReader(reader)}
+	csvReader := csv.NewReader(lines)
+	csvReader.FieldsPerRecord = -1
+	if mapping.Comma != 0 {
+		csvReader.Comma = mapping.Comma
+	}
+	separator := mapping.ListSeparator
+	if separator == "" {
+		separator = defaultCsvListSeparator
+	}
+	return &CsvReader{
+		reader:     csvReader,
+		lines:      lines,
+		mapping:    mapping,
+		separator:  separator,
+		newMessage: newMessage,
+	}
+}
+
+type CsvReader struct {
+	reader     *csv.Reader
+	lines      *lineReader
+	mapping    *CsvMapping
+	separator  string
+	newMessage func() proto.Message
+	// paths[i] is the field path of column i, empty if not mapped
+	paths []string
+}
+
+func (receiver *CsvReader) Read() (proto.Message, error) {
+	if receiver.paths == nil {
+		if err := receiver.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+	record, err := receiver.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &LineError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, err
+	}
+	// the reader stops at the last line of record, which
+	// spans one more line for every line break in cells
+	line := receiver.lines.count
+	for _, cell := range record {
+		line -= strings.Count(cell, "\n")
+	}
+	message := receiver.newMessage()
+	for i, cell := range record {
+		if i >= len(receiver.paths) || receiver.paths[i] == "" || cell == "" {
+			continue
+		}
+		if err := SetFieldPath(message, receiver.paths[i], cell, receiver.separator); err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+	}
+	return message, nil
+}
+
+func (receiver *CsvReader) readHeader() error {
+	header, err := receiver.reader.Read()
+	if err == io.EOF {
+		return errors.New("csv header is missing")
+	}
+	if err != nil {
+		return err
+	}
+	receiver.paths = make([]string, len(header))
+	mapped := 0
+	for i, column := range header {
+		// the BOM is written by some spreadsheet softwares
+		column = strings.TrimPrefix(strings.TrimSpace(column), "\uFEFF")
+		if path, ok := receiver.mapping.Columns[column]; ok {
+			receiver.paths[i] = path
+			mapped++
+		}
+	}
+	if mapped < len(receiver.mapping.Columns) {
+		return errors.New(fmt.Sprintf("csv header misses some mapped columns, header:%v", header))
+	}
+	return nil
+}
+
+// lineReader returns at most one line by every Read, so that csv.Reader, which
+// buffers its input, never reads ahead of the record it's parsing, and count
+// is the line where the last record read by csv.Reader ends
+type lineReader struct {
+	reader *bufio.Reader
+	// the part of current line not returned yet
+	rest    []byte
+	partial bool
+	count   int
+}
+
+func (receiver *lineReader) Read(p []byte) (int, error) {
+	if len(receiver.rest) == 0 {
+		line, err := receiver.reader.ReadSlice('\n')
+		if len(line) == 0 {
+			return 0, err
+		}
+		if !receiver.partial {
+			receiver.count++
+		}
+		// ReadSlice returns bufio.ErrBufferFull for a line longer than buffer
+		receiver.partial = line[len(line)-1] != '\n'
+		receiver.rest = line
+	}
+	n := copy(p, receiver.rest)
+	receiver.rest = receiver.rest[n:]
+	return n, nil
+}
+
+// RecordBatchReader reads the messages of MessageReader batch by batch,
+// the lines can't be parsed are skipped, and their errors are kept for caller.
+// The readers of products wrap it with their typed messages.
+type RecordBatchReader struct {
+	reader     MessageReader
+	lineErrors []*LineError
+}
+
+func NewRecordBatchReader(reader MessageReader) *RecordBatchReader {
+	return &RecordBatchReader{reader: reader}
+}
+
+// LineErrors returns the errors of lines skipped by ReadMessages
+func (receiver *RecordBatchReader) LineErrors() []*LineError {
+	return receiver.lineErrors
+}
+
+// ReadMessage returns the next message, see MessageReader for the errors
+func (receiver *RecordBatchReader) ReadMessage() (proto.Message, error) {
+	return receiver.reader.Read()
+}
+
+// ReadMessages passes at most size messages to add,
+// io.EOF is returned after the last batch is passed
+func (receiver *RecordBatchReader) ReadMessages(size int, add func(message proto.Message)) error {
+	for count := 0; count < size; {
+		message, err := receiver.reader.Read()
+		if lineErr, ok := err.(*LineError); ok {
+			receiver.lineErrors = append(receiver.lineErrors, lineErr)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		add(message)
+		count++
+	}
+	return nil
+}
+
+// RecordWriteBatchCount is the count of items read at a time by WriteBatches
+const RecordWriteBatchCount = 10 * MaxWriteItemCount
+
+// WriteBatches reads at most RecordWriteBatchCount items at a time by read,
+// which returns the count of items read, and writes them by write. The batch
+// passed to write is the index range of its items in all read items.
+// Reading stops at the first read error, which is returned, otherwise the first
+// error of write is returned. The statuses of batches are merged by MergeChunkStatus.
+func WriteBatches(read func(size int) (int, error),
+	write func(batch Chunk) (*protocol.Status, error)) (*protocol.Status, error) {
+	var (
+		merged   *protocol.Status
+		firstErr error
+		offset   int
+	)
+	for batchIdx := 0; ; batchIdx++ {
+		count, readErr := read(RecordWriteBatchCount)
+		if count > 0 {
+			status, err := write(Chunk{Index: batchIdx, Start: offset, End: offset + count})
+			if firstErr == nil {
+				firstErr = err
+			}
+			merged = MergeChunkStatus(merged, status)
+			offset += count
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return completeStatus(merged), readErr
+		}
+	}
+	return completeStatus(merged), firstErr
+}
+
+func completeStatus(status *protocol.Status) *protocol.Status {
+	if status == nil {
+		return &protocol.Status{Code: StatusCodeSuccess}
+	}
+	return status
+}
+
+// OffsetIndex converts the index of item in batch into the index in all read
+// items, the negative index of the error not belonging to any item is kept
+func OffsetIndex(index int, offset int) int {
+	if index < 0 {
+		return index
+	}
+	return index + offset
+}
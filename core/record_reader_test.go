@@ -0,0 +1,116 @@
+package core
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+func newProduct() proto.Message {
+	return &protocol.Product{}
+}
+
+func TestCsvReader(t *testing.T) {
+	content := "id,price,category,tags,color,ignored\n" +
+		"p1,100,Shoes,new|hot,red,x\n" +
+		"p2,abc,Shoes,,,\n" +
+		"p3,,,,blue,\n"
+	mapping := &CsvMapping{Columns: map[string]string{
+		"id":       "product_id",
+		"price":    "price.current_price",
+		"category": "categories[0].category_nodes[0].id_or_name",
+		"tags":     "tags",
+		"color":    "extra.color",
+	}}
+	reader := NewCsvReader(strings.NewReader(content), mapping, newProduct)
+	message, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := message.(*protocol.Product)
+	if product.ProductId != "p1" || product.GetPrice().GetCurrentPrice() != 100 ||
+		product.GetCategories()[0].GetCategoryNodes()[0].GetIdOrName() != "Shoes" ||
+		len(product.Tags) != 2 || product.Extra["color"] != "red" {
+		t.Errorf("unexpected product: %v", product)
+	}
+	if _, err = reader.Read(); err == nil {
+		t.Fatalf("expect error of invalid price")
+	}
+	if lineErr, ok := err.(*LineError); !ok || lineErr.Line != 3 {
+		t.Errorf("expect LineError at line 3, got %v", err)
+	}
+	message, err = reader.Read()
+	if err != nil || message.(*protocol.Product).Extra["color"] != "blue" {
+		t.Errorf("unexpected read result after line error, message:%v err:%v", message, err)
+	}
+	if _, err = reader.Read(); err != io.EOF {
+		t.Errorf("expect io.EOF, got %v", err)
+	}
+}
+
+func TestJsonlReader(t *testing.T) {
+	content := `{"product_id":"p1","price":{"current_price":100}}
+
+{"product_id":
+{"productId":"p3"}
+`
+	reader := NewJsonlReader(strings.NewReader(content), newProduct)
+	var (
+		ids        []string
+		lineErrors []int
+	)
+	for {
+		message, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if lineErr, ok := err.(*LineError); ok {
+			lineErrors = append(lineErrors, lineErr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, message.(*protocol.Product).ProductId)
+	}
+	if len(ids) != 2 || ids[1] != "p3" || len(lineErrors) != 1 || lineErrors[0] != 3 {
+		t.Errorf("unexpected result, ids:%v line errors:%v", ids, lineErrors)
+	}
+}
+
+func TestCsvReaderLineOfMultilineCell(t *testing.T) {
+	content := "id,title,price\n" +
+		"p1,\"two\nlines\",100\n" +
+		"\n" +
+		"p2,\"three\nmore\nlines\",abc\n" +
+		"p3,\"bad\"quote,1\n" +
+		"p4,,x\n"
+	mapping := &CsvMapping{Columns: map[string]string{
+		"id":    "product_id",
+		"title": "extra.title",
+		"price": "price.current_price",
+	}}
+	reader := NewCsvReader(strings.NewReader(content), mapping, newProduct)
+	if _, err := reader.Read(); err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		lineErr, ok := err.(*LineError)
+		if !ok {
+			t.Fatalf("expect LineError, got %v", err)
+		}
+		lines = append(lines, lineErr.Line)
+	}
+	// the lines where the records start, the blank line is counted
+	if len(lines) != 3 || lines[0] != 5 || lines[1] != 8 || lines[2] != 9 {
+		t.Errorf("unexpected lines of errors %v", lines)
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultVolcService = "air"
+	defaultPingPath    = "/predict/api/ping"
+)
+
+// Region is registered by RegisterRegion, the built-in ones are registered at init
+type Region int
+
+const (
+	RegionUnknown Region = iota
+	RegionCn
+	RegionSg
+	RegionUs
+	RegionAirCn
+	RegionAirSg
+	RegionSaasSg
+)
+
+type RegionConfig struct {
+	// Unique name of region, which is parsed by ParseRegion case-insensitively
+	Name string
+
+	// Server addresses, the first one is used before host health is checked
+	Hosts []string
+
+	// The region and service used by volc signature, service is "air" if empty
+	VolcRegion  string
+	VolcService string
+
+	// Schema used if builder doesn't set it, "https" if empty
+	Schema string
+
+	// Path of URL checking host health, "/predict/api/ping" if empty
+	PingPath string
+}
+
+var regionRegistry = struct {
+	lock    sync.RWMutex
+	configs []*RegionConfig
+	byName  map[string]Region
+}{
+	// index 0 is RegionUnknown
+	configs: []*RegionConfig{nil},
+	byName:  make(map[string]Region),
+}
+
+func init() {
+	builtins := []*RegionConfig{
+		{Name: "cn", Hosts: []string{"rec-b.volcengineapi.com", "rec.volcengineapi.com"}, VolcRegion: "cn-north-1"},
+		{Name: "sg", Hosts: []string{"rec-ap-singapore-1.byteplusapi.com"}, VolcRegion: "ap-singapore-1"},
+		{Name: "us", Hosts: []string{"rec-us-east-1.byteplusapi.com"}, VolcRegion: "us-east-1"},
+		{Name: "air_cn", Hosts: []string{"byteair-api-cn1.snssdk.com"}, VolcRegion: "cn-north-1"},
+		{Name: "air_sg", Hosts: []string{"byteair-api-sg1.byteintlapi.com"}, VolcRegion: "ap-singapore-1"},
+		{Name: "saas_sg", Hosts: []string{"rec-api-sg1.recplusapi.com"}, VolcRegion: "ap-singapore-1"},
+	}
+	// registered in the order of Region constants
+	for _, config := range builtins {
+		if _, err := RegisterRegion(config); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterRegion
+//
+// Registers a region with its hosts, so the new region could be used without
+// upgrading sdk. The returned Region is set by ClientBuilder.Region, or parsed
+// from config by its name. Registering a name again is rejected.
+func RegisterRegion(config *RegionConfig) (Region, error) {
+	name := strings.ToLower(strings.TrimSpace(config.Name))
+	if name == "" {
+		return RegionUnknown, errors.New("region name is empty")
+	}
+	if len(config.Hosts) == 0 {
+		return RegionUnknown, errors.New(fmt.Sprintf("hosts of region %s are empty", name))
+	}
+	if config.VolcRegion == "" {
+		return RegionUnknown, errors.New(fmt.Sprintf("volc region of region %s is empty", name))
+	}
+	registered := *config
+	registered.Name = name
+	registered.Hosts = append([]string(nil), config.Hosts...)
+	if registered.VolcService == "" {
+		registered.VolcService = defaultVolcService
+	}
+	if registered.PingPath == "" {
+		registered.PingPath = defaultPingPath
+	}
+	if !strings.HasPrefix(registered.PingPath, "/") {
+		registered.PingPath = "/" + registered.PingPath
+	}
+	regionRegistry.lock.Lock()
+	defer regionRegistry.lock.Unlock()
+	if _, exist := regionRegistry.byName[name]; exist {
+		return RegionUnknown, errors.New(fmt.Sprintf("region %s is registered", name))
+	}
+	region := Region(len(regionRegistry.configs))
+	regionRegistry.configs = append(regionRegistry.configs, &registered)
+	regionRegistry.byName[name] = region
+	return region, nil
+}
+
+// ParseRegion returns the registered region of name, such as "sg" or "AIR_CN"
+func ParseRegion(name string) (Region, error) {
+	regionRegistry.lock.RLock()
+	defer regionRegistry.lock.RUnlock()
+	region, exist := regionRegistry.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !exist {
+		return RegionUnknown, errors.New(fmt.Sprintf("unknown region %q, should be one of %s",
+			name, strings.Join(regionNames(), ", ")))
+	}
+	return region, nil
+}
+
+// String returns the registered name of region
+func (receiver Region) String() string {
+	if config := receiver.config(); config != nil {
+		return config.Name
+	}
+	if receiver == RegionUnknown {
+		return "unknown"
+	}
+	return fmt.Sprintf("Region(%d)", int(receiver))
+}
+
+// config returns nil if region is not registered
+func (receiver Region) config() *RegionConfig {
+	regionRegistry.lock.RLock()
+	defer regionRegistry.lock.RUnlock()
+	if receiver <= RegionUnknown || int(receiver) >= len(regionRegistry.configs) {
+		return nil
+	}
+	return regionRegistry.configs[receiver]
+}
+
+// regionNames is called with lock held
+func regionNames() []string {
+	names := make([]string, 0, len(regionRegistry.byName))
+	for name := range regionRegistry.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterRegion(t *testing.T) {
+	// the registry is global, the name is unique so that the test could run repeatedly
+	name := fmt.Sprintf("Test_EU_%d", time.Now().UnixNano())
+	region, err := RegisterRegion(&RegionConfig{
+		Name:       name,
+		Hosts:      []string{"rec-eu.example.com"},
+		VolcRegion: "eu-west-1",
+		Schema:     "http",
+		PingPath:   "health",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RegisterRegion(&RegionConfig{Name: strings.ToLower(name), Hosts: []string{"a"}, VolcRegion: "b"}); err == nil {
+		t.Fatal("registering name again should be rejected")
+	}
+	parsed, err := ParseRegion(" " + strings.ToUpper(name) + " ")
+	if err != nil || parsed != region || region.String() != strings.ToLower(name) {
+		t.Fatalf("unexpected parsed region %v, err:%v", parsed, err)
+	}
+	if RegionAirSg.String() != "air_sg" || RegionUnknown.String() != "unknown" {
+		t.Fatalf("unexpected built-in names %s %s", RegionAirSg, RegionUnknown)
+	}
+	if _, err := ParseRegion("mars"); err == nil {
+		t.Fatal("unknown name should be rejected")
+	}
+
+	context, err := NewContext(&ContextParam{Tenant: "t", TenantId: "1", AK: "ak", SK: "sk", Region: region})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if context.Hosts()[0] != "rec-eu.example.com" || context.Schema() != "http" || context.pingPath != "/health" {
+		t.Fatalf("unexpected context %+v", context)
+	}
+	if context.volcCredentials.Region != "eu-west-1" || context.volcCredentials.Service != "air" {
+		t.Fatalf("unexpected volc credentials %+v", context.volcCredentials)
+	}
+	if _, err := NewContext(&ContextParam{Tenant: "t", TenantId: "1", AK: "ak", SK: "sk", Region: Region(1000)}); err == nil {
+		t.Fatal("unregistered region should be rejected")
+	}
+}
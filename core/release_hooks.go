@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// ReleaseHooks holds the funcs called when client is released,
+// which drain the components depending on client, e.g. BufferedWriter.
+// Hooks are called in reverse order of adding, and at most once.
+type ReleaseHooks struct {
+	lock  sync.Mutex
+	hooks []func()
+}
+
+func (receiver *ReleaseHooks) Add(hook func()) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	receiver.hooks = append(receiver.hooks, hook)
+}
+
+func (receiver *ReleaseHooks) Run() {
+	receiver.lock.Lock()
+	hooks := receiver.hooks
+	receiver.hooks = nil
+	receiver.lock.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// runContext runs hooks until ctx is done, the hooks not finished
+// keep running, and their calls to client after close are rejected
+func (receiver *ReleaseHooks) runContext(ctx context.Context) error {
+	done := make(chan struct{})
+	AsyncExecute(func() {
+		defer close(done)
+		receiver.Run()
+	})
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ErrCloseTimeout
+	}
+}
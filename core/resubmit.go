@@ -0,0 +1,206 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/google/uuid"
+)
+
+const defaultResubmitMaxAttempts = 3
+
+// The per-item error messages containing these words are taken as retryable
+var retryableItemErrorWords = []string{
+	"timeout", "timed out", "internal", "unavailable", "too many", "busy", "try again",
+}
+
+type ResubmitConfig struct {
+	// Max count of sending an item, including the first one, default 3
+	MaxAttempts int
+
+	// Backoff before resending, DefaultBackoff if nil
+	Backoff *Backoff
+
+	// Reports whether the per-item error could succeed by resending,
+	// DefaultRetryableItemError if nil
+	IsRetryableItemError func(message string) bool
+
+	// Receives the items given up, they're only reported in ResubmitReport if nil
+	Sink DeadLetterSink
+}
+
+// DefaultRetryableItemError takes the per-item errors about server state as
+// retryable, such as timeout, the others are about the item itself, which are permanent
+func DefaultRetryableItemError(message string) bool {
+	message = strings.ToLower(message)
+	for _, word := range retryableItemErrorWords {
+		if strings.Contains(message, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ItemFailure is the per-item error of response
+type ItemFailure struct {
+	// Index of item in the caller's request, -1 if it can't be matched
+	Index int
+	// The failed item returned by server, which is used only if Index is -1
+	Item    interface{}
+	Message string
+}
+
+// ResubmitSendFunc sends the items at indexes of the caller's request with requestId,
+// and returns the response status with the per-item failures
+type ResubmitSendFunc func(indexes []int, requestId string) (*protocol.Status, []*ItemFailure, error)
+
+type ResubmitReport struct {
+	// Count of items succeeded
+	SuccessCount int
+	// Count of sending requests
+	Attempts int
+	// The items given up
+	DeadLetters []*DeadLetter
+}
+
+// NewResubmitRunner is used by the resubmitters of products, which adapt
+// their requests and responses into ResubmitSendFunc
+func NewResubmitRunner(config *ResubmitConfig) *ResubmitRunner {
+	runner := &ResubmitRunner{
+		maxAttempts: defaultResubmitMaxAttempts,
+		backoff:     DefaultBackoff,
+		retryable:   DefaultRetryableItemError,
+	}
+	if config == nil {
+		return runner
+	}
+	if config.MaxAttempts > 0 {
+		runner.maxAttempts = config.MaxAttempts
+	}
+	if config.Backoff != nil {
+		runner.backoff = config.Backoff
+	}
+	if config.IsRetryableItemError != nil {
+		runner.retryable = config.IsRetryableItemError
+	}
+	runner.sink = config.Sink
+	return runner
+}
+
+type ResubmitRunner struct {
+	maxAttempts int
+	backoff     *Backoff
+	retryable   func(message string) bool
+	sink        DeadLetterSink
+	// replaced in tests
+	sleep func(time.Duration)
+}
+
+// Run sends count items, then resends the retryable failed ones with backoff,
+// every attempt uses a new request id as the items differ from the last one.
+// The permanent failures and the items exceeding max attempts are put into sink,
+// the error of sink is returned together with the report.
+func (receiver *ResubmitRunner) Run(topic string, count int,
+	item func(index int) interface{}, send ResubmitSendFunc) (*ResubmitReport, error) {
+	report := &ResubmitReport{}
+	pending := make([]int, count)
+	for i := range pending {
+		pending[i] = i
+	}
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			receiver.doSleep(receiver.backoff.Delay(attempt - 1))
+		}
+		requestId := uuid.NewString()
+		status, failures, err := send(pending, requestId)
+		report.Attempts++
+		lastAttempt := attempt+1 >= receiver.maxAttempts
+		var retries []int
+		giveUp := func(index int, failedItem interface{}, message string) {
+			if index >= 0 {
+				failedItem = item(index)
+			}
+			report.DeadLetters = append(report.DeadLetters, &DeadLetter{
+				Topic:     topic,
+				Item:      failedItem,
+				Message:   message,
+				RequestId: requestId,
+				Timestamp: time.Now(),
+			})
+		}
+		wholeFailure, retryable := receiver.classifyRequest(status, len(failures), err)
+		if wholeFailure != "" {
+			for _, index := range pending {
+				if retryable && !lastAttempt {
+					retries = append(retries, index)
+					continue
+				}
+				giveUp(index, nil, wholeFailure)
+			}
+		} else {
+			for _, failure := range failures {
+				if failure.Index >= 0 && receiver.retryable(failure.Message) && !lastAttempt {
+					retries = append(retries, failure.Index)
+					continue
+				}
+				giveUp(failure.Index, failure.Item, failure.Message)
+			}
+			report.SuccessCount += len(pending) - len(failures)
+		}
+		if len(retries) > 0 {
+			logs.Warn("resubmit %d %s items, attempt:%d", len(retries), topic, attempt+1)
+		}
+		pending = retries
+	}
+	if receiver.sink == nil || len(report.DeadLetters) == 0 {
+		return report, nil
+	}
+	return report, receiver.sink.Put(report.DeadLetters)
+}
+
+// classifyRequest returns the message if the whole request failed, and whether it's retryable.
+// The request error is retryable unless it's permanent, such as rejected by validation before sending
+func (receiver *ResubmitRunner) classifyRequest(status *protocol.Status,
+	failedCount int, err error) (string, bool) {
+	if err != nil {
+		return err.Error(), !IsPermanentError(err)
+	}
+	message := ChunkFailureMessage(status, failedCount, nil)
+	if message == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s, code:%d", message, status.GetCode()), IsRetryableStatus(status.GetCode())
+}
+
+// ResubmitOptions sets the request id of attempt after the opts of caller
+func ResubmitOptions(opts []option.Option, requestId string) []option.Option {
+	result := make([]option.Option, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, option.WithRequestId(requestId))
+}
+
+// MatchResubmitIndexes is MatchIndexes for the items at indexes of the caller's request,
+// `match` is called with the index in request, and the matched index in request is returned
+func MatchResubmitIndexes(indexes []int, failedCount int, match func(failedIdx, itemIdx int) bool) []int {
+	matched := MatchIndexes(Chunk{End: len(indexes)}, failedCount, func(failedIdx, sentIdx int) bool {
+		return match(failedIdx, indexes[sentIdx])
+	})
+	for i, sentIdx := range matched {
+		if sentIdx >= 0 {
+			matched[i] = indexes[sentIdx]
+		}
+	}
+	return matched
+}
+
+func (receiver *ResubmitRunner) doSleep(delay time.Duration) {
+	if receiver.sleep != nil {
+		receiver.sleep(delay)
+		return
+	}
+	time.Sleep(delay)
+}
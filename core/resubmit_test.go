@@ -0,0 +1,75 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+)
+
+func TestResubmitRunner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resubmit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := NewJsonlDeadLetterSink(filepath.Join(dir, "dead.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := NewResubmitRunner(&ResubmitConfig{MaxAttempts: 3, Sink: sink})
+	var delays []time.Duration
+	runner.sleep = func(delay time.Duration) { delays = append(delays, delay) }
+
+	items := []string{`{"id":"0"}`, `{"id":"1"}`, `{"id":"2"}`}
+	var sent [][]int
+	send := func(indexes []int, requestId string) (*protocol.Status, []*ItemFailure, error) {
+		sent = append(sent, indexes)
+		switch len(sent) {
+		case 1:
+			return &protocol.Status{Code: 1001}, []*ItemFailure{
+				{Index: 1, Message: "invalid field"},
+				{Index: 2, Message: "write timeout"},
+			}, nil
+		case 2:
+			return &protocol.Status{Code: 503, Message: "unavailable"}, nil, nil
+		default:
+			return &protocol.Status{Code: StatusCodeSuccess}, nil, nil
+		}
+	}
+	report, err := runner.Run("user", len(items), func(index int) interface{} { return items[index] }, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent, [][]int{{0, 1, 2}, {2}, {2}}) {
+		t.Fatalf("unexpected sent %v", sent)
+	}
+	if report.SuccessCount != 2 || report.Attempts != 3 || len(report.DeadLetters) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(delays) != 2 || delays[0] != DefaultBackoff.Delay(0) || delays[1] != DefaultBackoff.Delay(1) {
+		t.Fatalf("unexpected delays %v", delays)
+	}
+	_ = sink.Close()
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "dead.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("unexpected lines %v", lines)
+	}
+	var letter map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &letter); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := letter["item"].(map[string]interface{})
+	if item["id"] != "1" || letter["message"] != "invalid field" || letter["request_id"] == "" {
+		t.Fatalf("unexpected dead letter %s", lines[0])
+	}
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SceneConfig declares a predict scene allowed to be used by client,
+// and the defaults applied to every predict request of this scene.
+type SceneConfig struct {
+	// Name of the scene, which will be filled in predict url
+	Name string
+
+	// Default count of predicted items, only used when request not fill size
+	Size int32
+
+	// Default http timeout of the predict request
+	Timeout time.Duration
+
+	// Default timeout that server should return in
+	ServerTimeout time.Duration
+
+	// Extra fields added to the predict request,
+	// the field already filled in request will not be overwritten
+	Extra map[string]string
+
+	// Scenes tried in order when predict with this scene fails,
+	// all of them should be registered as well
+	Fallback []string
+}
+
+// WithOptions puts the scene default options before caller options,
+// so that options specified by caller take precedence.
+func (receiver *SceneConfig) WithOptions(opts []option.Option) []option.Option {
+	result := make([]option.Option, 0, len(opts)+2)
+	if receiver.Timeout > 0 {
+		result = append(result, option.WithTimeout(receiver.Timeout))
+	}
+	if receiver.ServerTimeout > 0 {
+		result = append(result, option.WithServerTimeout(receiver.ServerTimeout))
+	}
+	return append(result, opts...)
+}
+
+// HasRequestDefaults reports whether the scene needs to modify predict request
+func (receiver *SceneConfig) HasRequestDefaults() bool {
+	return receiver.Size > 0 || len(receiver.Extra) > 0
+}
+
+func (receiver *SceneConfig) copy() *SceneConfig {
+	copied := *receiver
+	if receiver.Extra != nil {
+		copied.Extra = make(map[string]string, len(receiver.Extra))
+		for k, v := range receiver.Extra {
+			copied.Extra[k] = v
+		}
+	}
+	copied.Fallback = append([]string(nil), receiver.Fallback...)
+	return &copied
+}
+
+func (receiver *SceneConfig) validate() error {
+	if receiver == nil || receiver.Name == "" {
+		return errors.New("scene name is empty")
+	}
+	if receiver.Size < 0 || receiver.Timeout < 0 || receiver.ServerTimeout < 0 {
+		return fmt.Errorf("scene '%s' has negative size or timeout", receiver.Name)
+	}
+	return nil
+}
+
+// ApplySceneDefaults fills the size and extra defaults of scene into a copy
+// of the predict request, the request of caller will not be modified.
+// The request is expected to have an int32 field "size", and a string map
+// field "extra", or a message field "extra" holding such a map.
+func ApplySceneDefaults(request proto.Message, scene *SceneConfig) proto.Message {
+	if !scene.HasRequestDefaults() {
+		return request
+	}
+	request = proto.Clone(request)
+	message := request.ProtoReflect()
+	fields := message.Descriptor().Fields()
+	size := fields.ByName("size")
+	if scene.Size > 0 && size != nil && size.Kind() == protoreflect.Int32Kind && message.Get(size).Int() == 0 {
+		message.Set(size, protoreflect.ValueOfInt32(scene.Size))
+	}
+	extra := fields.ByName("extra")
+	if len(scene.Extra) == 0 || extra == nil {
+		return request
+	}
+	if extra.Message() != nil && !extra.IsMap() {
+		// such as retail PredictExtra, which holds the map in its own extra field
+		message = message.Mutable(extra).Message()
+		extra = message.Descriptor().Fields().ByName("extra")
+		if extra == nil {
+			return request
+		}
+	}
+	if !extra.IsMap() || extra.MapValue().Kind() != protoreflect.StringKind {
+		return request
+	}
+	values := message.Mutable(extra).Map()
+	for k, v := range scene.Extra {
+		key := protoreflect.ValueOfString(k).MapKey()
+		if !values.Has(key) {
+			values.Set(key, protoreflect.ValueOfString(v))
+		}
+	}
+	return request
+}
+
+func NewSceneRegistry(scenes []*SceneConfig) (*SceneRegistry, error) {
+	registry := &SceneRegistry{scenes: make(map[string]*SceneConfig, len(scenes))}
+	for _, scene := range scenes {
+		if err := scene.validate(); err != nil {
+			return nil, err
+		}
+		registry.put(scene.copy())
+	}
+	if err := registry.Validate(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// SceneRegistry holds the scenes allowed to predict.
+// An empty registry accepts any scene, which keeps the behavior
+// of clients that never declare scenes.
+type SceneRegistry struct {
+	lock   sync.RWMutex
+	scenes map[string]*SceneConfig
+	// keep the registering order for introspection
+	names []string
+}
+
+// Register adds a copy of scene, or replaces the one with the same name.
+// The scene is rejected if its fallback scenes are not registered yet.
+func (receiver *SceneRegistry) Register(scene *SceneConfig) error {
+	if err := scene.validate(); err != nil {
+		return err
+	}
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if err := receiver.validateFallback(scene); err != nil {
+		return err
+	}
+	receiver.put(scene.copy())
+	return nil
+}
+
+func (receiver *SceneRegistry) put(scene *SceneConfig) {
+	if _, exist := receiver.scenes[scene.Name]; !exist {
+		receiver.names = append(receiver.names, scene.Name)
+	}
+	receiver.scenes[scene.Name] = scene
+}
+
+// Validate checks that every fallback scene is registered and is not the scene itself
+func (receiver *SceneRegistry) Validate() error {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	for _, name := range receiver.names {
+		if err := receiver.validateFallback(receiver.scenes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (receiver *SceneRegistry) validateFallback(scene *SceneConfig) error {
+	for _, fallback := range scene.Fallback {
+		if fallback == scene.Name {
+			return fmt.Errorf("scene '%s' use itself as fallback", scene.Name)
+		}
+		if _, exist := receiver.scenes[fallback]; !exist {
+			return fmt.Errorf("fallback scene '%s' of scene '%s' is not registered", fallback, scene.Name)
+		}
+	}
+	return nil
+}
+
+// Get returns a copy of the registered scene
+func (receiver *SceneRegistry) Get(name string) (*SceneConfig, bool) {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	scene, exist := receiver.scenes[name]
+	if !exist {
+		return nil, false
+	}
+	return scene.copy(), true
+}
+
+// Names returns the registered scene names in registering order
+func (receiver *SceneRegistry) Names() []string {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	return append([]string(nil), receiver.names...)
+}
+
+// Scenes returns the copies of registered scene configs in registering order
+func (receiver *SceneRegistry) Scenes() []*SceneConfig {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	result := make([]*SceneConfig, 0, len(receiver.names))
+	for _, name := range receiver.names {
+		result = append(result, receiver.scenes[name].copy())
+	}
+	return result
+}
+
+func (receiver *SceneRegistry) Enabled() bool {
+	receiver.lock.RLock()
+	defer receiver.lock.RUnlock()
+	return len(receiver.scenes) > 0
+}
+
+// Resolve finds the config of scene, the unknown scene is rejected
+// if any scene is registered, otherwise a config without defaults is returned
+func (receiver *SceneRegistry) Resolve(name string) (*SceneConfig, error) {
+	if !receiver.Enabled() {
+		return &SceneConfig{Name: name}, nil
+	}
+	scene, exist := receiver.Get(name)
+	if !exist {
+		return nil, fmt.Errorf("scene '%s' is not registered", name)
+	}
+	return scene, nil
+}
+
+// Execute calls predict with the resolved scene, if it fails,
+// the fallback scenes will be tried in order until one succeeds
+func (receiver *SceneRegistry) Execute(name string, predict func(scene *SceneConfig) error) error {
+	scene, err := receiver.Resolve(name)
+	if err != nil {
+		return err
+	}
+	err = predict(scene)
+	for _, fallbackName := range scene.Fallback {
+		if err == nil {
+			return nil
+		}
+		fallback, exist := receiver.Get(fallbackName)
+		if !exist {
+			continue
+		}
+		logs.Warn("predict with scene '%s' fail, fallback to '%s', err:%v",
+			scene.Name, fallbackName, err)
+		err = predict(fallback)
+	}
+	return err
+}
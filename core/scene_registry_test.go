@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	general "github.com/byteplus-sdk/sdk-go/general/protocol"
+	retail "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSceneRegistry(t *testing.T) {
+	scenes := []*SceneConfig{
+		{Name: "home", Size: 10, Fallback: []string{"default"}},
+		{Name: "default"},
+	}
+	registry, err := NewSceneRegistry(scenes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSceneRegistry([]*SceneConfig{{Name: "home", Fallback: []string{"home"}}}); err == nil {
+		t.Error("scene using itself as fallback should be rejected")
+	}
+	if err := registry.Register(&SceneConfig{Name: "detail", Fallback: []string{"unknown"}}); err == nil {
+		t.Error("scene with unregistered fallback should be rejected after built")
+	}
+	if err := registry.Register(&SceneConfig{Name: "detail", Size: -1}); err == nil {
+		t.Error("scene with negative size should be rejected")
+	}
+	if !reflect.DeepEqual(registry.Names(), []string{"home", "default"}) {
+		t.Errorf("unexpected names %v", registry.Names())
+	}
+	// the registry keeps its own copies
+	scenes[0].Size = 20
+	registry.Scenes()[0].Fallback[0] = "unknown"
+	home, _ := registry.Get("home")
+	if home.Size != 10 || home.Fallback[0] != "default" {
+		t.Errorf("registered scene is modified outside, got %+v", home)
+	}
+
+	var tried []string
+	err = registry.Execute("home", func(scene *SceneConfig) error {
+		tried = append(tried, scene.Name)
+		if scene.Name == "home" {
+			return errors.New("predict fail")
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(tried, []string{"home", "default"}) {
+		t.Errorf("unexpected fallback, tried:%v err:%v", tried, err)
+	}
+	if _, err := registry.Resolve("unknown"); err == nil {
+		t.Error("unregistered scene should be rejected")
+	}
+}
+
+func TestApplySceneDefaults(t *testing.T) {
+	scene := &SceneConfig{Name: "home", Size: 10, Extra: map[string]string{"k1": "v1", "k2": "v2"}}
+	request := &retail.PredictRequest{Extra: map[string]string{"k1": "caller"}}
+	filled := ApplySceneDefaults(request, scene).(*retail.PredictRequest)
+	expected := &retail.PredictRequest{Size: 10, Extra: map[string]string{"k1": "caller", "k2": "v2"}}
+	if !proto.Equal(filled, expected) || len(request.Extra) != 1 || request.Size != 0 {
+		t.Errorf("unexpected filled request %v, request of caller %v", filled, request)
+	}
+
+	// the map is held by PredictExtra of general
+	generalRequest := &general.PredictRequest{Size: 5}
+	generalFilled := ApplySceneDefaults(generalRequest, scene).(*general.PredictRequest)
+	if generalFilled.Size != 5 || !reflect.DeepEqual(generalFilled.GetExtra().GetExtra(), scene.Extra) {
+		t.Errorf("unexpected filled request %v", generalFilled)
+	}
+	if generalRequest.Extra != nil {
+		t.Errorf("request of caller is modified: %v", generalRequest)
+	}
+	if ApplySceneDefaults(request, &SceneConfig{Name: "default"}) != request {
+		t.Error("request should not be copied without defaults")
+	}
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrTransportClosed = errors.New("transport is closed")
+
+// NewSharedTransport
+//
+// Creates the http clients and host health checking shared by the clients built
+// with ContextParam.Transport, so that the clients of many tenants in the same
+// region use one connection pool and one ping goroutine. Only the host fields
+// of param are used: Schema, HostHeader, Hosts, Headers, Region and RecordMode.
+func NewSharedTransport(param *ContextParam) (*SharedTransport, error) {
+	if param.Region == RegionUnknown {
+		return nil, errors.New("region is null")
+	}
+	if param.Region.config() == nil {
+		return nil, errors.New(fmt.Sprintf("region %s is not registered", param.Region))
+	}
+	context := &Context{
+		region:          param.Region,
+		schema:          param.Schema,
+		hostHeader:      param.HostHeader,
+		customerHeaders: param.Headers,
+		recordMode:      param.RecordMode,
+	}
+	context.fillHosts(param)
+	context.fillHttpClients()
+	context.fillDefault()
+	transport := &SharedTransport{context: context}
+	transport.availabler = newHostAvailabler(context)
+	return transport, nil
+}
+
+type SharedTransport struct {
+	lock sync.Mutex
+	// holds the hosts and http clients shared, without tenant
+	context    *Context
+	availabler *HostAvailabler
+	// count of the clients attached, which are not released yet
+	attached int
+	closed   bool
+}
+
+// Attached returns the count of clients using transport
+func (receiver *SharedTransport) Attached() int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.attached
+}
+
+// Close stops the host health checking and closes the idle connections,
+// the clients using transport should be released before.
+func (receiver *SharedTransport) Close() {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.closed {
+		return
+	}
+	receiver.closed = true
+	receiver.availabler.Shutdown()
+	clients := receiver.context.httpClients
+	if clients.hostHTTPCli != nil {
+		clients.hostHTTPCli.CloseIdleConnections()
+	}
+	if clients.defaultHTTPCli != nil {
+		clients.defaultHTTPCli.CloseIdleConnections()
+	}
+}
+
+// share makes context use the hosts and http clients of transport
+func (receiver *SharedTransport) share(context *Context) error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.closed {
+		return ErrTransportClosed
+	}
+	context.schema = receiver.context.schema
+	context.hostHeader = receiver.context.hostHeader
+	context.hosts = receiver.context.hosts
+	context.httpClients = receiver.context.httpClients
+	context.transport = receiver
+	return nil
+}
+
+// attach returns the availabler of the client using transport,
+// whose Shutdown detaches urlCenter
+func (receiver *SharedTransport) attach(urlCenter URLCenter) *HostAvailabler {
+	receiver.lock.Lock()
+	receiver.attached++
+	receiver.lock.Unlock()
+	receiver.availabler.addURLCenter(urlCenter)
+	return &HostAvailabler{
+		context:    receiver.context,
+		urlCenters: []URLCenter{urlCenter},
+		transport:  receiver,
+	}
+}
+
+func (receiver *SharedTransport) detach(availabler *HostAvailabler) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	// Shutdown may be called more than once
+	if len(availabler.urlCenters) == 0 {
+		return
+	}
+	receiver.availabler.removeURLCenter(availabler.urlCenters[0])
+	availabler.urlCenters = nil
+	receiver.attached--
+}
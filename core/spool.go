@@ -0,0 +1,577 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+)
+
+const (
+	spoolSegmentSuffix       = ".seg"
+	spoolAckSuffix           = ".ack"
+	spoolRecordHeaderBytes   = 8
+	spoolAckBytes            = 8
+	defaultSpoolSegmentBytes = 16 << 20
+)
+
+var (
+	ErrSpoolFull   = errors.New("spool disk quota exceeded")
+	ErrSpoolClosed = errors.New("spool is closed")
+)
+
+type SpoolOverflowPolicy int
+
+const (
+	// SpoolOverflowReject rejects the new record when disk quota exceeded
+	SpoolOverflowReject SpoolOverflowPolicy = iota
+
+	// SpoolOverflowDropOldest deletes the oldest segments to make room for the new record,
+	// the undelivered records in them are lost
+	SpoolOverflowDropOldest
+)
+
+type SpoolConfig struct {
+	// Directory of segment files, it should be used by only one spool
+	Dir string
+
+	// Max bytes of one segment file, default 16MB,
+	// or MaxBytes if it's less than the default
+	SegmentBytes int64
+
+	// Disk quota of all segment files, 0 means no limit,
+	// it should not be less than SegmentBytes
+	MaxBytes int64
+
+	// What to do when the disk quota exceeded
+	OverflowPolicy SpoolOverflowPolicy
+
+	// Sync file after every append, which survives machine crash but slower
+	SyncWrite bool
+
+	// Backoff of resending the record which failed to send, DefaultBackoff if nil
+	RetryBackoff *Backoff
+}
+
+// SpoolSendFunc sends the payload appended to spool, the payload will be
+// sent again later if error returned, otherwise it will be acknowledged
+type SpoolSendFunc func(payload []byte) error
+
+// Record format in segment: | length(4) | crc32(4) | payload(length) |
+// The offsets of acknowledged records are appended to the ack file of segment.
+type spoolSegment struct {
+	seq     int64
+	path    string
+	ackPath string
+	size    int64
+	records int
+	acked   map[int64]bool
+	// file is only opened for the active segment, which is the last one
+	file *os.File
+}
+
+func (receiver *spoolSegment) fullyAcked() bool {
+	return receiver.records == len(receiver.acked)
+}
+
+func (receiver *spoolSegment) remove() {
+	if receiver.file != nil {
+		_ = receiver.file.Close()
+	}
+	if err := os.Remove(receiver.path); err != nil && !os.IsNotExist(err) {
+		logs.Warn("remove spool segment fail, path:%s err:%v", receiver.path, err)
+	}
+	if err := os.Remove(receiver.ackPath); err != nil && !os.IsNotExist(err) {
+		logs.Warn("remove spool ack fail, path:%s err:%v", receiver.ackPath, err)
+	}
+}
+
+// OpenSpool loads the existing segments in dir, the unacknowledged records
+// in them will be sent again in order, then the appended ones.
+func OpenSpool(config *SpoolConfig, send SpoolSendFunc) (*Spool, error) {
+	if config == nil || config.Dir == "" {
+		return nil, errors.New("spool dir is null")
+	}
+	spool := &Spool{
+		config: *config,
+		send:   send,
+		abort:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	maxBytes := spool.config.MaxBytes
+	if spool.config.SegmentBytes <= 0 {
+		spool.config.SegmentBytes = defaultSpoolSegmentBytes
+		if maxBytes > 0 && maxBytes < defaultSpoolSegmentBytes {
+			spool.config.SegmentBytes = maxBytes
+		}
+	}
+	if maxBytes > 0 && spool.config.SegmentBytes > maxBytes {
+		return nil, errors.New(fmt.Sprintf("spool segment bytes %d exceed max bytes %d",
+			spool.config.SegmentBytes, maxBytes))
+	}
+	spool.cond = sync.NewCond(&spool.lock)
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := spool.loadSegments(); err != nil {
+		return nil, err
+	}
+	AsyncExecute(spool.deliverLoop)
+	return spool, nil
+}
+
+// Spool is a write-ahead log of requests, appended records are persisted into
+// segmented local files before sent, and are sent one by one in order until
+// succeed, so that they survive process crash or long unavailability of server.
+// A segment is deleted when all records in it are acknowledged.
+type Spool struct {
+	config     SpoolConfig
+	send       SpoolSendFunc
+	lock       sync.Mutex
+	cond       *sync.Cond
+	segments   []*spoolSegment
+	totalBytes int64
+	nextSeq    int64
+	closed     bool
+	abort      chan struct{}
+	done       chan struct{}
+}
+
+func (receiver *Spool) loadSegments() error {
+	files, err := ioutil.ReadDir(receiver.config.Dir)
+	if err != nil {
+		return err
+	}
+	var seqs []int64
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), spoolSegmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseInt(strings.TrimSuffix(file.Name(), spoolSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		segment := receiver.newSegment(seq)
+		if err := receiver.scanSegment(segment); err != nil {
+			return err
+		}
+		receiver.nextSeq = seq + 1
+		if segment.fullyAcked() {
+			segment.remove()
+			continue
+		}
+		receiver.segments = append(receiver.segments, segment)
+		receiver.totalBytes += segment.size
+		logs.Info("load spool segment, path:%s records:%d acked:%d",
+			segment.path, segment.records, len(segment.acked))
+	}
+	return nil
+}
+
+func (receiver *Spool) newSegment(seq int64) *spoolSegment {
+	name := fmt.Sprintf("%020d", seq)
+	return &spoolSegment{
+		seq:     seq,
+		path:    filepath.Join(receiver.config.Dir, name+spoolSegmentSuffix),
+		ackPath: filepath.Join(receiver.config.Dir, name+spoolAckSuffix),
+		acked:   make(map[int64]bool),
+	}
+}
+
+// scanSegment counts the records of segment, the broken tail left by crash is truncated
+func (receiver *Spool) scanSegment(segment *spoolSegment) error {
+	file, err := os.Open(segment.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	fileSize := info.Size()
+	var offset int64
+	for {
+		_, next, err := readSpoolRecord(file, offset, fileSize)
+		if err != nil {
+			break
+		}
+		offset = next
+		segment.records++
+	}
+	_ = file.Close()
+	if fileSize > offset {
+		logs.Warn("truncate broken spool segment tail, path:%s size:%d valid:%d",
+			segment.path, fileSize, offset)
+		if err := os.Truncate(segment.path, offset); err != nil {
+			return err
+		}
+	}
+	segment.size = offset
+	ackBytes, err := ioutil.ReadFile(segment.ackPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := 0; i+spoolAckBytes <= len(ackBytes); i += spoolAckBytes {
+		segment.acked[int64(binary.BigEndian.Uint64(ackBytes[i:]))] = true
+	}
+	return nil
+}
+
+// readSpoolRecord reads the record at offset, which should end before limit,
+// the length of a broken header is checked before allocating payload
+func readSpoolRecord(file io.ReaderAt, offset int64, limit int64) ([]byte, int64, error) {
+	header := make([]byte, spoolRecordHeaderBytes)
+	if _, err := file.ReadAt(header, offset); err != nil {
+		return nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if offset+spoolRecordHeaderBytes+int64(length) > limit {
+		return nil, 0, errors.New("spool record exceeds segment")
+	}
+	payload := make([]byte, length)
+	if _, err := file.ReadAt(payload, offset+spoolRecordHeaderBytes); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, 0, errors.New("spool record checksum mismatch")
+	}
+	return payload, offset + spoolRecordHeaderBytes + int64(length), nil
+}
+
+// Append persists payload into spool, it will be sent asynchronously.
+// ErrSpoolFull is returned if disk quota exceeded with SpoolOverflowReject policy.
+func (receiver *Spool) Append(payload []byte) error {
+	recordBytes := int64(spoolRecordHeaderBytes + len(payload))
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.closed {
+		return ErrSpoolClosed
+	}
+	if err := receiver.ensureQuota(recordBytes); err != nil {
+		return err
+	}
+	active, err := receiver.activeSegment(recordBytes)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordBytes)
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:], crc32.ChecksumIEEE(payload))
+	copy(record[spoolRecordHeaderBytes:], payload)
+	if _, err := active.file.Write(record); err != nil {
+		return err
+	}
+	if receiver.config.SyncWrite {
+		if err := active.file.Sync(); err != nil {
+			return err
+		}
+	}
+	active.size += recordBytes
+	active.records++
+	receiver.totalBytes += recordBytes
+	receiver.cond.Broadcast()
+	return nil
+}
+
+func (receiver *Spool) ensureQuota(recordBytes int64) error {
+	maxBytes := receiver.config.MaxBytes
+	if maxBytes <= 0 || receiver.totalBytes+recordBytes <= maxBytes {
+		return nil
+	}
+	// The acknowledged records of active segment still take disk,
+	// it's sealed and deleted if all records in it are acknowledged
+	if count := len(receiver.segments); count > 0 {
+		if active := receiver.segments[count-1]; active.file != nil && active.records > 0 && active.fullyAcked() {
+			receiver.sealSegment(count - 1)
+		}
+	}
+	for receiver.totalBytes+recordBytes > maxBytes {
+		// the active segment is never dropped, it's still being written
+		if receiver.config.OverflowPolicy != SpoolOverflowDropOldest || len(receiver.segments) <= 1 {
+			return ErrSpoolFull
+		}
+		oldest := receiver.segments[0]
+		logs.Warn("spool disk quota exceeded, drop oldest segment, path:%s unacked:%d",
+			oldest.path, oldest.records-len(oldest.acked))
+		receiver.removeSegment(0)
+	}
+	return nil
+}
+
+// activeSegment returns the segment for appending, a new one is created
+// when the current active segment has no room for the record
+func (receiver *Spool) activeSegment(recordBytes int64) (*spoolSegment, error) {
+	count := len(receiver.segments)
+	if count > 0 {
+		active := receiver.segments[count-1]
+		if active.file != nil && (active.size == 0 || active.size+recordBytes <= receiver.config.SegmentBytes) {
+			return active, nil
+		}
+		receiver.sealSegment(count - 1)
+	}
+	segment := receiver.newSegment(receiver.nextSeq)
+	file, err := os.OpenFile(segment.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	receiver.nextSeq++
+	segment.file = file
+	receiver.segments = append(receiver.segments, segment)
+	return segment, nil
+}
+
+func (receiver *Spool) sealSegment(idx int) {
+	segment := receiver.segments[idx]
+	if segment.file != nil {
+		_ = segment.file.Close()
+		segment.file = nil
+	}
+	if segment.fullyAcked() {
+		receiver.removeSegment(idx)
+	}
+}
+
+func (receiver *Spool) removeSegment(idx int) {
+	segment := receiver.segments[idx]
+	segment.remove()
+	receiver.totalBytes -= segment.size
+	receiver.segments = append(receiver.segments[:idx], receiver.segments[idx+1:]...)
+}
+
+func (receiver *Spool) ack(seq int64, offset int64) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for idx, segment := range receiver.segments {
+		if segment.seq != seq {
+			continue
+		}
+		segment.acked[offset] = true
+		ackBytes := make([]byte, spoolAckBytes)
+		binary.BigEndian.PutUint64(ackBytes, uint64(offset))
+		if err := appendFile(segment.ackPath, ackBytes); err != nil {
+			// the record would be sent again after restart, it's acceptable
+			logs.Warn("persist spool ack fail, path:%s err:%v", segment.ackPath, err)
+		}
+		if segment.file == nil && segment.fullyAcked() {
+			receiver.removeSegment(idx)
+		}
+		return
+	}
+}
+
+func appendFile(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
+
+// nextRecord waits until the record after (seq, offset) is available,
+// false is returned if spool closed
+func (receiver *Spool) nextRecord(seq int64, offset int64) (*spoolSegment, int64, bool) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for !receiver.closed {
+		var segment *spoolSegment
+		for _, s := range receiver.segments {
+			if s.seq >= seq {
+				segment = s
+				break
+			}
+		}
+		if segment == nil {
+			receiver.cond.Wait()
+			continue
+		}
+		if segment.seq > seq {
+			seq, offset = segment.seq, 0
+		}
+		if offset < segment.size {
+			return segment, offset, true
+		}
+		if segment.file == nil && segment != receiver.segments[len(receiver.segments)-1] {
+			seq, offset = segment.seq+1, 0
+			continue
+		}
+		receiver.cond.Wait()
+	}
+	return nil, 0, false
+}
+
+func (receiver *Spool) deliverLoop() {
+	defer close(receiver.done)
+	var seq, offset int64
+	for {
+		segment, recordOffset, ok := receiver.nextRecord(seq, offset)
+		if !ok {
+			return
+		}
+		seq = segment.seq
+		payload, next, err := receiver.readRecord(segment, recordOffset)
+		if err != nil {
+			// the segment may be dropped by quota, skip to the next one
+			logs.Warn("read spool record fail, path:%s offset:%d err:%v", segment.path, recordOffset, err)
+			seq, offset = seq+1, 0
+			continue
+		}
+		offset = next
+		if payload == nil {
+			continue
+		}
+		if !receiver.deliver(payload) {
+			return
+		}
+		receiver.ack(seq, recordOffset)
+	}
+}
+
+// readRecord returns nil payload if the record was acknowledged
+func (receiver *Spool) readRecord(segment *spoolSegment, offset int64) ([]byte, int64, error) {
+	receiver.lock.Lock()
+	acked, size := segment.acked[offset], segment.size
+	receiver.lock.Unlock()
+	file, err := os.Open(segment.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	payload, next, err := readSpoolRecord(file, offset, size)
+	if err != nil || acked {
+		return nil, next, err
+	}
+	return payload, next, nil
+}
+
+// deliver sends payload until succeed, false is returned if spool closed
+func (receiver *Spool) deliver(payload []byte) bool {
+	for attempt := 0; ; attempt++ {
+		err := receiver.send(payload)
+		if err == nil {
+			return true
+		}
+		logs.Warn("send spooled record fail, attempt:%d err:%v", attempt, err)
+		if !receiver.config.RetryBackoff.Sleep(attempt, receiver.abort) {
+			return false
+		}
+	}
+}
+
+// Bytes returns the total bytes of segment files
+func (receiver *Spool) Bytes() int64 {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.totalBytes
+}
+
+// Pending returns the count of records not acknowledged yet
+func (receiver *Spool) Pending() int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	pending := 0
+	for _, segment := range receiver.segments {
+		pending += segment.records - len(segment.acked)
+	}
+	return pending
+}
+
+// Close stops sending, the unacknowledged records are kept
+// in segment files and will be sent after spool opened again
+func (receiver *Spool) Close() {
+	receiver.lock.Lock()
+	if !receiver.closed {
+		receiver.closed = true
+		close(receiver.abort)
+		receiver.cond.Broadcast()
+	}
+	receiver.lock.Unlock()
+	<-receiver.done
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for _, segment := range receiver.segments {
+		if segment.file != nil {
+			_ = segment.file.Close()
+			segment.file = nil
+		}
+	}
+}
+
+// SpoolEnvelope is the write request persisted in spool
+type SpoolEnvelope struct {
+	// The write method of request, e.g. "WriteUsers"
+	Method string `json:"method"`
+
+	// The topic of data, only used by general data writing
+	Topic string `json:"topic,omitempty"`
+
+	// Request id is generated when appended, so that the request
+	// sent again will be rejected as idempotent by server
+	RequestId string `json:"request_id"`
+
+	// Serialized request
+	Body []byte `json:"body"`
+}
+
+func (receiver *SpoolEnvelope) GetMethod() string {
+	if receiver == nil {
+		return ""
+	}
+	return receiver.Method
+}
+
+func EncodeSpoolEnvelope(envelope *SpoolEnvelope) ([]byte, error) {
+	return json.Marshal(envelope)
+}
+
+func DecodeSpoolEnvelope(payload []byte) (*SpoolEnvelope, error) {
+	envelope := &SpoolEnvelope{}
+	if err := json.Unmarshal(payload, envelope); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// SpoolSendResult converts the result of sending spooled request to the
+// result of SpoolSendFunc. Only the failure that could succeed by retrying
+// is returned, the request rejected permanently is logged and acknowledged,
+// otherwise it would block the later ones forever. The errors detected before
+// sending, such as FieldErrors of validation, are permanent, see IsPermanentError.
+func SpoolSendResult(envelope *SpoolEnvelope, status *protocol.Status, err error) error {
+	if err != nil && IsPermanentError(err) {
+		logs.Error("spooled request rejected by client, method:%s requestId:%s err:%s",
+			envelope.Method, envelope.RequestId, err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	code := status.GetCode()
+	if code == StatusCodeSuccess || code == StatusCodeIdempotent {
+		return nil
+	}
+	if IsRetryableStatus(code) {
+		return errors.New(fmt.Sprintf("%s fail, code:%d msg:%s", envelope.Method, code, status.GetMessage()))
+	}
+	logs.Error("spooled request rejected by server, method:%s requestId:%s code:%d msg:%s",
+		envelope.Method, envelope.RequestId, code, status.GetMessage())
+	return nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSpoolReplayAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	config := &SpoolConfig{Dir: dir, SegmentBytes: 64, RetryBackoff: &Backoff{Initial: time.Millisecond}}
+	failing := func(payload []byte) error {
+		return errors.New("unavailable")
+	}
+	spool, err := OpenSpool(config, failing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := spool.Append([]byte(fmt.Sprintf("record-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spool.Close()
+	if segments, _ := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentSuffix)); len(segments) < 2 {
+		t.Fatalf("expect multiple segments, got %d", len(segments))
+	}
+
+	var (
+		lock     sync.Mutex
+		received []string
+		sent     = make(chan struct{}, 10)
+	)
+	spool, err = OpenSpool(config, func(payload []byte) error {
+		lock.Lock()
+		received = append(received, string(payload))
+		lock.Unlock()
+		sent <- struct{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for replayed records, got %d", i)
+		}
+	}
+	spool.Close()
+	for i, record := range received {
+		if record != fmt.Sprintf("record-%d", i) {
+			t.Fatalf("unexpected record order, idx:%d record:%s", i, record)
+		}
+	}
+	if spool.Pending() != 0 {
+		t.Fatalf("expect no pending records, got %d", spool.Pending())
+	}
+	entries, _ := os.ReadDir(dir)
+	// only the active segment and its ack file may be kept
+	if len(entries) > 2 {
+		t.Fatalf("expect acknowledged segments deleted, got %d files", len(entries))
+	}
+}
+
+func TestSpoolQuota(t *testing.T) {
+	block := func(payload []byte) error {
+		return errors.New("unavailable")
+	}
+	config := &SpoolConfig{Dir: t.TempDir(), SegmentBytes: 32, MaxBytes: 64, RetryBackoff: &Backoff{Initial: time.Hour}}
+	spool, err := OpenSpool(config, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, 24)
+	for i := 0; i < 2; i++ {
+		if err := spool.Append(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := spool.Append(payload); err != ErrSpoolFull {
+		t.Fatalf("expect ErrSpoolFull, got %v", err)
+	}
+	spool.Close()
+
+	config = &SpoolConfig{Dir: t.TempDir(), SegmentBytes: 32, MaxBytes: 64,
+		OverflowPolicy: SpoolOverflowDropOldest, RetryBackoff: &Backoff{Initial: time.Hour}}
+	spool, err = OpenSpool(config, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+	for i := 0; i < 5; i++ {
+		if err := spool.Append(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if spool.Bytes() > config.MaxBytes {
+		t.Fatalf("expect bytes within quota, got %d", spool.Bytes())
+	}
+}
+
+func TestSpoolQuotaReusesAckedSegment(t *testing.T) {
+	sent := make(chan struct{}, 1)
+	config := &SpoolConfig{Dir: t.TempDir(), MaxBytes: 100}
+	spool, err := OpenSpool(config, func(payload []byte) error {
+		sent <- struct{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+	for i := 0; i < 10; i++ {
+		if err := spool.Append(make([]byte, 30)); err != nil {
+			t.Fatalf("append %d fail, err:%v", i, err)
+		}
+		<-sent
+		// wait for the record acknowledged
+		for spool.Pending() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	config = &SpoolConfig{Dir: t.TempDir(), SegmentBytes: 200, MaxBytes: 100}
+	if _, err := OpenSpool(config, nil); err == nil {
+		t.Fatal("segment bytes exceeding max bytes should be rejected")
+	}
+}
+
+func TestReadSpoolRecordBrokenLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken"+spoolSegmentSuffix)
+	// the length of header claims 4GB
+	if err := os.WriteFile(path, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 1}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, _, err := readSpoolRecord(file, 0, 9); err == nil {
+		t.Fatal("expect error for broken length")
+	}
+}
+
+func TestSpoolSendResultPermanentError(t *testing.T) {
+	envelope := &SpoolEnvelope{Method: "WriteData"}
+	invalid := FieldErrors{{Index: 0, Path: "typo", Message: "is unknown"}}
+	if err := SpoolSendResult(envelope, nil, invalid); err != nil {
+		t.Fatalf("invalid record should be acknowledged, got %v", err)
+	}
+	if err := SpoolSendResult(envelope, nil, NewPermanentError("too many items")); err != nil {
+		t.Fatalf("permanent error should be acknowledged, got %v", err)
+	}
+	if err := SpoolSendResult(envelope, nil, errors.New("timeout")); err == nil {
+		t.Fatal("network error should be retried")
+	}
+}
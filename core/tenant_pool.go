@@ -0,0 +1,228 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+)
+
+const (
+	defaultPoolIdleTimeout = 30 * time.Minute
+	maxPoolEvictInterval   = time.Minute
+)
+
+var ErrPoolClosed = errors.New("pool is closed")
+
+type TenantPoolConfig struct {
+	// The clients not got for IdleTimeout are released, default 30 minutes,
+	// negative means never
+	IdleTimeout time.Duration
+}
+
+// PooledClient is the client of products held by TenantPool
+type PooledClient interface {
+	Release()
+}
+
+// TransportFunc returns the transport shared by the clients of the same hosts as param
+type TransportFunc func(param *ContextParam) (*SharedTransport, error)
+
+// TenantBuildFunc builds the client of tenant with the transport got by `transport`
+type TenantBuildFunc func(tenant string, transport TransportFunc) (PooledClient, error)
+
+// NewTenantPool is used by the client pools of products,
+// which adapt their ClientBuilder into TenantBuildFunc
+func NewTenantPool(config *TenantPoolConfig, build TenantBuildFunc) *TenantPool {
+	pool := &TenantPool{
+		build:       build,
+		idleTimeout: defaultPoolIdleTimeout,
+		clients:     make(map[string]*pooledClient),
+		transports:  make(map[string]*SharedTransport),
+		stop:        make(chan struct{}),
+		now:         time.Now,
+	}
+	if config != nil && config.IdleTimeout != 0 {
+		pool.idleTimeout = config.IdleTimeout
+	}
+	if pool.idleTimeout > 0 {
+		AsyncExecute(pool.evictFunc())
+	}
+	return pool
+}
+
+// TenantPool lazily builds the clients of tenants, the clients of the same
+// hosts share one SharedTransport, which is closed once all its clients are evicted.
+type TenantPool struct {
+	lock        sync.Mutex
+	build       TenantBuildFunc
+	idleTimeout time.Duration
+	clients     map[string]*pooledClient
+	transports  map[string]*SharedTransport
+	closed      bool
+	stop        chan struct{}
+	// replaced in tests
+	now func() time.Time
+}
+
+type pooledClient struct {
+	client   PooledClient
+	lastUsed time.Time
+}
+
+// Get returns the client of tenant, which is built at the first call.
+// The client may be released once it's not got for IdleTimeout,
+// so it should be got again for every use instead of being held.
+func (receiver *TenantPool) Get(tenant string) (PooledClient, error) {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.closed {
+		return nil, ErrPoolClosed
+	}
+	if pooled, exist := receiver.clients[tenant]; exist {
+		pooled.lastUsed = receiver.now()
+		return pooled.client, nil
+	}
+	// Building client costs no network, it's done with lock held
+	// so that the transport could not be closed by eviction meanwhile
+	client, err := receiver.build(tenant, receiver.transport)
+	if err != nil {
+		return nil, err
+	}
+	receiver.clients[tenant] = &pooledClient{client: client, lastUsed: receiver.now()}
+	return client, nil
+}
+
+// Remove releases the client of tenant, e.g. when the token of tenant changed,
+// the next Get builds a new one
+func (receiver *TenantPool) Remove(tenant string) {
+	receiver.lock.Lock()
+	pooled, exist := receiver.clients[tenant]
+	delete(receiver.clients, tenant)
+	receiver.lock.Unlock()
+	if exist {
+		pooled.client.Release()
+	}
+}
+
+// Len returns the count of clients in pool
+func (receiver *TenantPool) Len() int {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return len(receiver.clients)
+}
+
+// Close releases all clients and closes all transports, Get fails after Close
+func (receiver *TenantPool) Close() {
+	receiver.lock.Lock()
+	if receiver.closed {
+		receiver.lock.Unlock()
+		return
+	}
+	receiver.closed = true
+	close(receiver.stop)
+	clients := receiver.clients
+	receiver.clients = make(map[string]*pooledClient)
+	receiver.lock.Unlock()
+	releasing := make([]PooledClient, 0, len(clients))
+	for _, pooled := range clients {
+		releasing = append(releasing, pooled.client)
+	}
+	releaseClients(releasing)
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for key, transport := range receiver.transports {
+		transport.Close()
+		delete(receiver.transports, key)
+	}
+}
+
+// transport is called by build with lock held
+func (receiver *TenantPool) transport(param *ContextParam) (*SharedTransport, error) {
+	key := transportKey(param)
+	if transport, exist := receiver.transports[key]; exist {
+		return transport, nil
+	}
+	// The headers of tenant are not used by the shared ping
+	transport, err := NewSharedTransport(&ContextParam{
+		Schema:     param.Schema,
+		HostHeader: param.HostHeader,
+		Hosts:      param.Hosts,
+		Region:     param.Region,
+		RecordMode: param.RecordMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	receiver.transports[key] = transport
+	return transport, nil
+}
+
+// releaseClients releases clients in parallel, as each Release may wait
+// for the in-flight calls of client, releasing them one by one costs the sum
+func releaseClients(clients []PooledClient) {
+	var wg sync.WaitGroup
+	wg.Add(len(clients))
+	for _, client := range clients {
+		release := client.Release
+		AsyncExecute(func() {
+			defer wg.Done()
+			release()
+		})
+	}
+	wg.Wait()
+}
+
+func transportKey(param *ContextParam) string {
+	return fmt.Sprintf("%d|%s|%s|%s|%d", param.Region, param.Schema,
+		param.HostHeader, strings.Join(param.Hosts, ","), param.RecordMode)
+}
+
+func (receiver *TenantPool) evictFunc() func() {
+	return func() {
+		interval := receiver.idleTimeout / 2
+		if interval > maxPoolEvictInterval {
+			interval = maxPoolEvictInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-receiver.stop:
+				return
+			case <-ticker.C:
+				receiver.evict()
+			}
+		}
+	}
+}
+
+// evict releases the clients idle for IdleTimeout,
+// then closes the transports no longer used
+func (receiver *TenantPool) evict() {
+	receiver.lock.Lock()
+	deadline := receiver.now().Add(-receiver.idleTimeout)
+	var idleClients []PooledClient
+	for tenant, pooled := range receiver.clients {
+		if pooled.lastUsed.Before(deadline) {
+			idleClients = append(idleClients, pooled.client)
+			delete(receiver.clients, tenant)
+		}
+	}
+	receiver.lock.Unlock()
+	if len(idleClients) > 0 {
+		logs.Info("release %d idle tenant clients", len(idleClients))
+	}
+	releaseClients(idleClients)
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	for key, transport := range receiver.transports {
+		if transport.Attached() == 0 {
+			transport.Close()
+			delete(receiver.transports, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeURLCenter struct {
+	host string
+}
+
+func (receiver *fakeURLCenter) Refresh(host string) {
+	receiver.host = host
+}
+
+type fakePooledClient struct {
+	availabler *HostAvailabler
+	released   int
+}
+
+func (receiver *fakePooledClient) Release() {
+	receiver.released++
+	receiver.availabler.Shutdown()
+}
+
+func TestTenantPool(t *testing.T) {
+	var built []*fakePooledClient
+	build := func(tenant string, transport TransportFunc) (PooledClient, error) {
+		param := &ContextParam{Tenant: tenant, TenantId: tenant, Token: "token",
+			UseAirAuth: true, Region: RegionSg, Hosts: []string{"127.0.0.1:1"}}
+		sharedTransport, err := transport(param)
+		if err != nil {
+			return nil, err
+		}
+		param.Transport = sharedTransport
+		context, err := NewContext(param)
+		if err != nil {
+			return nil, err
+		}
+		client := &fakePooledClient{availabler: NewHostAvailabler(&fakeURLCenter{}, context)}
+		built = append(built, client)
+		return client, nil
+	}
+	now := time.Unix(1000, 0)
+	pool := NewTenantPool(&TenantPoolConfig{IdleTimeout: -1}, build)
+	pool.idleTimeout = time.Minute
+	pool.now = func() time.Time { return now }
+
+	first, _ := pool.Get("a")
+	again, _ := pool.Get("a")
+	_, _ = pool.Get("b")
+	if first != again || len(built) != 2 {
+		t.Fatalf("client should be built once per tenant, built %d", len(built))
+	}
+	if len(pool.transports) != 1 || built[0].availabler.transport != built[1].availabler.transport {
+		t.Fatal("tenants of same hosts should share transport")
+	}
+	transport := built[0].availabler.transport
+	if transport.Attached() != 2 {
+		t.Fatalf("unexpected attached %d", transport.Attached())
+	}
+
+	now = now.Add(50 * time.Second)
+	_, _ = pool.Get("b")
+	now = now.Add(20 * time.Second)
+	pool.evict()
+	if pool.Len() != 1 || built[0].released != 1 || transport.Attached() != 1 {
+		t.Fatalf("idle tenant should be released, len:%d attached:%d", pool.Len(), transport.Attached())
+	}
+
+	pool.Close()
+	pool.Close()
+	if built[1].released != 1 || transport.Attached() != 0 || !transport.closed || len(pool.transports) != 0 {
+		t.Fatal("all clients and transports should be released by Close")
+	}
+	if _, err := pool.Get("a"); err != ErrPoolClosed {
+		t.Fatalf("expect ErrPoolClosed, got %v", err)
+	}
+}
+
+type slowPooledClient struct {
+	delay time.Duration
+}
+
+func (receiver *slowPooledClient) Release() {
+	time.Sleep(receiver.delay)
+}
+
+func TestTenantPoolCloseReleasesInParallel(t *testing.T) {
+	build := func(tenant string, transport TransportFunc) (PooledClient, error) {
+		return &slowPooledClient{delay: 200 * time.Millisecond}, nil
+	}
+	pool := NewTenantPool(&TenantPoolConfig{IdleTimeout: -1}, build)
+	for _, tenant := range []string{"a", "b", "c", "d", "e"} {
+		_, _ = pool.Get(tenant)
+	}
+	start := time.Now()
+	pool.Close()
+	if cost := time.Since(start); cost > 600*time.Millisecond {
+		t.Errorf("clients should be released in parallel, cost %v", cost)
+	}
+}
@@ -0,0 +1,385 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeInt    FieldType = "int"
+	FieldTypeFloat  FieldType = "float"
+	FieldTypeBool   FieldType = "bool"
+	// Unix timestamp in seconds, time.Time and RFC3339 strings are converted
+	FieldTypeTimestamp  FieldType = "timestamp"
+	FieldTypeStringList FieldType = "string_list"
+	FieldTypeIntList    FieldType = "int_list"
+	FieldTypeFloatList  FieldType = "float_list"
+	// Any json value, it's not checked or converted
+	FieldTypeAny FieldType = "any"
+)
+
+type FieldSchema struct {
+	Name     string    `json:"name"`
+	Type     FieldType `json:"type"`
+	Required bool      `json:"required,omitempty"`
+	// Allowed values of string or int field, or elements of list field
+	Enum []string `json:"enum,omitempty"`
+	// Max rune count of string, or max element count of list, 0 means no limit
+	MaxLength int `json:"max_length,omitempty"`
+}
+
+// TopicSchema declares the fields of the data of a general or byteair topic,
+// the data is checked and converted by it before sent.
+type TopicSchema struct {
+	Topic  string         `json:"topic"`
+	Fields []*FieldSchema `json:"fields"`
+	// Whether the fields not declared are allowed, they're sent as they are
+	AllowUnknown bool `json:"allow_unknown,omitempty"`
+
+	fields map[string]*FieldSchema
+	enums  map[string]map[string]bool
+}
+
+// LoadTopicSchemas reads the json file of a schema list, such as
+// [{"topic":"user","fields":[{"name":"user_id","type":"string","required":true}]}]
+func LoadTopicSchemas(path string) ([]*TopicSchema, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schemas []*TopicSchema
+	if err := json.Unmarshal(content, &schemas); err != nil {
+		return nil, err
+	}
+	for _, schema := range schemas {
+		if err := schema.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+}
+
+// IndexTopicSchemas validates schemas, and indexes them by topic
+func IndexTopicSchemas(schemas []*TopicSchema) (map[string]*TopicSchema, error) {
+	result := make(map[string]*TopicSchema, len(schemas))
+	for _, schema := range schemas {
+		if err := schema.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exist := result[schema.Topic]; exist {
+			return nil, errors.New("schema of topic is duplicate: " + schema.Topic)
+		}
+		result[schema.Topic] = schema
+	}
+	return result, nil
+}
+
+// Validate checks the declaration of schema, it should be called
+// before Coerce if schema is declared in code
+func (receiver *TopicSchema) Validate() error {
+	if receiver.Topic == "" {
+		return errors.New("topic of schema is empty")
+	}
+	receiver.fields = make(map[string]*FieldSchema, len(receiver.Fields))
+	receiver.enums = make(map[string]map[string]bool)
+	for _, field := range receiver.Fields {
+		if field.Name == "" {
+			return errors.New(fmt.Sprintf("field name is empty, topic:%s", receiver.Topic))
+		}
+		if _, exist := receiver.fields[field.Name]; exist {
+			return errors.New(fmt.Sprintf("field is duplicate, topic:%s field:%s", receiver.Topic, field.Name))
+		}
+		switch field.Type {
+		case FieldTypeString, FieldTypeInt, FieldTypeFloat, FieldTypeBool, FieldTypeTimestamp,
+			FieldTypeStringList, FieldTypeIntList, FieldTypeFloatList, FieldTypeAny:
+		default:
+			return errors.New(fmt.Sprintf("unknown field type, topic:%s field:%s type:%s",
+				receiver.Topic, field.Name, field.Type))
+		}
+		receiver.fields[field.Name] = field
+		if len(field.Enum) > 0 {
+			enum := make(map[string]bool, len(field.Enum))
+			for _, value := range field.Enum {
+				enum[value] = true
+			}
+			receiver.enums[field.Name] = enum
+		}
+	}
+	return nil
+}
+
+// CoerceAll checks every data, and converts the values into the declared types.
+// The data of caller is not modified, the converted copies are returned.
+// FieldErrors is returned if any field is invalid.
+func (receiver *TopicSchema) CoerceAll(dataList []map[string]interface{}) ([]map[string]interface{}, error) {
+	if receiver.fields == nil {
+		if err := receiver.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	var fieldErrors FieldErrors
+	result := make([]map[string]interface{}, len(dataList))
+	for i, data := range dataList {
+		var errs FieldErrors
+		result[i], errs = receiver.Coerce(i, data)
+		fieldErrors = append(fieldErrors, errs...)
+	}
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+	return result, nil
+}
+
+// Coerce checks and converts one data, index is reported in FieldError
+func (receiver *TopicSchema) Coerce(index int, data map[string]interface{}) (map[string]interface{}, FieldErrors) {
+	var fieldErrors FieldErrors
+	addError := func(path string, format string, args ...interface{}) {
+		fieldErrors = append(fieldErrors, &FieldError{Index: index, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	// sorted, so that the errors are reported in stable order
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make(map[string]interface{}, len(data))
+	for _, name := range names {
+		value := data[name]
+		field, ok := receiver.fields[name]
+		if !ok {
+			if !receiver.AllowUnknown {
+				addError(name, "unknown field of topic %s", receiver.Topic)
+			}
+			result[name] = value
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		coerced, err := receiver.coerceField(field, value, addError)
+		if err != nil {
+			continue
+		}
+		result[name] = coerced
+	}
+	for _, field := range receiver.Fields {
+		if value, exist := data[field.Name]; field.Required && (!exist || value == nil) {
+			addError(field.Name, "required field is missing")
+		}
+	}
+	return result, fieldErrors
+}
+
+func (receiver *TopicSchema) coerceField(field *FieldSchema, value interface{},
+	addError func(path string, format string, args ...interface{})) (interface{}, error) {
+	elementType := listElementType(field.Type)
+	if elementType == "" {
+		coerced, err := coerceValue(field.Type, value)
+		if err == nil {
+			err = receiver.checkLimits(field, coerced)
+		}
+		if err != nil {
+			addError(field.Name, "%s", err.Error())
+		}
+		return coerced, err
+	}
+	elements := reflect.ValueOf(value)
+	if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+		err := errors.New(fmt.Sprintf("expect %s, got %T", field.Type, value))
+		addError(field.Name, "%s", err.Error())
+		return nil, err
+	}
+	if field.MaxLength > 0 && elements.Len() > field.MaxLength {
+		err := errors.New(fmt.Sprintf("length %d exceeds max length %d", elements.Len(), field.MaxLength))
+		addError(field.Name, "%s", err.Error())
+		return nil, err
+	}
+	var firstErr error
+	coercedList := make([]interface{}, elements.Len())
+	for i := 0; i < elements.Len(); i++ {
+		coerced, err := coerceValue(elementType, elements.Index(i).Interface())
+		if err == nil {
+			err = receiver.checkEnum(field, coerced)
+		}
+		if err != nil {
+			addError(fmt.Sprintf("%s[%d]", field.Name, i), "%s", err.Error())
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		coercedList[i] = coerced
+	}
+	return coercedList, firstErr
+}
+
+func (receiver *TopicSchema) checkLimits(field *FieldSchema, value interface{}) error {
+	if str, ok := value.(string); ok && field.MaxLength > 0 && utf8.RuneCountInString(str) > field.MaxLength {
+		return errors.New(fmt.Sprintf("length %d exceeds max length %d", utf8.RuneCountInString(str), field.MaxLength))
+	}
+	return receiver.checkEnum(field, value)
+}
+
+func (receiver *TopicSchema) checkEnum(field *FieldSchema, value interface{}) error {
+	enum, ok := receiver.enums[field.Name]
+	if !ok {
+		return nil
+	}
+	str := fmt.Sprint(value)
+	if !enum[str] {
+		return errors.New(fmt.Sprintf("value %s is not one of %s", str, strings.Join(field.Enum, ",")))
+	}
+	return nil
+}
+
+func listElementType(fieldType FieldType) FieldType {
+	switch fieldType {
+	case FieldTypeStringList:
+		return FieldTypeString
+	case FieldTypeIntList:
+		return FieldTypeInt
+	case FieldTypeFloatList:
+		return FieldTypeFloat
+	default:
+		return ""
+	}
+}
+
+func coerceValue(fieldType FieldType, value interface{}) (interface{}, error) {
+	switch fieldType {
+	case FieldTypeString:
+		return coerceString(value)
+	case FieldTypeInt:
+		return coerceInt(value)
+	case FieldTypeFloat:
+		return coerceFloat(value)
+	case FieldTypeBool:
+		return coerceBool(value)
+	case FieldTypeTimestamp:
+		return coerceTimestamp(value)
+	default:
+		return value, nil
+	}
+}
+
+func coerceString(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return nil, errors.New(fmt.Sprintf("expect string, got %T", value))
+}
+
+func coerceInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("expect int, got string %q", v))
+		}
+		return parsed, nil
+	case json.Number:
+		return coerceInt(v.String())
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, errors.New(fmt.Sprintf("int %d overflows", rv.Uint()))
+		}
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		// the numbers decoded by encoding/json are float64
+		if f := rv.Float(); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int64(f), nil
+		}
+		return nil, errors.New(fmt.Sprintf("expect int, got float %v", rv.Float()))
+	}
+	return nil, errors.New(fmt.Sprintf("expect int, got %T", value))
+}
+
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("expect float, got string %q", v))
+		}
+		return parsed, nil
+	case json.Number:
+		return coerceFloat(v.String())
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return nil, errors.New(fmt.Sprintf("expect float, got %T", value))
+}
+
+func coerceBool(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("expect bool, got string %q", v))
+		}
+		return parsed, nil
+	}
+	return nil, errors.New(fmt.Sprintf("expect bool, got %T", value))
+}
+
+func coerceTimestamp(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Unix(), nil
+	case *time.Time:
+		if v == nil {
+			return nil, errors.New("expect timestamp, got nil")
+		}
+		return v.Unix(), nil
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(v)); err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+	timestamp, err := coerceInt(value)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("expect timestamp, got %T %v", value, value))
+	}
+	return timestamp, nil
+}
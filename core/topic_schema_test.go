@@ -0,0 +1,63 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTopicSchemaCoerceAll(t *testing.T) {
+	schema := &TopicSchema{
+		Topic: "user",
+		Fields: []*FieldSchema{
+			{Name: "user_id", Type: FieldTypeString, Required: true, MaxLength: 8},
+			{Name: "age", Type: FieldTypeInt},
+			{Name: "gender", Type: FieldTypeString, Enum: []string{"male", "female"}},
+			{Name: "register_time", Type: FieldTypeTimestamp},
+			{Name: "scores", Type: FieldTypeFloatList},
+		},
+	}
+	registerTime := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	valid := []map[string]interface{}{{
+		"user_id":       "u1",
+		"age":           "18",
+		"gender":        "male",
+		"register_time": registerTime,
+		"scores":        []interface{}{json.Number("1.5"), 2},
+	}}
+	coerced, err := schema.CoerceAll(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coerced[0]["age"] != int64(18) || coerced[0]["register_time"] != registerTime.Unix() ||
+		coerced[0]["scores"].([]interface{})[1] != float64(2) {
+		t.Errorf("unexpected coerced data: %v", coerced[0])
+	}
+	if valid[0]["age"] != "18" {
+		t.Errorf("data of caller should not be modified")
+	}
+
+	invalid := []map[string]interface{}{
+		{"user_id": "u1"},
+		{"user_id": "too_long_id", "age": "x", "gender": "unknown", "nmae": "typo", "scores": []string{"a"}},
+		{"age": 1.5},
+	}
+	_, err = schema.CoerceAll(invalid)
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("expect FieldErrors, got %v", err)
+	}
+	expected := []string{"[1].age", "[1].gender", "[1].nmae", "[1].scores[0]", "[1].user_id", "[2].age", "[2].user_id"}
+	if len(fieldErrors) != len(expected) {
+		t.Fatalf("unexpected errors: %v", fieldErrors)
+	}
+	for i, fieldError := range fieldErrors {
+		if path := fmt.Sprintf("[%d].%s", fieldError.Index, fieldError.Path); path != expected[i] {
+			t.Errorf("unexpected error %d: %s", i, fieldError.Error())
+		}
+	}
+	if indexes := fieldErrors.Indexes(); len(indexes) != 2 || indexes[0] != 1 || indexes[1] != 2 {
+		t.Errorf("unexpected invalid indexes: %v", indexes)
+	}
+}
@@ -0,0 +1,227 @@
+package facade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/byteplus-sdk/sdk-go/byteair"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/general"
+	"github.com/byteplus-sdk/sdk-go/retail"
+	"github.com/byteplus-sdk/sdk-go/retailv2"
+	"github.com/byteplus-sdk/sdk-go/saas"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultItemTopic     = "item"
+	defaultEventTopic    = "user_event"
+	defaultItemIdField   = "item_id"
+	defaultTrafficSource = "byteplus"
+)
+
+// Recommender is the product-neutral interface of the recommendation clients,
+// the shared recommendation layer could switch products by configuration.
+type Recommender interface {
+	// WriteEvents writes any count of user events in chunks
+	WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error)
+
+	// WriteItems writes any count of items in chunks, the fields of item are named
+	// as the product expects, such as "product_id" and "price" for retail,
+	// the nested messages of retail and saas are nested maps.
+	WriteItems(items []map[string]interface{}, opts ...option.Option) (*WriteResult, error)
+
+	// Predict gets the ranked items, the failure status is returned as error
+	Predict(request *PredictRequest, opts ...option.Option) (*PredictResult, error)
+
+	// ReportImpressions sends back the items actually shown, which is
+	// AckServerImpressions of retail and saas, or Callback of general and byteair
+	ReportImpressions(report *ImpressionReport, opts ...option.Option) error
+}
+
+type Config struct {
+	// Max count of write requests sent at the same time, default 1
+	Concurrency int
+
+	// The project, stage and model of saas
+	ProjectId string
+	Stage     string
+	ModelId   string
+
+	// The topics written by general and byteair, default "item" and "user_event"
+	ItemTopic  string
+	EventTopic string
+	// The field of item id in the data of general and byteair, default "item_id"
+	ItemIdField string
+
+	// The traffic source reported by retail and saas, default "byteplus"
+	TrafficSource string
+}
+
+// Event is a user event, the product-specific fields are set by Fields
+type Event struct {
+	UserId    string
+	EventType string
+	ItemId    string
+	// Unix timestamp in seconds
+	Timestamp int64
+	Scene     string
+	// The other fields named as the product expects, such as
+	// {"device": {"platform": "app"}} for retail
+	Fields map[string]interface{}
+}
+
+type WriteResult struct {
+	// The Index of failure is the position in the items passed
+	Failures []*ItemFailure
+}
+
+type PredictRequest struct {
+	UserId string
+	Scene  string
+	// Max count of items returned, 0 means the default of scene
+	Size int
+	// Only these items are ranked if not empty
+	CandidateItemIds []string
+	Extra            map[string]string
+}
+
+type PredictResult struct {
+	RequestId string
+	Items     []*PredictedItem
+}
+
+type PredictedItem struct {
+	Id   string
+	Rank int
+	// The predicted ctr, 0 if the product doesn't return it
+	Score float64
+	// The rec_info of retail and saas, or the trans_data of general and byteair,
+	// which should be passed back with the later user events
+	TransData string
+	Extra     map[string]string
+}
+
+// The reasons of the items shown
+const (
+	ImpressionKept     = "kept"
+	ImpressionFiltered = "filtered"
+	ImpressionInserted = "inserted"
+)
+
+type ImpressionReport struct {
+	PredictRequestId string
+	UserId           string
+	Scene            string
+	Items            []*ImpressionItem
+}
+
+type ImpressionItem struct {
+	Id     string
+	Reason string
+	// The final rank of item, 0 if it's filtered
+	Rank int
+}
+
+// NewRecommender creates the adapter of client by its product,
+// client should be the Client of retail, retailv2, saas, general or byteair
+func NewRecommender(client interface{}, config *Config) (Recommender, error) {
+	switch productClient := client.(type) {
+	case retail.Client:
+		return NewRetailRecommender(productClient, config), nil
+	case retailv2.Client:
+		return NewRetailV2Recommender(productClient, config), nil
+	case saas.Client:
+		return NewSaasRecommender(productClient, config)
+	case general.Client:
+		return NewGeneralRecommender(productClient, config), nil
+	case byteair.Client:
+		return NewByteairRecommender(productClient, config), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported client %T", client))
+	}
+}
+
+func withDefaults(config *Config) Config {
+	result := Config{}
+	if config != nil {
+		result = *config
+	}
+	if result.Concurrency <= 0 {
+		result.Concurrency = 1
+	}
+	if result.ItemTopic == "" {
+		result.ItemTopic = defaultItemTopic
+	}
+	if result.EventTopic == "" {
+		result.EventTopic = defaultEventTopic
+	}
+	if result.ItemIdField == "" {
+		result.ItemIdField = defaultItemIdField
+	}
+	if result.TrafficSource == "" {
+		result.TrafficSource = defaultTrafficSource
+	}
+	return result
+}
+
+// eventData converts event into the data of product, the scene of retail
+// and saas is a message, which is a nested map
+func eventData(event *Event, itemIdField string, sceneMessage bool) map[string]interface{} {
+	data := make(map[string]interface{}, len(event.Fields)+5)
+	for name, value := range event.Fields {
+		data[name] = value
+	}
+	data["user_id"] = event.UserId
+	data["event_type"] = event.EventType
+	data["event_timestamp"] = event.Timestamp
+	if event.ItemId != "" {
+		data[itemIdField] = event.ItemId
+	}
+	if event.Scene != "" && sceneMessage {
+		data["scene"] = map[string]interface{}{"scene_name": event.Scene}
+	} else if event.Scene != "" {
+		data["scene"] = event.Scene
+	}
+	return data
+}
+
+// dataToMessage decodes data into message by the proto field names
+func dataToMessage(data map[string]interface{}, message proto.Message) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(bytes, message)
+}
+
+// convertItems calls convert for every item, the items failed to convert are
+// reported as failures, and the indexes of the converted items are returned
+func convertItems(count int, convert func(index int) error) ([]int, []*ItemFailure) {
+	converted := make([]int, 0, count)
+	var failures []*ItemFailure
+	for i := 0; i < count; i++ {
+		if err := convert(i); err != nil {
+			failures = append(failures, &ItemFailure{Index: i, Message: err.Error()})
+			continue
+		}
+		converted = append(converted, i)
+	}
+	return converted, failures
+}
+
+// originalIndex converts the index in the converted items into the index in items passed
+func originalIndex(converted []int, index int) int {
+	if index < 0 || index >= len(converted) {
+		return -1
+	}
+	return converted[index]
+}
+
+func impressionReasonExtra(reason string) string {
+	bytes, _ := json.Marshal(map[string]string{"reason": reason})
+	return string(bytes)
+}
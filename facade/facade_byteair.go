@@ -0,0 +1,107 @@
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/byteplus-sdk/sdk-go/byteair"
+	byteairpb "github.com/byteplus-sdk/sdk-go/byteair/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// NewByteairRecommender writes items and events into the
+// ItemTopic and EventTopic of config
+func NewByteairRecommender(client byteair.Client, config *Config) Recommender {
+	return &byteairRecommender{client: client, config: withDefaults(config)}
+}
+
+type byteairRecommender struct {
+	client byteair.Client
+	config Config
+}
+
+func (receiver *byteairRecommender) WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error) {
+	dataList := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		dataList[i] = eventData(event, receiver.config.ItemIdField, false)
+	}
+	return receiver.write(dataList, receiver.config.EventTopic, opts)
+}
+
+func (receiver *byteairRecommender) WriteItems(items []map[string]interface{},
+	opts ...option.Option) (*WriteResult, error) {
+	return receiver.write(items, receiver.config.ItemTopic, opts)
+}
+
+func (receiver *byteairRecommender) write(dataList []map[string]interface{}, topic string,
+	opts []option.Option) (*WriteResult, error) {
+	response, err := byteair.ChunkWriteData(receiver.client, dataList, topic, receiver.config.Concurrency, opts...)
+	result := &WriteResult{}
+	for _, dataError := range response.Errors {
+		result.Failures = append(result.Failures, &ItemFailure{Index: dataError.Index,
+			Item: dataError.GetData(), Message: dataError.GetMessage()})
+	}
+	return result, err
+}
+
+func (receiver *byteairRecommender) Predict(request *PredictRequest,
+	opts ...option.Option) (*PredictResult, error) {
+	predictRequest := &byteairpb.PredictRequest{
+		User:  &byteairpb.PredictUser{Uid: request.UserId},
+		Size:  int32(request.Size),
+		Extra: &byteairpb.PredictExtra{Extra: request.Extra},
+	}
+	for _, itemId := range request.CandidateItemIds {
+		predictRequest.CandidateItems = append(predictRequest.CandidateItems,
+			&byteairpb.PredictCandidateItem{Id: itemId})
+	}
+	// the scene of byteair is passed by option
+	predictOpts := make([]option.Option, 0, len(opts)+1)
+	predictOpts = append(predictOpts, opts...)
+	if request.Scene != "" {
+		predictOpts = append(predictOpts, option.WithScene(request.Scene))
+	}
+	response, err := receiver.client.Predict(predictRequest, predictOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetCode() != StatusCodeSuccess {
+		return nil, errors.New(fmt.Sprintf("[Predict] fail, code:%d msg:%s",
+			response.GetCode(), response.GetMessage()))
+	}
+	result := &PredictResult{RequestId: response.GetRequestId()}
+	for _, item := range response.GetValue().GetItems() {
+		result.Items = append(result.Items, &PredictedItem{
+			Id:        item.GetId(),
+			Rank:      int(item.GetRank()),
+			TransData: item.GetTransData(),
+			Extra:     item.GetExtra(),
+		})
+	}
+	return result, nil
+}
+
+func (receiver *byteairRecommender) ReportImpressions(report *ImpressionReport, opts ...option.Option) error {
+	request := &byteairpb.CallbackRequest{
+		Uid:              report.UserId,
+		Scene:            report.Scene,
+		PredictRequestId: report.PredictRequestId,
+	}
+	for _, item := range report.Items {
+		request.Items = append(request.Items, &byteairpb.CallbackItem{
+			Id:    item.Id,
+			Pos:   strconv.Itoa(item.Rank),
+			Extra: impressionReasonExtra(item.Reason),
+		})
+	}
+	response, err := receiver.client.Callback(request, opts...)
+	if err != nil {
+		return err
+	}
+	if response.GetCode() != StatusCodeSuccess {
+		return errors.New(fmt.Sprintf("[Callback] fail, code:%d msg:%s", response.GetCode(), response.GetMessage()))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/general"
+	generalpb "github.com/byteplus-sdk/sdk-go/general/protocol"
+)
+
+// NewGeneralRecommender writes items and events into the
+// ItemTopic and EventTopic of config
+func NewGeneralRecommender(client general.Client, config *Config) Recommender {
+	return &generalRecommender{client: client, config: withDefaults(config)}
+}
+
+type generalRecommender struct {
+	client general.Client
+	config Config
+}
+
+func (receiver *generalRecommender) WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error) {
+	dataList := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		dataList[i] = eventData(event, receiver.config.ItemIdField, false)
+	}
+	return receiver.write(dataList, receiver.config.EventTopic, opts)
+}
+
+func (receiver *generalRecommender) WriteItems(items []map[string]interface{},
+	opts ...option.Option) (*WriteResult, error) {
+	return receiver.write(items, receiver.config.ItemTopic, opts)
+}
+
+func (receiver *generalRecommender) write(dataList []map[string]interface{}, topic string,
+	opts []option.Option) (*WriteResult, error) {
+	response, err := general.ChunkWriteData(receiver.client, dataList, topic, receiver.config.Concurrency, opts...)
+	result := &WriteResult{}
+	for _, dataError := range response.Errors {
+		result.Failures = append(result.Failures, &ItemFailure{Index: dataError.Index,
+			Item: dataError.GetData(), Message: dataError.GetMessage()})
+	}
+	return result, err
+}
+
+func (receiver *generalRecommender) Predict(request *PredictRequest,
+	opts ...option.Option) (*PredictResult, error) {
+	predictRequest := &generalpb.PredictRequest{
+		User:  &generalpb.PredictUser{Uid: request.UserId},
+		Size:  int32(request.Size),
+		Extra: &generalpb.PredictExtra{Extra: request.Extra},
+	}
+	for _, itemId := range request.CandidateItemIds {
+		predictRequest.CandidateItems = append(predictRequest.CandidateItems,
+			&generalpb.PredictCandidateItem{Id: itemId})
+	}
+	response, err := receiver.client.Predict(predictRequest, request.Scene, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetCode() != StatusCodeSuccess {
+		return nil, errors.New(fmt.Sprintf("[Predict] fail, code:%d msg:%s",
+			response.GetCode(), response.GetMessage()))
+	}
+	result := &PredictResult{RequestId: response.GetRequestId()}
+	for _, item := range response.GetValue().GetItems() {
+		result.Items = append(result.Items, &PredictedItem{
+			Id:        item.GetId(),
+			Rank:      int(item.GetRank()),
+			TransData: item.GetTransData(),
+			Extra:     item.GetExtra(),
+		})
+	}
+	return result, nil
+}
+
+func (receiver *generalRecommender) ReportImpressions(report *ImpressionReport, opts ...option.Option) error {
+	request := &generalpb.CallbackRequest{
+		Uid:              report.UserId,
+		Scene:            report.Scene,
+		PredictRequestId: report.PredictRequestId,
+	}
+	for _, item := range report.Items {
+		request.Items = append(request.Items, &generalpb.CallbackItem{
+			Id:    item.Id,
+			Pos:   strconv.Itoa(item.Rank),
+			Extra: impressionReasonExtra(item.Reason),
+		})
+	}
+	response, err := receiver.client.Callback(request, opts...)
+	if err != nil {
+		return err
+	}
+	if response.GetCode() != StatusCodeSuccess {
+		return errors.New(fmt.Sprintf("[Callback] fail, code:%d msg:%s", response.GetCode(), response.GetMessage()))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package facade
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/retail"
+	retailpb "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+func NewRetailRecommender(client retail.Client, config *Config) Recommender {
+	return &retailRecommender{client: client, config: withDefaults(config)}
+}
+
+type retailRecommender struct {
+	client retail.Client
+	config Config
+}
+
+func (receiver *retailRecommender) WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error) {
+	userEvents := make([]*retailpb.UserEvent, 0, len(events))
+	converted, failures := convertItems(len(events), func(index int) error {
+		userEvent := &retailpb.UserEvent{}
+		if err := dataToMessage(eventData(events[index], "product_id", true), userEvent); err != nil {
+			return err
+		}
+		userEvents = append(userEvents, userEvent)
+		return nil
+	})
+	request := &retailpb.WriteUserEventsRequest{UserEvents: userEvents}
+	response, err := retail.ChunkWriteUserEvents(receiver.client, request, receiver.config.Concurrency, opts...)
+	for _, userEventError := range response.Errors {
+		failures = append(failures, &ItemFailure{Index: originalIndex(converted, userEventError.Index),
+			Item: userEventError.GetUserEvent(), Message: userEventError.GetMessage()})
+	}
+	return &WriteResult{Failures: failures}, err
+}
+
+func (receiver *retailRecommender) WriteItems(items []map[string]interface{},
+	opts ...option.Option) (*WriteResult, error) {
+	products := make([]*retailpb.Product, 0, len(items))
+	converted, failures := convertItems(len(items), func(index int) error {
+		product := &retailpb.Product{}
+		if err := dataToMessage(items[index], product); err != nil {
+			return err
+		}
+		products = append(products, product)
+		return nil
+	})
+	request := &retailpb.WriteProductsRequest{Products: products}
+	response, err := retail.ChunkWriteProducts(receiver.client, request, receiver.config.Concurrency, opts...)
+	for _, productError := range response.Errors {
+		failures = append(failures, &ItemFailure{Index: originalIndex(converted, productError.Index),
+			Item: productError.GetProduct(), Message: productError.GetMessage()})
+	}
+	return &WriteResult{Failures: failures}, err
+}
+
+func (receiver *retailRecommender) Predict(request *PredictRequest,
+	opts ...option.Option) (*PredictResult, error) {
+	predictRequest := &retailpb.PredictRequest{
+		UserId:  request.UserId,
+		Size:    int32(request.Size),
+		Scene:   &retailpb.UserEvent_Scene{SceneName: request.Scene},
+		Context: &retailpb.PredictRequest_Context{CandidateProductIds: request.CandidateItemIds},
+		Extra:   request.Extra,
+	}
+	response, err := receiver.client.Predict(predictRequest, request.Scene, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return nil, errors.New(fmt.Sprintf("[Predict] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	result := &PredictResult{RequestId: response.GetRequestId()}
+	for _, product := range response.GetValue().GetResponseProducts() {
+		result.Items = append(result.Items, &PredictedItem{
+			Id:        product.GetProductId(),
+			Rank:      int(product.GetRank()),
+			Score:     product.GetPctr(),
+			TransData: product.GetRecInfo(),
+			Extra:     product.GetExtra(),
+		})
+	}
+	return result, nil
+}
+
+func (receiver *retailRecommender) ReportImpressions(report *ImpressionReport, opts ...option.Option) error {
+	request := &retailpb.AckServerImpressionsRequest{
+		PredictRequestId: report.PredictRequestId,
+		UserId:           report.UserId,
+		TrafficSource:    receiver.config.TrafficSource,
+		Scene:            &retailpb.UserEvent_Scene{SceneName: report.Scene},
+	}
+	for _, item := range report.Items {
+		request.AlteredProducts = append(request.AlteredProducts, &retailpb.AckServerImpressionsRequest_AlteredProduct{
+			ProductId:     item.Id,
+			AlteredReason: item.Reason,
+			Rank:          int32(item.Rank),
+		})
+	}
+	response, err := receiver.client.AckServerImpressions(request, opts...)
+	if err != nil {
+		return err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return errors.New(fmt.Sprintf("[AckImpressions] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package facade
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/retailv2"
+	retailv2pb "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+func NewRetailV2Recommender(client retailv2.Client, config *Config) Recommender {
+	return &retailV2Recommender{client: client, config: withDefaults(config)}
+}
+
+type retailV2Recommender struct {
+	client retailv2.Client
+	config Config
+}
+
+func (receiver *retailV2Recommender) WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error) {
+	userEvents := make([]*retailv2pb.UserEvent, 0, len(events))
+	converted, failures := convertItems(len(events), func(index int) error {
+		userEvent := &retailv2pb.UserEvent{}
+		if err := dataToMessage(eventData(events[index], "product_id", true), userEvent); err != nil {
+			return err
+		}
+		userEvents = append(userEvents, userEvent)
+		return nil
+	})
+	request := &retailv2pb.WriteUserEventsRequest{UserEvents: userEvents}
+	response, err := retailv2.ChunkWriteUserEvents(receiver.client, request, receiver.config.Concurrency, opts...)
+	for _, userEventError := range response.Errors {
+		failures = append(failures, &ItemFailure{Index: originalIndex(converted, userEventError.Index),
+			Item: userEventError.GetUserEvent(), Message: userEventError.GetMessage()})
+	}
+	return &WriteResult{Failures: failures}, err
+}
+
+func (receiver *retailV2Recommender) WriteItems(items []map[string]interface{},
+	opts ...option.Option) (*WriteResult, error) {
+	products := make([]*retailv2pb.Product, 0, len(items))
+	converted, failures := convertItems(len(items), func(index int) error {
+		product := &retailv2pb.Product{}
+		if err := dataToMessage(items[index], product); err != nil {
+			return err
+		}
+		products = append(products, product)
+		return nil
+	})
+	request := &retailv2pb.WriteProductsRequest{Products: products}
+	response, err := retailv2.ChunkWriteProducts(receiver.client, request, receiver.config.Concurrency, opts...)
+	for _, productError := range response.Errors {
+		failures = append(failures, &ItemFailure{Index: originalIndex(converted, productError.Index),
+			Item: productError.GetProduct(), Message: productError.GetMessage()})
+	}
+	return &WriteResult{Failures: failures}, err
+}
+
+func (receiver *retailV2Recommender) Predict(request *PredictRequest,
+	opts ...option.Option) (*PredictResult, error) {
+	predictRequest := &retailv2pb.PredictRequest{
+		UserId:  request.UserId,
+		Size:    int32(request.Size),
+		Scene:   &retailv2pb.UserEvent_Scene{SceneName: request.Scene},
+		Context: &retailv2pb.PredictRequest_Context{CandidateProductIds: request.CandidateItemIds},
+		Extra:   request.Extra,
+	}
+	response, err := receiver.client.Predict(predictRequest, request.Scene, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return nil, errors.New(fmt.Sprintf("[Predict] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	result := &PredictResult{RequestId: response.GetRequestId()}
+	for _, product := range response.GetValue().GetResponseProducts() {
+		result.Items = append(result.Items, &PredictedItem{
+			Id:        product.GetProductId(),
+			Rank:      int(product.GetRank()),
+			Score:     product.GetPctr(),
+			TransData: product.GetRecInfo(),
+			Extra:     product.GetExtra(),
+		})
+	}
+	return result, nil
+}
+
+func (receiver *retailV2Recommender) ReportImpressions(report *ImpressionReport, opts ...option.Option) error {
+	request := &retailv2pb.AckServerImpressionsRequest{
+		PredictRequestId: report.PredictRequestId,
+		UserId:           report.UserId,
+		TrafficSource:    receiver.config.TrafficSource,
+		Scene:            &retailv2pb.UserEvent_Scene{SceneName: report.Scene},
+	}
+	for _, item := range report.Items {
+		request.AlteredProducts = append(request.AlteredProducts, &retailv2pb.AckServerImpressionsRequest_AlteredProduct{
+			ProductId:     item.Id,
+			AlteredReason: item.Reason,
+			Rank:          int32(item.Rank),
+		})
+	}
+	response, err := receiver.client.AckServerImpressions(request, opts...)
+	if err != nil {
+		return err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return errors.New(fmt.Sprintf("[AckImpressions] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	return nil
+}
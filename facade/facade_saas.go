@@ -0,0 +1,126 @@
+package facade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas"
+	saaspb "github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// NewSaasRecommender requires the ProjectId and Stage of config,
+// and ModelId is required by Predict and ReportImpressions
+func NewSaasRecommender(client saas.Client, config *Config) (Recommender, error) {
+	recommender := &saasRecommender{client: client, config: withDefaults(config)}
+	if recommender.config.ProjectId == "" || recommender.config.Stage == "" {
+		return nil, errors.New("project id and stage are required by saas")
+	}
+	return recommender, nil
+}
+
+type saasRecommender struct {
+	client saas.Client
+	config Config
+}
+
+func (receiver *saasRecommender) WriteEvents(events []*Event, opts ...option.Option) (*WriteResult, error) {
+	dataList := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		dataList[i] = eventData(event, "product_id", true)
+	}
+	return receiver.write(saas.ChunkWriteUserEvents, dataList, opts)
+}
+
+func (receiver *saasRecommender) WriteItems(items []map[string]interface{},
+	opts ...option.Option) (*WriteResult, error) {
+	return receiver.write(saas.ChunkWriteProducts, items, opts)
+}
+
+type saasChunkWriteFunc func(client saas.Client, request *saaspb.WriteDataRequest, concurrency int,
+	opts ...option.Option) (*saas.ChunkWriteResponse, error)
+
+func (receiver *saasRecommender) write(chunkWrite saasChunkWriteFunc, dataList []map[string]interface{},
+	opts []option.Option) (*WriteResult, error) {
+	encoded := make([]string, 0, len(dataList))
+	converted, failures := convertItems(len(dataList), func(index int) error {
+		bytes, err := json.Marshal(dataList[index])
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, string(bytes))
+		return nil
+	})
+	request := &saaspb.WriteDataRequest{
+		ProjectId: receiver.config.ProjectId,
+		Stage:     receiver.config.Stage,
+		Data:      encoded,
+	}
+	response, err := chunkWrite(receiver.client, request, receiver.config.Concurrency, opts...)
+	for _, dataError := range response.Errors {
+		failures = append(failures, &ItemFailure{Index: originalIndex(converted, dataError.Index),
+			Item: dataError.GetData(), Message: dataError.GetMessage()})
+	}
+	return &WriteResult{Failures: failures}, err
+}
+
+func (receiver *saasRecommender) Predict(request *PredictRequest,
+	opts ...option.Option) (*PredictResult, error) {
+	predictRequest := &saaspb.PredictRequest{
+		ProjectId: receiver.config.ProjectId,
+		ModelId:   receiver.config.ModelId,
+		UserId:    request.UserId,
+		Size:      int32(request.Size),
+		Scene:     &saaspb.Scene{SceneName: request.Scene},
+		Context:   &saaspb.PredictRequest_Context{CandidateProductIds: request.CandidateItemIds},
+		Extra:     request.Extra,
+	}
+	response, err := receiver.client.Predict(predictRequest, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return nil, errors.New(fmt.Sprintf("[Predict] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	result := &PredictResult{RequestId: response.GetRequestId()}
+	for _, product := range response.GetValue().GetResponseProducts() {
+		result.Items = append(result.Items, &PredictedItem{
+			Id:        product.GetProductId(),
+			Rank:      int(product.GetRank()),
+			Score:     product.GetPctr(),
+			TransData: product.GetRecInfo(),
+			Extra:     product.GetExtra(),
+		})
+	}
+	return result, nil
+}
+
+func (receiver *saasRecommender) ReportImpressions(report *ImpressionReport, opts ...option.Option) error {
+	request := &saaspb.AckServerImpressionsRequest{
+		ProjectId:        receiver.config.ProjectId,
+		ModelId:          receiver.config.ModelId,
+		PredictRequestId: report.PredictRequestId,
+		UserId:           report.UserId,
+		TrafficSource:    receiver.config.TrafficSource,
+		Scene:            &saaspb.Scene{SceneName: report.Scene},
+	}
+	for _, item := range report.Items {
+		request.AlteredProducts = append(request.AlteredProducts, &saaspb.AckServerImpressionsRequest_AlteredProduct{
+			ProductId:     item.Id,
+			AlteredReason: item.Reason,
+			Rank:          int32(item.Rank),
+		})
+	}
+	response, err := receiver.client.AckServerImpressions(request, opts...)
+	if err != nil {
+		return err
+	}
+	if response.GetStatus().GetCode() != StatusCodeSuccess {
+		return errors.New(fmt.Sprintf("[AckImpressions] fail, code:%d msg:%s",
+			response.GetStatus().GetCode(), response.GetStatus().GetMessage()))
+	}
+	return nil
+}
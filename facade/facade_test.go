@@ -0,0 +1,60 @@
+package facade
+
+import (
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/retail"
+	retailpb "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+type fakeRetailClient struct {
+	retail.Client
+	written []*retailpb.UserEvent
+}
+
+func (receiver *fakeRetailClient) WriteUserEvents(request *retailpb.WriteUserEventsRequest,
+	_ ...option.Option) (*retailpb.WriteUserEventsResponse, error) {
+	receiver.written = append(receiver.written, request.GetUserEvents()...)
+	response := &retailpb.WriteUserEventsResponse{Status: &Status{Code: StatusCodeSuccess}}
+	for _, userEvent := range request.GetUserEvents() {
+		if userEvent.GetUserId() == "rejected" {
+			response.Status.Code = 1001
+			response.Errors = append(response.Errors,
+				&retailpb.UserEventError{Message: "invalid user", UserEvent: userEvent})
+		}
+	}
+	return response, nil
+}
+
+func TestRetailWriteEvents(t *testing.T) {
+	client := &fakeRetailClient{}
+	recommender, err := NewRecommender(client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*Event{
+		{UserId: "u1", EventType: "click", ItemId: "p1", Timestamp: 1, Scene: "home"},
+		{UserId: "u2", EventType: "click", Timestamp: 1, Fields: map[string]interface{}{"device": "app"}},
+		{UserId: "rejected", EventType: "click", ItemId: "p3", Timestamp: 1},
+	}
+	result, err := recommender.WriteEvents(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.written) != 2 || client.written[0].GetScene().GetSceneName() != "home" ||
+		client.written[0].GetProductId() != "p1" {
+		t.Fatalf("unexpected written events %v", client.written)
+	}
+	if len(result.Failures) != 2 || result.Failures[0].Index != 1 || result.Failures[1].Index != 2 {
+		t.Fatalf("unexpected failures %v", result.Failures)
+	}
+}
+
+func TestNewRecommenderUnsupported(t *testing.T) {
+	if _, err := NewRecommender("client", nil); err == nil {
+		t.Fatal("expect error for unsupported client")
+	}
+}
@@ -1,7 +1,7 @@
 package general
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -16,18 +16,52 @@ import (
 
 var (
 	errMsgFormat    = "Only can receive max to %d items in one request"
-	TooManyItemsErr = errors.New(fmt.Sprintf(errMsgFormat, MaxImportItemCount))
+	TooManyItemsErr = NewPermanentError(fmt.Sprintf(errMsgFormat, MaxImportItemCount))
 )
 
+func init() {
+	common.RegisterOperationResponseType(&ImportResponse{})
+}
+
 type clientImpl struct {
 	common.Client
 	hCaller *HttpCaller
 	gu      *generalURL
 	hostAva *HostAvailabler
+	scenes  *SceneRegistry
+	schemas map[string]*TopicSchema
+	// drain the components depending on client when released
+	hooks ReleaseHooks
 }
 
 func (c *clientImpl) Release() {
-	c.hostAva.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReleaseTimeout)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		logs.Warn("release client fail, err:%s", err.Error())
+	}
+}
+
+func (c *clientImpl) Close(ctx context.Context) error {
+	return CloseClient(ctx, &c.hooks, c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) Diagnostics() *Diagnostics {
+	return NewDiagnostics(c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) SceneRegistry() *SceneRegistry {
+	return c.scenes
+}
+
+// coerceData checks and converts data by the schema of topic,
+// the data is returned as it is if topic has no schema
+func (c *clientImpl) coerceData(dataList []map[string]interface{}, topic string) ([]map[string]interface{}, error) {
+	schema, ok := c.schemas[topic]
+	if !ok {
+		return dataList, nil
+	}
+	return schema.CoerceAll(dataList)
 }
 
 func (c *clientImpl) WriteData(dataList []map[string]interface{}, topic string,
@@ -38,10 +72,14 @@ func (c *clientImpl) WriteData(dataList []map[string]interface{}, topic string,
 			return nil, TooManyItemsErr
 		}
 	}
+	dataList, err := c.coerceData(dataList, topic)
+	if err != nil {
+		return nil, err
+	}
 	urlFormat := c.gu.writeDataURLFormat
 	url := strings.ReplaceAll(urlFormat, "{}", topic)
 	response := &WriteResponse{}
-	err := c.hCaller.DoJsonRequest(url, dataList, response, option.Conv2Options(opts...))
+	err = c.hCaller.DoJsonRequest(url, dataList, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -54,10 +92,14 @@ func (c *clientImpl) ImportData(dataList []map[string]interface{},
 	if len(dataList) > MaxImportItemCount {
 		return nil, TooManyItemsErr
 	}
+	dataList, err := c.coerceData(dataList, topic)
+	if err != nil {
+		return nil, err
+	}
 	urlFormat := c.gu.importDataURLFormat
 	url := strings.ReplaceAll(urlFormat, "{}", topic)
 	response := &OperationResponse{}
-	err := c.hCaller.DoJsonRequest(url, dataList, response, option.Conv2Options(opts...))
+	err = c.hCaller.DoJsonRequest(url, dataList, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +116,7 @@ func (c *clientImpl) Done(dateList []time.Time,
 	urlFormat := c.gu.doneURLFormat
 	url := strings.ReplaceAll(urlFormat, "{}", topic)
 	response := &DoneResponse{}
-	err := c.hCaller.DoJsonRequest(url, dateMaps, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoJsonRequest(url, dateMaps, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -90,10 +132,24 @@ func (c *clientImpl) appendDoneDate(dateMaps []map[string]string,
 
 func (c *clientImpl) Predict(request *PredictRequest,
 	scene string, opts ...option.Option) (*PredictResponse, error) {
+	var response *PredictResponse
+	err := c.scenes.Execute(scene, func(sceneConf *SceneConfig) error {
+		var err error
+		response, err = c.doPredict(ApplySceneDefaults(request, sceneConf).(*PredictRequest), sceneConf, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *clientImpl) doPredict(request *PredictRequest, scene *SceneConfig,
+	opts []option.Option) (*PredictResponse, error) {
 	urlFormat := c.gu.predictUrlFormat
-	url := strings.ReplaceAll(urlFormat, "{}", scene)
+	url := strings.ReplaceAll(urlFormat, "{}", scene.Name)
 	response := &PredictResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(scene.WithOptions(opts)...))
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +161,7 @@ func (c *clientImpl) Callback(request *CallbackRequest,
 	opts ...option.Option) (*CallbackResponse, error) {
 	url := c.gu.callbackURL
 	response := &CallbackResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
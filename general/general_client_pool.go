@@ -0,0 +1,41 @@
+package general
+
+import (
+	. "github.com/byteplus-sdk/sdk-go/core"
+)
+
+// NewClientPool
+//
+// Creates a pool which builds the client of tenant by the builder returned by
+// newBuilder at the first Get. The clients of the same region share one transport,
+// so the tenants don't spawn their own ping goroutines and connection pools.
+func NewClientPool(config *TenantPoolConfig, newBuilder func(tenant string) (*ClientBuilder, error)) *ClientPool {
+	build := func(tenant string, transport TransportFunc) (PooledClient, error) {
+		builder, err := newBuilder(tenant)
+		if err != nil {
+			return nil, err
+		}
+		sharedTransport, err := transport(&builder.param)
+		if err != nil {
+			return nil, err
+		}
+		return builder.Transport(sharedTransport).Build()
+	}
+	return &ClientPool{TenantPool: NewTenantPool(config, build)}
+}
+
+// ClientPool holds the clients of tenants, the clients unused for the idle
+// timeout of config are released. Remove, Len and Close are those of TenantPool.
+type ClientPool struct {
+	*TenantPool
+}
+
+// Get returns the client of tenant, which should be got again for every use
+// instead of being held, as it may be released once idle.
+func (receiver *ClientPool) Get(tenant string) (Client, error) {
+	client, err := receiver.TenantPool.Get(tenant)
+	if err != nil {
+		return nil, err
+	}
+	return client.(Client), nil
+}
@@ -0,0 +1,51 @@
+package general
+
+import (
+	"reflect"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+// NewResubmitter
+//
+// Creates a resubmitter which resends the retryable failed data of writes with backoff,
+// and routes the permanent failures into the sink of config.
+func NewResubmitter(client Client, config *ResubmitConfig) *Resubmitter {
+	return &Resubmitter{client: client, runner: NewResubmitRunner(config)}
+}
+
+// Resubmitter writes at most 100 data at a time like Client, the opts of
+// writes should not contain request id, as every attempt needs a new one
+type Resubmitter struct {
+	client Client
+	runner *ResubmitRunner
+}
+
+func (receiver *Resubmitter) WriteData(dataList []map[string]interface{}, topic string,
+	opts ...option.Option) (*ResubmitReport, error) {
+	item := func(index int) interface{} { return dataList[index] }
+	normalizedData := make(map[int]interface{}, len(dataList))
+	send := func(indexes []int, requestId string) (*Status, []*ItemFailure, error) {
+		subset := make([]map[string]interface{}, len(indexes))
+		for i, index := range indexes {
+			subset[i] = dataList[index]
+		}
+		response, err := receiver.client.WriteData(subset, topic, ResubmitOptions(opts, requestId)...)
+		dataErrors := response.GetErrors()
+		matched := MatchResubmitIndexes(indexes, len(dataErrors), func(failedIdx, itemIdx int) bool {
+			if _, exist := normalizedData[itemIdx]; !exist {
+				normalizedData[itemIdx] = NormalizeJson(dataList[itemIdx])
+			}
+			failed := NormalizeJsonString(dataErrors[failedIdx].GetData())
+			return failed != nil && reflect.DeepEqual(failed, normalizedData[itemIdx])
+		})
+		failures := make([]*ItemFailure, len(dataErrors))
+		for i, dataError := range dataErrors {
+			failures[i] = &ItemFailure{Index: matched[i], Item: dataError.GetData(), Message: dataError.GetMessage()}
+		}
+		return response.GetStatus(), failures, err
+	}
+	return receiver.runner.Run(topic, len(dataList), item, send)
+}
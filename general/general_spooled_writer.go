@@ -0,0 +1,100 @@
+package general
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/google/uuid"
+)
+
+const spoolMethodWriteData = "WriteData"
+
+// NewSpooledWriter opens the spool in config.Dir, the requests that were
+// not sent successfully before last exit will be sent again through client.
+// The spool is closed when client released, or it could be closed by caller.
+// The opts are used by every request, so it should not contain request id.
+func NewSpooledWriter(client Client, config *SpoolConfig, opts ...option.Option) (*SpooledWriter, error) {
+	writer := &SpooledWriter{client: client, opts: opts}
+	spool, err := OpenSpool(config, writer.send)
+	if err != nil {
+		return nil, err
+	}
+	writer.spool = spool
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer, nil
+}
+
+// SpooledWriter persists write requests into local spool before sending them,
+// the request is deleted from spool after server returns success.
+// Requests with more than 100 items are split into chunks.
+type SpooledWriter struct {
+	client Client
+	opts   []option.Option
+	spool  *Spool
+}
+
+func (receiver *SpooledWriter) WriteData(dataList []map[string]interface{}, topic string) error {
+	for _, chunk := range SplitChunks(len(dataList), MaxWriteItemCount) {
+		body, err := json.Marshal(dataList[chunk.Start:chunk.End])
+		if err != nil {
+			return err
+		}
+		payload, err := EncodeSpoolEnvelope(&SpoolEnvelope{
+			Method:    spoolMethodWriteData,
+			Topic:     topic,
+			RequestId: uuid.NewString(),
+			Body:      body,
+		})
+		if err != nil {
+			return err
+		}
+		if err := receiver.spool.Append(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the count of requests not sent successfully
+func (receiver *SpooledWriter) Pending() int {
+	return receiver.spool.Pending()
+}
+
+// Close stops sending, the unsent requests will be sent after reopened
+func (receiver *SpooledWriter) Close() {
+	receiver.spool.Close()
+}
+
+func (receiver *SpooledWriter) send(payload []byte) error {
+	envelope, err := DecodeSpoolEnvelope(payload)
+	if err != nil {
+		return skipSpooledRequest(nil, err)
+	}
+	if envelope.Method != spoolMethodWriteData {
+		return skipSpooledRequest(envelope, errors.New("unknown method"))
+	}
+	var dataList []map[string]interface{}
+	// keep numbers as they were, avoid losing precision of large integers
+	decoder := json.NewDecoder(bytes.NewReader(envelope.Body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&dataList); err != nil {
+		return skipSpooledRequest(envelope, err)
+	}
+	opts := make([]option.Option, 0, len(receiver.opts)+1)
+	opts = append(opts, receiver.opts...)
+	opts = append(opts, option.WithRequestId(envelope.RequestId))
+	response, err := receiver.client.WriteData(dataList, envelope.Topic, opts...)
+	return SpoolSendResult(envelope, response.GetStatus(), err)
+}
+
+// The broken request never succeeds, it's skipped to avoid blocking the later ones
+func skipSpooledRequest(envelope *SpoolEnvelope, err error) error {
+	logs.Error("skip broken spooled request, method:%s err:%s", envelope.GetMethod(), err.Error())
+	return nil
+}
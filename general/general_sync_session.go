@@ -0,0 +1,62 @@
+package general
+
+import (
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/general/protocol"
+)
+
+func NewSyncSession(client Client, topic string, date time.Time,
+	store common.SyncStateStore) (*SyncSession, error) {
+	session, err := common.NewSyncSession(client, topic, date, store)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncSession{SyncSession: session, client: client}, nil
+}
+
+// SyncSession stamps the writes and imports of a topic with the date,
+// the end of date is only sent by Finalize after all imports done.
+type SyncSession struct {
+	*common.SyncSession
+	client Client
+}
+
+func (receiver *SyncSession) WriteData(dataList []map[string]interface{},
+	opts ...option.Option) (*WriteResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return receiver.client.WriteData(dataList, receiver.Topic(), opts...)
+}
+
+// ImportData imports data of the date, and tracks the returned operation
+func (receiver *SyncSession) ImportData(dataList []map[string]interface{},
+	opts ...option.Option) (*OperationResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	response, err := receiver.client.ImportData(dataList, receiver.Topic(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := receiver.Track(response); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// Finalize sends an empty import request with the date end header,
+// then calls Done, see common.SyncSession.Finalize
+func (receiver *SyncSession) Finalize(policy *common.WaitPolicy) error {
+	return receiver.SyncSession.Finalize(func(opts []option.Option) (*OperationResponse, error) {
+		return receiver.client.ImportData([]map[string]interface{}{}, receiver.Topic(), opts...)
+	}, policy)
+}
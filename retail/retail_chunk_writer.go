@@ -0,0 +1,172 @@
+package retail
+
+import (
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// IndexedUserError is UserError with the index of user in the
+// caller's WriteUsersRequest.Users, Index is -1 if it can't be matched
+type IndexedUserError struct {
+	Index int
+	*UserError
+}
+
+type ChunkWriteUsersResponse struct {
+	// The first failure status of chunks, or success status if all chunks succeed
+	Status *Status
+	Errors []*IndexedUserError
+}
+
+// IndexedProductError is ProductError with the index of product in the
+// caller's WriteProductsRequest.Products, Index is -1 if it can't be matched
+type IndexedProductError struct {
+	Index int
+	*ProductError
+}
+
+type ChunkWriteProductsResponse struct {
+	// The first failure status of chunks, or success status if all chunks succeed
+	Status *Status
+	Errors []*IndexedProductError
+}
+
+// IndexedUserEventError is UserEventError with the index of user event in the
+// caller's WriteUserEventsRequest.UserEvents, Index is -1 if it can't be matched
+type IndexedUserEventError struct {
+	Index int
+	*UserEventError
+}
+
+type ChunkWriteUserEventsResponse struct {
+	// The first failure status of chunks, or success status if all chunks succeed
+	Status *Status
+	Errors []*IndexedUserEventError
+}
+
+// ChunkWriteUsers
+//
+// Splits users into chunks of at most 100 users, writes the chunks with at most
+// `concurrency` goroutines, then merges the responses of all chunks.
+// If a whole chunk fails, every user of it is reported in response errors,
+// and the first chunk error is returned together with the merged response.
+func ChunkWriteUsers(client Client, request *WriteUsersRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteUsersResponse, error) {
+	users := request.GetUsers()
+	responses := make([]*WriteUsersResponse, ChunkCount(len(users), MaxWriteItemCount))
+	result := &ChunkWriteUsersResponse{}
+	var err error
+	result.Status, err = WriteChunks(len(users), MaxWriteItemCount, concurrency, opts, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*Status, int, error) {
+			chunkRequest := &WriteUsersRequest{Users: users[chunk.Start:chunk.End], Extra: request.GetExtra()}
+			response, err := client.WriteUsers(chunkRequest, opts...)
+			responses[chunk.Index] = response
+			return response.GetStatus(), len(response.GetErrors()), err
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			for i := chunk.Start; i < chunk.End; i++ {
+				userError := &UserError{Message: message, User: users[i]}
+				result.Errors = append(result.Errors, &IndexedUserError{Index: i, UserError: userError})
+			}
+		},
+		CollectErrors: func(chunk Chunk) {
+			userErrors := responses[chunk.Index].GetErrors()
+			indexes := MatchIndexes(chunk, len(userErrors), func(failedIdx, itemIdx int) bool {
+				failed, user := userErrors[failedIdx].GetUser(), users[itemIdx]
+				return failed.GetUserId() == user.GetUserId() || proto.Equal(failed, user)
+			})
+			for i, userError := range userErrors {
+				result.Errors = append(result.Errors, &IndexedUserError{Index: indexes[i], UserError: userError})
+			}
+		},
+	})
+	return result, err
+}
+
+// ChunkWriteProducts
+//
+// Splits products into chunks of at most 100 products, writes the chunks with at most
+// `concurrency` goroutines, then merges the responses of all chunks.
+// If a whole chunk fails, every product of it is reported in response errors,
+// and the first chunk error is returned together with the merged response.
+func ChunkWriteProducts(client Client, request *WriteProductsRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteProductsResponse, error) {
+	products := request.GetProducts()
+	responses := make([]*WriteProductsResponse, ChunkCount(len(products), MaxWriteItemCount))
+	result := &ChunkWriteProductsResponse{}
+	var err error
+	result.Status, err = WriteChunks(len(products), MaxWriteItemCount, concurrency, opts, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*Status, int, error) {
+			chunkRequest := &WriteProductsRequest{Products: products[chunk.Start:chunk.End], Extra: request.GetExtra()}
+			response, err := client.WriteProducts(chunkRequest, opts...)
+			responses[chunk.Index] = response
+			return response.GetStatus(), len(response.GetErrors()), err
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			for i := chunk.Start; i < chunk.End; i++ {
+				productError := &ProductError{Message: message, Product: products[i]}
+				result.Errors = append(result.Errors, &IndexedProductError{Index: i, ProductError: productError})
+			}
+		},
+		CollectErrors: func(chunk Chunk) {
+			productErrors := responses[chunk.Index].GetErrors()
+			indexes := MatchIndexes(chunk, len(productErrors), func(failedIdx, itemIdx int) bool {
+				failed, product := productErrors[failedIdx].GetProduct(), products[itemIdx]
+				return failed.GetProductId() == product.GetProductId() || proto.Equal(failed, product)
+			})
+			for i, productError := range productErrors {
+				result.Errors = append(result.Errors, &IndexedProductError{Index: indexes[i], ProductError: productError})
+			}
+		},
+	})
+	return result, err
+}
+
+// ChunkWriteUserEvents
+//
+// Splits user events into chunks of at most 100 user events, writes the chunks with at most
+// `concurrency` goroutines, then merges the responses of all chunks.
+// If a whole chunk fails, every user event of it is reported in response errors,
+// and the first chunk error is returned together with the merged response.
+func ChunkWriteUserEvents(client Client, request *WriteUserEventsRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteUserEventsResponse, error) {
+	userEvents := request.GetUserEvents()
+	responses := make([]*WriteUserEventsResponse, ChunkCount(len(userEvents), MaxWriteItemCount))
+	result := &ChunkWriteUserEventsResponse{}
+	var err error
+	result.Status, err = WriteChunks(len(userEvents), MaxWriteItemCount, concurrency, opts, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*Status, int, error) {
+			chunkRequest := &WriteUserEventsRequest{UserEvents: userEvents[chunk.Start:chunk.End], Extra: request.GetExtra()}
+			response, err := client.WriteUserEvents(chunkRequest, opts...)
+			responses[chunk.Index] = response
+			return response.GetStatus(), len(response.GetErrors()), err
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			for i := chunk.Start; i < chunk.End; i++ {
+				userEventError := &UserEventError{Message: message, UserEvent: userEvents[i]}
+				result.Errors = append(result.Errors, &IndexedUserEventError{Index: i, UserEventError: userEventError})
+			}
+		},
+		CollectErrors: func(chunk Chunk) {
+			userEventErrors := responses[chunk.Index].GetErrors()
+			indexes := MatchIndexes(chunk, len(userEventErrors), func(failedIdx, itemIdx int) bool {
+				failed, userEvent := userEventErrors[failedIdx].GetUserEvent(), userEvents[itemIdx]
+				return isSameUserEvent(failed, userEvent) || proto.Equal(failed, userEvent)
+			})
+			for i, userEventError := range userEventErrors {
+				result.Errors = append(result.Errors, &IndexedUserEventError{Index: indexes[i], UserEventError: userEventError})
+			}
+		},
+	})
+	return result, err
+}
+
+func isSameUserEvent(a *UserEvent, b *UserEvent) bool {
+	return a.GetUserId() == b.GetUserId() &&
+		a.GetEventType() == b.GetEventType() &&
+		a.GetEventTimestamp() == b.GetEventTimestamp() &&
+		a.GetProductId() == b.GetProductId()
+}
@@ -0,0 +1,61 @@
+package retail
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+// fakeClient rejects the users whose id is in `rejected`,
+// and fails the whole chunk whose first user id is in `chunkFailed`
+type fakeClient struct {
+	Client
+	rejected    map[string]bool
+	chunkFailed map[string]bool
+}
+
+func (c *fakeClient) WriteUsers(request *WriteUsersRequest,
+	opts ...option.Option) (*WriteUsersResponse, error) {
+	if c.chunkFailed[request.Users[0].UserId] {
+		return nil, errors.New("timeout")
+	}
+	response := &WriteUsersResponse{Status: &Status{Code: 0}}
+	for _, user := range request.Users {
+		if c.rejected[user.UserId] {
+			// server returns a copy of the user
+			failed := &User{UserId: user.UserId, Gender: user.Gender}
+			response.Errors = append(response.Errors, &UserError{Message: "invalid", User: failed})
+		}
+	}
+	return response, nil
+}
+
+func TestChunkWriteUsers(t *testing.T) {
+	users := make([]*User, 250)
+	for i := range users {
+		users[i] = &User{UserId: strconv.Itoa(i)}
+	}
+	client := &fakeClient{
+		rejected:    map[string]bool{"5": true, "150": true, "249": true},
+		chunkFailed: map[string]bool{"200": true},
+	}
+	response, err := ChunkWriteUsers(client, &WriteUsersRequest{Users: users}, 2)
+	if err == nil {
+		t.Errorf("ChunkWriteUsers() should return the chunk error")
+	}
+	failedIndexes := make(map[int]bool)
+	for _, userError := range response.Errors {
+		if userError.Index < 0 || users[userError.Index].UserId != userError.User.UserId {
+			t.Errorf("user error %v mapped to wrong index %d", userError.User, userError.Index)
+		}
+		failedIndexes[userError.Index] = true
+	}
+	// 2 rejected users and 50 users of the failed chunk
+	if len(failedIndexes) != 52 || !failedIndexes[5] || !failedIndexes[150] || !failedIndexes[249] {
+		t.Errorf("ChunkWriteUsers() failed indexes = %v", failedIndexes)
+	}
+}
@@ -1,7 +1,7 @@
 package retail
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,21 +15,46 @@ import (
 
 var (
 	writeMsgFormat  = "Only can receive max to %d items in one write request"
-	writeTooManyErr = errors.New(fmt.Sprintf(writeMsgFormat, MaxWriteItemCount))
+	writeTooManyErr = NewPermanentError(fmt.Sprintf(writeMsgFormat, MaxWriteItemCount))
 
 	importMsgFormat  = "Only can receive max to %d items in one import request"
-	importTooManyErr = errors.New(fmt.Sprintf(importMsgFormat, MaxImportItemCount))
+	importTooManyErr = NewPermanentError(fmt.Sprintf(importMsgFormat, MaxImportItemCount))
 )
 
+func init() {
+	common.RegisterOperationResponseType(&ImportUsersResponse{}, &ImportProductsResponse{}, &ImportUserEventsResponse{})
+}
+
 type clientImpl struct {
 	common.Client
 	hCaller *HttpCaller
 	ru      *retailURL
 	hostAva *HostAvailabler
+	scenes  *SceneRegistry
+	// validate requests before sending, see ClientBuilder.ValidateRequests
+	validate bool
+	// drain the components depending on client when released
+	hooks ReleaseHooks
 }
 
 func (c *clientImpl) Release() {
-	c.hostAva.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReleaseTimeout)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		logs.Warn("release client fail, err:%s", err.Error())
+	}
+}
+
+func (c *clientImpl) Close(ctx context.Context) error {
+	return CloseClient(ctx, &c.hooks, c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) Diagnostics() *Diagnostics {
+	return NewDiagnostics(c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) SceneRegistry() *SceneRegistry {
+	return c.scenes
 }
 
 func (c *clientImpl) WriteUsers(request *WriteUsersRequest,
@@ -37,9 +62,14 @@ func (c *clientImpl) WriteUsers(request *WriteUsersRequest,
 	if len(request.Users) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateUsers(request.GetUsers()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeUsersURL
 	response := &WriteUsersResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -53,9 +83,14 @@ func (c *clientImpl) ImportUsers(request *ImportUsersRequest,
 	if len(users) > MaxImportItemCount {
 		return nil, importTooManyErr
 	}
+	if c.validate {
+		if err := validateImport(ValidateUsers(users), request.GetDateConfig()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.importUsersURL
 	response := &OperationResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -68,9 +103,14 @@ func (c *clientImpl) WriteProducts(request *WriteProductsRequest,
 	if len(request.Products) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateProducts(request.GetProducts()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeProductsURL
 	response := &WriteProductsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -84,9 +124,14 @@ func (c *clientImpl) ImportProducts(request *ImportProductsRequest,
 	if len(products) > MaxImportItemCount {
 		return nil, importTooManyErr
 	}
+	if c.validate {
+		if err := validateImport(ValidateProducts(products), request.GetDateConfig()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.importProductsURL
 	response := &OperationResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -99,9 +144,14 @@ func (c *clientImpl) WriteUserEvents(request *WriteUserEventsRequest,
 	if len(request.UserEvents) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateUserEvents(request.GetUserEvents()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeUserEventsURL
 	response := &WriteUserEventsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -115,9 +165,14 @@ func (c *clientImpl) ImportUserEvents(request *ImportUserEventsRequest,
 	if len(userEvents) > MaxImportItemCount {
 		return nil, importTooManyErr
 	}
+	if c.validate {
+		if err := validateImport(ValidateUserEvents(userEvents), request.GetDateConfig()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.importUserEventsURL
 	response := &OperationResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -127,9 +182,29 @@ func (c *clientImpl) ImportUserEvents(request *ImportUserEventsRequest,
 
 func (c *clientImpl) Predict(request *PredictRequest, scene string,
 	opts ...option.Option) (*PredictResponse, error) {
-	url := strings.ReplaceAll(c.ru.predictURLFormat, "{}", scene)
+	// validated before Execute, the invalid request is not retried with fallback scenes
+	if c.validate {
+		if err := ValidatePredictRequest(request); err != nil {
+			return nil, err
+		}
+	}
+	var response *PredictResponse
+	err := c.scenes.Execute(scene, func(sceneConf *SceneConfig) error {
+		var err error
+		response, err = c.doPredict(ApplySceneDefaults(request, sceneConf).(*PredictRequest), sceneConf, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *clientImpl) doPredict(request *PredictRequest, scene *SceneConfig,
+	opts []option.Option) (*PredictResponse, error) {
+	url := strings.ReplaceAll(c.ru.predictURLFormat, "{}", scene.Name)
 	response := &PredictResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(scene.WithOptions(opts)...))
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +214,14 @@ func (c *clientImpl) Predict(request *PredictRequest, scene string,
 
 func (c *clientImpl) AckServerImpressions(request *AckServerImpressionsRequest,
 	opts ...option.Option) (*AckServerImpressionsResponse, error) {
+	if c.validate {
+		if err := ValidateAckServerImpressionsRequest(request); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.ackImpressionURL
 	response := &AckServerImpressionsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
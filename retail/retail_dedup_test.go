@@ -0,0 +1,67 @@
+package retail
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+// fakeDedupClient rejects the user events of "bad" user,
+// and fails the whole request if err is set
+type fakeDedupClient struct {
+	Client
+	err  error
+	sent int
+}
+
+func (c *fakeDedupClient) WriteUserEvents(request *WriteUserEventsRequest,
+	opts ...option.Option) (*WriteUserEventsResponse, error) {
+	c.sent += len(request.GetUserEvents())
+	if c.err != nil {
+		return nil, c.err
+	}
+	response := &WriteUserEventsResponse{Status: &Status{Code: StatusCodeSuccess}}
+	for _, userEvent := range request.GetUserEvents() {
+		if userEvent.GetUserId() == "bad" {
+			response.Status.Code = 1001
+			response.Errors = append(response.Errors, &UserEventError{Message: "invalid", UserEvent: userEvent})
+		}
+	}
+	return response, nil
+}
+
+func TestUserEventDeduperForget(t *testing.T) {
+	client := &fakeDedupClient{}
+	deduper := NewUserEventDeduper(client, nil, nil)
+	request := &WriteUserEventsRequest{UserEvents: []*UserEvent{
+		{UserId: "good", EventType: EventTypeClick, EventTimestamp: 1},
+		{UserId: "bad", EventType: EventTypeClick, EventTimestamp: 1},
+	}}
+	if _, err := deduper.WriteUserEvents(request); err != nil {
+		t.Fatal(err)
+	}
+	// the rejected event is forgotten, so its retry is sent
+	if _, err := deduper.WriteUserEvents(request); err != nil {
+		t.Fatal(err)
+	}
+	if client.sent != 3 || deduper.Dropped() != 1 {
+		t.Fatalf("expect only the rejected event resent, sent:%d dropped:%d", client.sent, deduper.Dropped())
+	}
+
+	client.err = errors.New("network error")
+	retried := &WriteUserEventsRequest{UserEvents: []*UserEvent{{UserId: "new", EventTimestamp: 2}}}
+	if _, err := deduper.WriteUserEvents(retried); err == nil {
+		t.Fatal("expect the error of client")
+	}
+	client.err = nil
+	if _, err := deduper.WriteUserEvents(retried); err != nil {
+		t.Fatal(err)
+	}
+	if client.sent != 5 {
+		t.Fatalf("expect the events of failed request resent, sent:%d", client.sent)
+	}
+}
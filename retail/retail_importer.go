@@ -0,0 +1,434 @@
+package retail
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+const defaultImportMaxErrorSamples = 100
+
+type ImporterConfig struct {
+	// Max count of chunks submitted and polled at the same time, default 1
+	Concurrency int
+
+	// Policy of waiting for every operation done, common.DefaultWaitPolicy if nil
+	WaitPolicy *common.WaitPolicy
+
+	// Deprecated: use WaitPolicy, it's used only if WaitPolicy is nil
+	PollBackoff *Backoff
+
+	// Deprecated: use WaitPolicy.Timeout, it's used only if WaitPolicy is nil
+	PollTimeout time.Duration
+
+	// Max count of error samples kept in report, default 100
+	MaxErrorSamples int
+}
+
+// ImportClient is the part of Client used by Importer, which is also
+// implemented by the import adapter of retailv2
+type ImportClient interface {
+	ImportUsers(request *ImportUsersRequest, opts ...option.Option) (*OperationResponse, error)
+
+	ImportProducts(request *ImportProductsRequest, opts ...option.Option) (*OperationResponse, error)
+
+	ImportUserEvents(request *ImportUserEventsRequest, opts ...option.Option) (*OperationResponse, error)
+
+	WaitOperation(name string, policy *common.WaitPolicy, opts ...option.Option) (*common.OperationResult, error)
+}
+
+func NewImporter(client ImportClient, config *ImporterConfig) *Importer {
+	importer := &Importer{client: client}
+	if config != nil {
+		importer.config = *config
+	}
+	if importer.config.MaxErrorSamples <= 0 {
+		importer.config.MaxErrorSamples = defaultImportMaxErrorSamples
+	}
+	if importer.config.WaitPolicy == nil && (importer.config.PollBackoff != nil || importer.config.PollTimeout > 0) {
+		importer.config.WaitPolicy = pollWaitPolicy(importer.config.PollBackoff, importer.config.PollTimeout)
+	}
+	return importer
+}
+
+// pollWaitPolicy converts the deprecated polling config into WaitPolicy
+func pollWaitPolicy(backoff *Backoff, timeout time.Duration) *common.WaitPolicy {
+	policy := *common.DefaultWaitPolicy
+	if backoff != nil {
+		policy.Interval = backoff.Initial
+		policy.MaxInterval = backoff.Max
+		policy.Multiplier = backoff.Multiplier
+	}
+	policy.Timeout = timeout
+	return &policy
+}
+
+// Importer imports any count of items for a date, it splits the items into
+// chunks of at most 10000 items, submits them, polls every operation until done,
+// then aggregates the unpacked import responses into a report.
+// If DateConfig.IsEnd is true, the date is finalized by an extra empty request
+// after all chunks succeed, so that the later chunks are not rejected.
+type Importer struct {
+	client ImportClient
+	config ImporterConfig
+}
+
+// ImportChunkResult is the result of one submitted chunk
+type ImportChunkResult struct {
+	Chunk         Chunk
+	OperationName string
+	// The status of unpacked import response, or the status of submitting
+	Status   *Status
+	Metadata *Metadata
+	Err      error
+}
+
+type ImportReport struct {
+	Chunks       []*ImportChunkResult
+	TotalCount   int64
+	SuccessCount int64
+	FailureCount int64
+	// Whether the date is finalized by an is_end request
+	Finalized bool
+}
+
+// Failed returns the chunks failed as a whole
+func (receiver *ImportReport) Failed() []*ImportChunkResult {
+	var failed []*ImportChunkResult
+	for _, chunk := range receiver.Chunks {
+		if chunk.Err != nil {
+			failed = append(failed, chunk)
+		}
+	}
+	return failed
+}
+
+type ImportUsersReport struct {
+	ImportReport
+	ErrorSamples []*UserError
+}
+
+type ImportProductsReport struct {
+	ImportReport
+	ErrorSamples []*ProductError
+}
+
+type ImportUserEventsReport struct {
+	ImportReport
+	ErrorSamples []*UserEventError
+}
+
+// importSubmitFunc submits the items of one chunk, an empty chunk is the is_end request
+type importSubmitFunc func(dateConfig *DateConfig, opts []option.Option) (*OperationResponse, error)
+
+// importNextFunc reads the next chunk of at most size items, and returns the count
+// of them and the func submitting them, io.EOF is returned with the last chunk
+type importNextFunc func(size int) (count int, submit importSubmitFunc, err error)
+
+// ImportUsers
+//
+// Imports all users of request, the first error of chunks
+// is returned together with the report.
+func (receiver *Importer) ImportUsers(request *ImportUsersRequest,
+	opts ...option.Option) (*ImportUsersReport, error) {
+	users := request.GetInputConfig().GetUsersInlineSource().GetUsers()
+	read := func(size int) ([]*User, error) {
+		chunk := users[:inlineChunkEnd(len(users), size)]
+		users = users[len(chunk):]
+		if len(users) == 0 {
+			return chunk, io.EOF
+		}
+		return chunk, nil
+	}
+	return receiver.importUsers(request, read, opts)
+}
+
+func (receiver *Importer) importUsers(request *ImportUsersRequest,
+	read func(size int) ([]*User, error), opts []option.Option) (*ImportUsersReport, error) {
+	submit := func(users []*User) importSubmitFunc {
+		return func(dateConfig *DateConfig, opts []option.Option) (*OperationResponse, error) {
+			chunkRequest := &ImportUsersRequest{
+				InputConfig: &UsersInputConfig{Source: &UsersInputConfig_UsersInlineSource{
+					UsersInlineSource: &UsersInlineSource{Users: users},
+				}},
+				DateConfig:   dateConfig,
+				ErrorsConfig: request.GetErrorsConfig(),
+				Extra:        request.GetExtra(),
+			}
+			return receiver.client.ImportUsers(chunkRequest, opts...)
+		}
+	}
+	next := func(size int) (int, importSubmitFunc, error) {
+		users, err := read(size)
+		return len(users), submit(users), err
+	}
+	report := &ImportUsersReport{}
+	collect := func(response proto.Message) {
+		samples := response.(*ImportUsersResponse).GetErrorSamples()
+		room := receiver.sampleRoom(len(report.ErrorSamples), len(samples))
+		report.ErrorSamples = append(report.ErrorSamples, samples[:room]...)
+	}
+	err := receiver.run(&report.ImportReport, request.GetDateConfig(), next, submit(nil), collect, opts)
+	return report, err
+}
+
+// ImportProducts
+//
+// Imports all products of request, the first error of chunks
+// is returned together with the report.
+func (receiver *Importer) ImportProducts(request *ImportProductsRequest,
+	opts ...option.Option) (*ImportProductsReport, error) {
+	products := request.GetInputConfig().GetProductsInlineSource().GetProducts()
+	read := func(size int) ([]*Product, error) {
+		chunk := products[:inlineChunkEnd(len(products), size)]
+		products = products[len(chunk):]
+		if len(products) == 0 {
+			return chunk, io.EOF
+		}
+		return chunk, nil
+	}
+	return receiver.importProducts(request, read, opts)
+}
+
+func (receiver *Importer) importProducts(request *ImportProductsRequest,
+	read func(size int) ([]*Product, error), opts []option.Option) (*ImportProductsReport, error) {
+	submit := func(products []*Product) importSubmitFunc {
+		return func(dateConfig *DateConfig, opts []option.Option) (*OperationResponse, error) {
+			chunkRequest := &ImportProductsRequest{
+				InputConfig: &ProductsInputConfig{Source: &ProductsInputConfig_ProductsInlineSource{
+					ProductsInlineSource: &ProductsInlineSource{Products: products},
+				}},
+				DateConfig:   dateConfig,
+				ErrorsConfig: request.GetErrorsConfig(),
+				Extra:        request.GetExtra(),
+			}
+			return receiver.client.ImportProducts(chunkRequest, opts...)
+		}
+	}
+	next := func(size int) (int, importSubmitFunc, error) {
+		products, err := read(size)
+		return len(products), submit(products), err
+	}
+	report := &ImportProductsReport{}
+	collect := func(response proto.Message) {
+		samples := response.(*ImportProductsResponse).GetErrorSamples()
+		room := receiver.sampleRoom(len(report.ErrorSamples), len(samples))
+		report.ErrorSamples = append(report.ErrorSamples, samples[:room]...)
+	}
+	err := receiver.run(&report.ImportReport, request.GetDateConfig(), next, submit(nil), collect, opts)
+	return report, err
+}
+
+// ImportUserEvents
+//
+// Imports all user events of request, the first error of chunks
+// is returned together with the report.
+func (receiver *Importer) ImportUserEvents(request *ImportUserEventsRequest,
+	opts ...option.Option) (*ImportUserEventsReport, error) {
+	userEvents := request.GetInputConfig().GetUserEventsInlineSource().GetUserEvents()
+	read := func(size int) ([]*UserEvent, error) {
+		chunk := userEvents[:inlineChunkEnd(len(userEvents), size)]
+		userEvents = userEvents[len(chunk):]
+		if len(userEvents) == 0 {
+			return chunk, io.EOF
+		}
+		return chunk, nil
+	}
+	return receiver.importUserEvents(request, read, opts)
+}
+
+func (receiver *Importer) importUserEvents(request *ImportUserEventsRequest,
+	read func(size int) ([]*UserEvent, error), opts []option.Option) (*ImportUserEventsReport, error) {
+	submit := func(userEvents []*UserEvent) importSubmitFunc {
+		return func(dateConfig *DateConfig, opts []option.Option) (*OperationResponse, error) {
+			chunkRequest := &ImportUserEventsRequest{
+				InputConfig: &UserEventsInputConfig{Source: &UserEventsInputConfig_UserEventsInlineSource{
+					UserEventsInlineSource: &UserEventsInlineSource{UserEvents: userEvents},
+				}},
+				DateConfig:   dateConfig,
+				ErrorsConfig: request.GetErrorsConfig(),
+				Extra:        request.GetExtra(),
+			}
+			return receiver.client.ImportUserEvents(chunkRequest, opts...)
+		}
+	}
+	next := func(size int) (int, importSubmitFunc, error) {
+		userEvents, err := read(size)
+		return len(userEvents), submit(userEvents), err
+	}
+	report := &ImportUserEventsReport{}
+	collect := func(response proto.Message) {
+		samples := response.(*ImportUserEventsResponse).GetErrorSamples()
+		room := receiver.sampleRoom(len(report.ErrorSamples), len(samples))
+		report.ErrorSamples = append(report.ErrorSamples, samples[:room]...)
+	}
+	err := receiver.run(&report.ImportReport, request.GetDateConfig(), next, submit(nil), collect, opts)
+	return report, err
+}
+
+// inlineChunkEnd returns the end of the next chunk of at most size in total items
+func inlineChunkEnd(total int, size int) int {
+	if total < size {
+		return total
+	}
+	return size
+}
+
+// importTask is a chunk read by run, which is submitted by one of the workers
+type importTask struct {
+	chunk    Chunk
+	submit   importSubmitFunc
+	result   *ImportChunkResult
+	response proto.Message
+}
+
+// run reads the chunks by next one by one, and hands every chunk to at most
+// Concurrency workers once it's read, so only the chunks being imported
+// and the one waiting for a worker are held in memory
+func (receiver *Importer) run(report *ImportReport, dateConfig *DateConfig, next importNextFunc,
+	submitEnd importSubmitFunc, collect func(response proto.Message), opts []option.Option) error {
+	if dateConfig.GetDate() == "" {
+		return errors.New("date of import is empty")
+	}
+	// only the last request could carry is_end, otherwise the
+	// date is finalized and the later chunks are rejected
+	chunkDateConfig := &DateConfig{Date: dateConfig.GetDate()}
+	concurrency := receiver.config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	taskCh := make(chan *importTask)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		AsyncExecute(func() {
+			defer wg.Done()
+			for task := range taskCh {
+				task.result, task.response = receiver.importChunk(task.chunk, chunkDateConfig, task.submit, opts)
+				// the items are released once submitted
+				task.submit = nil
+			}
+		})
+	}
+	var (
+		tasks   []*importTask
+		total   int
+		readErr error
+	)
+	for {
+		count, submit, err := next(MaxImportItemCount)
+		if count > 0 {
+			task := &importTask{chunk: Chunk{Index: len(tasks), Start: total, End: total + count}, submit: submit}
+			tasks = append(tasks, task)
+			taskCh <- task
+			total += count
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	close(taskCh)
+	wg.Wait()
+
+	var firstErr error
+	report.Chunks = make([]*ImportChunkResult, len(tasks))
+	for i, task := range tasks {
+		report.Chunks[i] = task.result
+		if firstErr == nil {
+			firstErr = task.result.Err
+		}
+		report.addCounts(task.result)
+		if task.response != nil {
+			collect(task.response)
+		}
+	}
+	if readErr != nil {
+		logs.Warn("stop importing date:%s as reading fails, err:%v", dateConfig.GetDate(), readErr)
+		return readErr
+	}
+	if !dateConfig.GetIsEnd() {
+		return firstErr
+	}
+	if firstErr != nil {
+		logs.Warn("skip finalizing date:%s as some chunks failed, err:%v", dateConfig.GetDate(), firstErr)
+		return firstErr
+	}
+	endChunk := Chunk{Index: len(tasks), Start: total, End: total}
+	endResult, _ := receiver.importChunk(endChunk, &DateConfig{Date: dateConfig.GetDate(), IsEnd: true},
+		submitEnd, opts)
+	report.Finalized = endResult.Err == nil
+	return endResult.Err
+}
+
+func (receiver *Importer) importChunk(chunk Chunk, dateConfig *DateConfig,
+	submit importSubmitFunc, opts []option.Option) (*ImportChunkResult, proto.Message) {
+	result := &ImportChunkResult{Chunk: chunk}
+	opResponse, err := submit(dateConfig, ChunkOptions(opts, chunk))
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	result.Status = opResponse.GetStatus()
+	if result.Status.GetCode() != StatusCodeSuccess {
+		result.Err = errors.New(fmt.Sprintf("[ImportChunk] fail, chunk:%d code:%d msg:%s",
+			chunk.Index, result.Status.GetCode(), result.Status.GetMessage()))
+		return result, nil
+	}
+	result.OperationName = opResponse.GetOperation().GetName()
+	waited, err := receiver.client.WaitOperation(result.OperationName, receiver.config.WaitPolicy)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	result.Metadata = waited.Operation.GetMetadata()
+	// all typed import responses carry status in the same field
+	response, ok := waited.Response.(interface{ GetStatus() *Status })
+	if !ok {
+		result.Err = errors.New(fmt.Sprintf("[ImportChunk] unexpected response, name:%s", result.OperationName))
+		return result, nil
+	}
+	result.Status = response.GetStatus()
+	return result, waited.Response
+}
+
+func (receiver *ImportReport) addCounts(result *ImportChunkResult) {
+	metadata := result.Metadata
+	if metadata != nil {
+		receiver.TotalCount += metadata.GetTotalCount()
+		receiver.SuccessCount += metadata.GetSuccessCount()
+		receiver.FailureCount += metadata.GetFailureCount()
+		return
+	}
+	// the whole chunk failed before imported
+	count := int64(result.Chunk.Len())
+	receiver.TotalCount += count
+	if result.Err != nil {
+		receiver.FailureCount += count
+	}
+}
+
+// sampleRoom returns how many of the added error samples could be kept in report
+func (receiver *Importer) sampleRoom(current int, added int) int {
+	room := receiver.config.MaxErrorSamples - current
+	if room < 0 {
+		return 0
+	}
+	if room > added {
+		return added
+	}
+	return room
+}
@@ -0,0 +1,188 @@
+package retail
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	"github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeImportClient finishes every operation at the second poll,
+// and rejects the first user of every chunk
+type fakeImportClient struct {
+	Client
+	lock       sync.Mutex
+	requests   []*ImportUsersRequest
+	operations map[string]*Operation
+	polled     map[string]bool
+}
+
+func (c *fakeImportClient) ImportUsers(request *ImportUsersRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests = append(c.requests, request)
+	users := request.GetInputConfig().GetUsersInlineSource().GetUsers()
+	response := &ImportUsersResponse{Status: &Status{Code: 0}}
+	metadata := &Metadata{TotalCount: int64(len(users)), SuccessCount: int64(len(users))}
+	if len(users) > 0 {
+		response.Status.Code = 1001
+		response.ErrorSamples = []*UserError{{Message: "invalid", User: users[0]}}
+		metadata.SuccessCount--
+		metadata.FailureCount++
+	}
+	packed, err := anypb.New(response)
+	if err != nil {
+		return nil, err
+	}
+	name := "op-" + strconv.Itoa(len(c.requests))
+	c.operations[name] = &Operation{Name: name, Metadata: metadata, Done: true, Response: packed}
+	return &OperationResponse{Status: &Status{Code: 0}, Operation: &Operation{Name: name}}, nil
+}
+
+func (c *fakeImportClient) GetOperation(request *GetOperationRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.polled[request.Name] {
+		c.polled[request.Name] = true
+		return &OperationResponse{Status: &Status{Code: 0}, Operation: &Operation{Name: request.Name}}, nil
+	}
+	return &OperationResponse{Status: &Status{Code: 0}, Operation: c.operations[request.Name]}, nil
+}
+
+func (c *fakeImportClient) WaitOperation(name string, policy *common.WaitPolicy,
+	opts ...option.Option) (*common.OperationResult, error) {
+	for {
+		response, _ := c.GetOperation(&GetOperationRequest{Name: name})
+		if operation := response.GetOperation(); operation.GetDone() {
+			unpacked, err := common.UnpackOperationResponse(operation)
+			return &common.OperationResult{Operation: operation, Response: unpacked}, err
+		}
+		time.Sleep(policy.Interval)
+	}
+}
+
+func TestImporterImportUsers(t *testing.T) {
+	users := make([]*User, 25000)
+	for i := range users {
+		users[i] = &User{UserId: strconv.Itoa(i)}
+	}
+	client := &fakeImportClient{operations: make(map[string]*Operation), polled: make(map[string]bool)}
+	importer := NewImporter(client, &ImporterConfig{
+		Concurrency:     2,
+		WaitPolicy:      &common.WaitPolicy{Interval: time.Millisecond},
+		MaxErrorSamples: 2,
+	})
+	request := &ImportUsersRequest{
+		InputConfig: &UsersInputConfig{Source: &UsersInputConfig_UsersInlineSource{
+			UsersInlineSource: &UsersInlineSource{Users: users},
+		}},
+		DateConfig: &DateConfig{Date: "2021-06-10", IsEnd: true},
+	}
+	report, err := importer.ImportUsers(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.requests) != 4 {
+		t.Fatalf("expect 3 chunks and an is_end request, got %d requests", len(client.requests))
+	}
+	for i, chunkRequest := range client.requests {
+		isEnd := len(chunkRequest.GetInputConfig().GetUsersInlineSource().GetUsers()) == 0
+		if chunkRequest.GetDateConfig().GetIsEnd() != isEnd || (isEnd && i != 3) {
+			t.Errorf("unexpected date config of request %d: %v", i, chunkRequest.GetDateConfig())
+		}
+	}
+	if !report.Finalized || len(report.Chunks) != 3 {
+		t.Errorf("unexpected report, finalized:%v chunks:%d", report.Finalized, len(report.Chunks))
+	}
+	if report.TotalCount != 25000 || report.SuccessCount != 24997 || report.FailureCount != 3 {
+		t.Errorf("unexpected counts, total:%d success:%d failure:%d",
+			report.TotalCount, report.SuccessCount, report.FailureCount)
+	}
+	if len(report.ErrorSamples) != 2 {
+		t.Errorf("expect error samples limited to 2, got %d", len(report.ErrorSamples))
+	}
+}
+
+func TestImporterDeprecatedPollConfig(t *testing.T) {
+	importer := NewImporter(nil, &ImporterConfig{
+		PollBackoff: &core.Backoff{Initial: time.Millisecond, Max: time.Second},
+		PollTimeout: time.Minute,
+	})
+	policy := importer.config.WaitPolicy
+	if policy == nil || policy.Interval != time.Millisecond || policy.MaxInterval != time.Second ||
+		policy.Timeout != time.Minute {
+		t.Errorf("unexpected wait policy %+v", policy)
+	}
+}
+
+// eofReader tells whether the content is read to the end
+type eofReader struct {
+	reader io.Reader
+	eof    int32
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		atomic.StoreInt32(&r.eof, 1)
+	}
+	return n, err
+}
+
+// streamImportClient records whether the file is read to
+// the end when the first chunk is submitted
+type streamImportClient struct {
+	*fakeImportClient
+	file         *eofReader
+	eofAtSubmit  bool
+	submitCalled bool
+}
+
+func (c *streamImportClient) ImportUsers(request *ImportUsersRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	if !c.submitCalled {
+		c.submitCalled = true
+		c.eofAtSubmit = atomic.LoadInt32(&c.file.eof) == 1
+	}
+	return c.fakeImportClient.ImportUsers(request, opts...)
+}
+
+func TestImportUsersFromStreams(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 25000; i++ {
+		content.WriteString(`{"user_id":"` + strconv.Itoa(i) + `"}` + "\n")
+	}
+	content.WriteString("{bad line\n")
+	file := &eofReader{reader: strings.NewReader(content.String())}
+	client := &streamImportClient{
+		fakeImportClient: &fakeImportClient{operations: make(map[string]*Operation), polled: make(map[string]bool)},
+		file:             file,
+	}
+	importer := NewImporter(client, &ImporterConfig{WaitPolicy: &common.WaitPolicy{Interval: time.Millisecond}})
+	reader := NewUserJsonlReader(file)
+	report, err := ImportUsersFrom(importer, reader, &DateConfig{Date: "2021-06-10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.eofAtSubmit {
+		t.Error("the first chunk should be submitted before the whole file is read")
+	}
+	if len(report.Chunks) != 3 || report.Chunks[2].Chunk.Start != 20000 || report.TotalCount != 25000 {
+		t.Errorf("unexpected report, chunks:%d total:%d", len(report.Chunks), report.TotalCount)
+	}
+	if len(reader.LineErrors()) != 1 || reader.LineErrors()[0].Line != 25001 {
+		t.Errorf("unexpected line errors %v", reader.LineErrors())
+	}
+}
@@ -0,0 +1,71 @@
+package retail
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+// fakeResubmitClient times out user "u1" at the first write, and rejects user "u2"
+type fakeResubmitClient struct {
+	Client
+	writes [][]string
+}
+
+func (c *fakeResubmitClient) WriteUsers(request *WriteUsersRequest,
+	opts ...option.Option) (*WriteUsersResponse, error) {
+	if len(request.GetUsers()) > MaxWriteItemCount {
+		return nil, writeTooManyErr
+	}
+	var ids []string
+	response := &WriteUsersResponse{Status: &Status{Code: StatusCodeSuccess}}
+	for _, user := range request.GetUsers() {
+		ids = append(ids, user.GetUserId())
+		switch {
+		case user.GetUserId() == "u1" && len(c.writes) == 0:
+			response.Errors = append(response.Errors, &UserError{Message: "write timeout", User: user})
+		case user.GetUserId() == "u2":
+			response.Errors = append(response.Errors, &UserError{Message: "invalid gender", User: user})
+		}
+	}
+	if len(response.Errors) > 0 {
+		response.Status.Code = 1001
+	}
+	c.writes = append(c.writes, ids)
+	return response, nil
+}
+
+func TestResubmitterWriteUsers(t *testing.T) {
+	client := &fakeResubmitClient{}
+	resubmitter := NewResubmitter(client, &ResubmitConfig{Backoff: &Backoff{Initial: time.Millisecond}})
+	request := &WriteUsersRequest{Users: []*User{{UserId: "u0"}, {UserId: "u1"}, {UserId: "u2"}}}
+	report, err := resubmitter.WriteUsers(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.writes) != 2 || len(client.writes[1]) != 1 || client.writes[1][0] != "u1" {
+		t.Fatalf("expect only the timeout user resent, got %v", client.writes)
+	}
+	if report.SuccessCount != 2 || len(report.DeadLetters) != 1 ||
+		report.DeadLetters[0].Item.(*User).GetUserId() != "u2" || report.DeadLetters[0].Topic != TopicUser {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// the request over limit never succeeds, it's not resent
+	client = &fakeResubmitClient{}
+	users := make([]*User, MaxWriteItemCount+1)
+	for i := range users {
+		users[i] = &User{UserId: "u"}
+	}
+	report, err = NewResubmitter(client, nil).WriteUsers(&WriteUsersRequest{Users: users})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Attempts != 1 || len(report.DeadLetters) != len(users) {
+		t.Fatalf("expect given up at once, attempts:%d dead letters:%d", report.Attempts, len(report.DeadLetters))
+	}
+}
@@ -0,0 +1,156 @@
+package retail
+
+import (
+	"errors"
+	"time"
+
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewSyncSession creates the session of topic in date, the topic
+// should be one of TopicUser, TopicProduct and TopicUserEvent
+func NewSyncSession(client Client, topic string, date time.Time,
+	store common.SyncStateStore) (*SyncSession, error) {
+	if topic != TopicUser && topic != TopicProduct && topic != TopicUserEvent {
+		return nil, errors.New("unknown retail topic: " + topic)
+	}
+	session, err := common.NewSyncSession(client, topic, date, store)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncSession{SyncSession: session, client: client}, nil
+}
+
+// SyncSession stamps the writes and imports of a topic with the date,
+// the DateConfig of import requests is filled, and is_end is only sent
+// by Finalize after all imports done.
+type SyncSession struct {
+	*common.SyncSession
+	client Client
+}
+
+func (receiver *SyncSession) WriteUsers(request *WriteUsersRequest,
+	opts ...option.Option) (*WriteUsersResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return receiver.client.WriteUsers(request, opts...)
+}
+
+func (receiver *SyncSession) WriteProducts(request *WriteProductsRequest,
+	opts ...option.Option) (*WriteProductsResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return receiver.client.WriteProducts(request, opts...)
+}
+
+func (receiver *SyncSession) WriteUserEvents(request *WriteUserEventsRequest,
+	opts ...option.Option) (*WriteUserEventsResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return receiver.client.WriteUserEvents(request, opts...)
+}
+
+// ImportUsers overrides the DateConfig of request with the date of session,
+// and tracks the returned operation
+func (receiver *SyncSession) ImportUsers(request *ImportUsersRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	request = proto.Clone(request).(*ImportUsersRequest)
+	request.DateConfig = receiver.dateConfig(false)
+	return receiver.track(receiver.client.ImportUsers(request, opts...))
+}
+
+// ImportProducts overrides the DateConfig of request with the date of session,
+// and tracks the returned operation
+func (receiver *SyncSession) ImportProducts(request *ImportProductsRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	request = proto.Clone(request).(*ImportProductsRequest)
+	request.DateConfig = receiver.dateConfig(false)
+	return receiver.track(receiver.client.ImportProducts(request, opts...))
+}
+
+// ImportUserEvents overrides the DateConfig of request with the date of session,
+// and tracks the returned operation
+func (receiver *SyncSession) ImportUserEvents(request *ImportUserEventsRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	opts, release, err := receiver.Options(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	request = proto.Clone(request).(*ImportUserEventsRequest)
+	request.DateConfig = receiver.dateConfig(false)
+	return receiver.track(receiver.client.ImportUserEvents(request, opts...))
+}
+
+// Finalize sends an empty import request of topic with DateConfig.is_end,
+// then calls Done, see common.SyncSession.Finalize
+func (receiver *SyncSession) Finalize(policy *common.WaitPolicy) error {
+	return receiver.SyncSession.Finalize(receiver.sendEnd, policy)
+}
+
+func (receiver *SyncSession) sendEnd(opts []option.Option) (*OperationResponse, error) {
+	switch receiver.Topic() {
+	case TopicUser:
+		request := &ImportUsersRequest{
+			InputConfig: &UsersInputConfig{Source: &UsersInputConfig_UsersInlineSource{
+				UsersInlineSource: &UsersInlineSource{},
+			}},
+			DateConfig: receiver.dateConfig(true),
+		}
+		return receiver.client.ImportUsers(request, opts...)
+	case TopicProduct:
+		request := &ImportProductsRequest{
+			InputConfig: &ProductsInputConfig{Source: &ProductsInputConfig_ProductsInlineSource{
+				ProductsInlineSource: &ProductsInlineSource{},
+			}},
+			DateConfig: receiver.dateConfig(true),
+		}
+		return receiver.client.ImportProducts(request, opts...)
+	default:
+		request := &ImportUserEventsRequest{
+			InputConfig: &UserEventsInputConfig{Source: &UserEventsInputConfig_UserEventsInlineSource{
+				UserEventsInlineSource: &UserEventsInlineSource{},
+			}},
+			DateConfig: receiver.dateConfig(true),
+		}
+		return receiver.client.ImportUserEvents(request, opts...)
+	}
+}
+
+func (receiver *SyncSession) dateConfig(isEnd bool) *DateConfig {
+	return &DateConfig{Date: receiver.DateString(), IsEnd: isEnd}
+}
+
+func (receiver *SyncSession) track(response *OperationResponse, err error) (*OperationResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := receiver.Track(response); err != nil {
+		return response, err
+	}
+	return response, nil
+}
@@ -0,0 +1,298 @@
+package retail
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+// The acceptable values of UserEvent.event_type
+const (
+	EventTypeImpression          = "impression"
+	EventTypeClick               = "click"
+	EventTypeAddToCart           = "add-to-cart"
+	EventTypeRemoveFromCart      = "remove-from-cart"
+	EventTypeAddToFavorites      = "add-to-favorites"
+	EventTypeRemoveFromFavorites = "remove-from-favorites"
+	EventTypePurchase            = "purchase"
+	EventTypeSearch              = "search"
+	EventTypeStayDetailPage      = "stay-detail-page"
+)
+
+// The acceptable values of AckServerImpressionsRequest.AlteredProduct.altered_reason
+const (
+	AlteredReasonKept     = "kept"
+	AlteredReasonFiltered = "filtered"
+	AlteredReasonInserted = "inserted"
+)
+
+const validateDateLayout = "2006-01-02"
+
+var (
+	eventTypes = valueSet(EventTypeImpression, EventTypeClick, EventTypeAddToCart,
+		EventTypeRemoveFromCart, EventTypeAddToFavorites, EventTypeRemoveFromFavorites,
+		EventTypePurchase, EventTypeSearch, EventTypeStayDetailPage)
+	sceneRequiredEventTypes = valueSet(EventTypeImpression, EventTypeClick)
+	alteredReasons          = valueSet(AlteredReasonKept, AlteredReasonFiltered, AlteredReasonInserted)
+	platforms               = valueSet("app", "desktop-web", "mobile-web", "other")
+	osTypes                 = valueSet("android", "ios", "windows", "other")
+	networks                = valueSet("2g", "3g", "4g", "5g", "wifi", "other")
+	trafficSources          = valueSet("self", "byteplus", "other")
+)
+
+// ValidateUsers checks the rules documented in the proto of users,
+// FieldErrors is returned if any user is invalid
+func ValidateUsers(users []*User) error {
+	checker := &fieldChecker{}
+	for i, user := range users {
+		checker.index = i
+		checker.required("user_id", user.GetUserId())
+	}
+	return checker.result()
+}
+
+// ValidateProducts checks the rules documented in the proto of products,
+// FieldErrors is returned if any product is invalid
+func ValidateProducts(products []*Product) error {
+	checker := &fieldChecker{}
+	for i, product := range products {
+		checker.index = i
+		checker.required("product_id", product.GetProductId())
+		checker.required("title", product.GetTitle())
+		if len(product.GetCategories()) == 0 {
+			checker.add("categories", "is required")
+		}
+		for j, category := range product.GetCategories() {
+			path := fmt.Sprintf("categories[%d]", j)
+			if category.GetCategoryDepth() <= 0 {
+				checker.add(path+".category_depth", "should be positive")
+			}
+			if len(category.GetCategoryNodes()) == 0 {
+				checker.add(path+".category_nodes", "is required")
+			}
+			for k, node := range category.GetCategoryNodes() {
+				checker.required(fmt.Sprintf("%s.category_nodes[%d].id_or_name", path, k), node.GetIdOrName())
+			}
+		}
+		if len(product.GetBrands()) == 0 {
+			checker.add("brands", "is required")
+		}
+		for j, brand := range product.GetBrands() {
+			path := fmt.Sprintf("brands[%d]", j)
+			if brand.GetBrandDepth() <= 0 {
+				checker.add(path+".brand_depth", "should be positive")
+			}
+			checker.required(path+".id_or_name", brand.GetIdOrName())
+		}
+		if product.GetPrice() == nil {
+			checker.add("price", "is required")
+		}
+	}
+	return checker.result()
+}
+
+// ValidateUserEvents checks the rules documented in the proto of user events,
+// such as product_id is required except search event, which requires context.query.
+// FieldErrors is returned if any user event is invalid
+func ValidateUserEvents(userEvents []*UserEvent) error {
+	checker := &fieldChecker{}
+	for i, userEvent := range userEvents {
+		checker.index = i
+		checkUserEvent(checker, userEvent)
+	}
+	return checker.result()
+}
+
+func checkUserEvent(checker *fieldChecker, userEvent *UserEvent) {
+	checker.required("user_id", userEvent.GetUserId())
+	eventType := userEvent.GetEventType()
+	if checker.required("event_type", eventType) {
+		checker.oneOf("event_type", eventType, eventTypes)
+	}
+	if userEvent.GetEventTimestamp() <= 0 {
+		checker.add("event_timestamp", "is required")
+	}
+	if userEvent.GetScene() == nil && sceneRequiredEventTypes[eventType] {
+		checker.add("scene", "is required for "+eventType+" event")
+	}
+	if userEvent.GetScene() != nil {
+		checker.required("scene.scene_name", userEvent.GetScene().GetSceneName())
+	}
+	switch {
+	case eventType == EventTypeSearch:
+		if userEvent.GetProductId() != "" {
+			checker.add("product_id", "should be empty for search event")
+		}
+		checker.required("context.query", userEvent.GetContext().GetQuery())
+	case eventTypes[eventType]:
+		checker.required("product_id", userEvent.GetProductId())
+	}
+	if eventType == EventTypePurchase && userEvent.GetPurchaseCount() <= 0 {
+		checker.add("purchase_count", "should be positive for purchase event")
+	}
+	if eventType == EventTypeStayDetailPage && userEvent.GetDetailPageStayTime() <= 0 {
+		checker.add("detail_page_stay_time", "should be positive for stay-detail-page event")
+	}
+	if userEvent.GetDevice() == nil {
+		checker.add("device", "is required")
+	} else {
+		checkDevice(checker, "device", userEvent.GetDevice())
+	}
+	if userEvent.GetTrafficSource() != "" {
+		checker.oneOf("traffic_source", userEvent.GetTrafficSource(), trafficSources)
+	}
+}
+
+func checkDevice(checker *fieldChecker, path string, device *UserEvent_Device) {
+	if checker.required(path+".platform", device.GetPlatform()) {
+		checker.oneOf(path+".platform", device.GetPlatform(), platforms)
+	}
+	if device.GetOsType() != "" {
+		checker.oneOf(path+".os_type", device.GetOsType(), osTypes)
+	}
+	if device.GetNetwork() != "" {
+		checker.oneOf(path+".network", device.GetNetwork(), networks)
+	}
+}
+
+// ValidateDateConfig checks the date is formatted like "2021-06-10",
+// the FieldErrors is reported with Index -1
+func ValidateDateConfig(dateConfig *DateConfig) error {
+	checker := &fieldChecker{index: -1}
+	checkDateConfig(checker, dateConfig)
+	return checker.result()
+}
+
+func checkDateConfig(checker *fieldChecker, dateConfig *DateConfig) {
+	if dateConfig == nil {
+		checker.add("date_config", "is required")
+		return
+	}
+	date := dateConfig.GetDate()
+	if !checker.required("date_config.date", date) {
+		return
+	}
+	if _, err := time.Parse(validateDateLayout, date); err != nil {
+		checker.add("date_config.date", "should be formatted like 2021-06-10, got "+date)
+	}
+}
+
+// validateImport merges the errors of items and the date config of import request
+func validateImport(itemsErr error, dateConfig *DateConfig) error {
+	checker := &fieldChecker{index: -1}
+	if fieldErrors, ok := itemsErr.(FieldErrors); ok {
+		checker.errors = fieldErrors
+	}
+	checkDateConfig(checker, dateConfig)
+	return checker.result()
+}
+
+// ValidatePredictRequest checks the fields of request, the FieldErrors is
+// reported with Index -1. Size is not checked as it may be filled by scene defaults
+func ValidatePredictRequest(request *PredictRequest) error {
+	checker := &fieldChecker{index: -1}
+	checker.required("user_id", request.GetUserId())
+	if request.GetSize() < 0 {
+		checker.add("size", "should not be negative")
+	}
+	if request.GetScene() == nil {
+		checker.add("scene", "is required")
+	} else {
+		checker.required("scene.scene_name", request.GetScene().GetSceneName())
+	}
+	context := request.GetContext()
+	if rootProduct := context.GetRootProduct(); rootProduct != nil {
+		checker.required("context.root_product.product_id", rootProduct.GetProductId())
+	}
+	if device := context.GetDevice(); device != nil {
+		checkDevice(checker, "context.device", device)
+	}
+	for i, productId := range context.GetCandidateProductIds() {
+		checker.required(fmt.Sprintf("context.candidate_product_ids[%d]", i), productId)
+	}
+	return checker.result()
+}
+
+// ValidateAckServerImpressionsRequest checks the fields of request, such as
+// altered_reason is one of "kept", "filtered" and "inserted".
+// The FieldErrors is reported with Index -1
+func ValidateAckServerImpressionsRequest(request *AckServerImpressionsRequest) error {
+	checker := &fieldChecker{index: -1}
+	checker.required("predict_request_id", request.GetPredictRequestId())
+	checker.required("user_id", request.GetUserId())
+	if checker.required("traffic_source", request.GetTrafficSource()) {
+		checker.oneOf("traffic_source", request.GetTrafficSource(), trafficSources)
+	}
+	if request.GetScene() == nil {
+		checker.add("scene", "is required")
+	} else {
+		checker.required("scene.scene_name", request.GetScene().GetSceneName())
+	}
+	if len(request.GetAlteredProducts()) == 0 {
+		checker.add("altered_products", "is required")
+	}
+	for i, alteredProduct := range request.GetAlteredProducts() {
+		path := fmt.Sprintf("altered_products[%d]", i)
+		checker.required(path+".product_id", alteredProduct.GetProductId())
+		if checker.required(path+".altered_reason", alteredProduct.GetAlteredReason()) {
+			checker.oneOf(path+".altered_reason", alteredProduct.GetAlteredReason(), alteredReasons)
+		}
+		if alteredProduct.GetRank() < 0 {
+			checker.add(path+".rank", "should not be negative")
+		}
+	}
+	return checker.result()
+}
+
+// fieldChecker collects the FieldError of the item at index
+type fieldChecker struct {
+	index  int
+	errors FieldErrors
+}
+
+func (receiver *fieldChecker) add(path string, message string) {
+	receiver.errors = append(receiver.errors, &FieldError{Index: receiver.index, Path: path, Message: message})
+}
+
+// required reports the empty value, and returns whether the value is present
+func (receiver *fieldChecker) required(path string, value string) bool {
+	if value == "" {
+		receiver.add(path, "is required")
+		return false
+	}
+	return true
+}
+
+func (receiver *fieldChecker) oneOf(path string, value string, allowed map[string]bool) {
+	if !allowed[value] {
+		receiver.add(path, fmt.Sprintf("should be one of %s, got %s", joinValues(allowed), value))
+	}
+}
+
+func (receiver *fieldChecker) result() error {
+	if len(receiver.errors) == 0 {
+		return nil
+	}
+	return receiver.errors
+}
+
+func valueSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func joinValues(set map[string]bool) string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,65 @@
+package retail
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	. "github.com/byteplus-sdk/sdk-go/retail/protocol"
+)
+
+func TestValidateUserEvents(t *testing.T) {
+	device := &UserEvent_Device{Platform: "app"}
+	userEvents := []*UserEvent{
+		{UserId: "u1", EventType: EventTypeClick, EventTimestamp: 1, ProductId: "p1",
+			Scene: &UserEvent_Scene{SceneName: "home"}, Device: device},
+		{UserId: "u2", EventType: EventTypeImpression, EventTimestamp: 1, Device: device},
+		{UserId: "u3", EventType: EventTypeSearch, EventTimestamp: 1, ProductId: "p3", Device: device},
+		{UserId: "u4", EventType: "view", EventTimestamp: 1, Device: device},
+	}
+	err := ValidateUserEvents(userEvents)
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("expect FieldErrors, got %v", err)
+	}
+	var paths []string
+	for _, fieldError := range fieldErrors {
+		paths = append(paths, fieldError.Error())
+	}
+	expected := []string{
+		"[1].scene: is required for impression event",
+		"[1].product_id: is required",
+		"[2].product_id: should be empty for search event",
+		"[2].context.query: is required",
+		"[3].event_type: should be one of add-to-cart,add-to-favorites,click,impression," +
+			"purchase,remove-from-cart,remove-from-favorites,search,stay-detail-page, got view",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("unexpected errors %v", paths)
+	}
+	if !reflect.DeepEqual(fieldErrors.Indexes(), []int{1, 2, 3}) {
+		t.Fatalf("unexpected indexes %v", fieldErrors.Indexes())
+	}
+}
+
+func TestValidateRequestFields(t *testing.T) {
+	err := ValidateDateConfig(&DateConfig{Date: "2021/06/10"})
+	if err == nil || err.Error() != "invalid fields: date_config.date: should be formatted like 2021-06-10, got 2021/06/10" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	request := &AckServerImpressionsRequest{
+		PredictRequestId: "r1",
+		UserId:           "u1",
+		TrafficSource:    "self",
+		Scene:            &UserEvent_Scene{SceneName: "home"},
+		AlteredProducts: []*AckServerImpressionsRequest_AlteredProduct{
+			{ProductId: "p1", AlteredReason: AlteredReasonKept, Rank: 1},
+			{ProductId: "p2", AlteredReason: "removed"},
+		},
+	}
+	err = ValidateAckServerImpressionsRequest(request)
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok || len(fieldErrors) != 1 || fieldErrors[0].Path != "altered_products[1].altered_reason" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package retailv2
+
+import (
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// UserEventCallback reports the result of user event written by UserEventsBufferedWriter,
+// err is nil if the user event is accepted by server
+type UserEventCallback func(userEvent *UserEvent, err error)
+
+// NewUserEventsBufferedWriter creates a writer that buffers single user events,
+// and writes them in batch through client.WriteUserEvents.
+// The writer is drained when client released, or it could be closed by caller.
+// The opts are used by every flush, so it should not contain request id.
+func NewUserEventsBufferedWriter(client Client, config *BufferedWriterConfig,
+	callback UserEventCallback, opts ...option.Option) *UserEventsBufferedWriter {
+	writer := &UserEventsBufferedWriter{client: client, opts: opts}
+	var recordCallback RecordCallback
+	if callback != nil {
+		recordCallback = func(record interface{}, err error) {
+			callback(record.(*UserEvent), err)
+		}
+	}
+	writer.writer = NewBufferedWriter(config, MaxWriteItemCount, writer.flush, sizeOfUserEvent, recordCallback)
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer
+}
+
+type UserEventsBufferedWriter struct {
+	client Client
+	opts   []option.Option
+	writer *BufferedWriter
+}
+
+// Write puts the user event into buffer, it blocks when buffer is full
+func (receiver *UserEventsBufferedWriter) Write(userEvent *UserEvent) error {
+	return receiver.writer.Write(userEvent)
+}
+
+// Close flushes all buffered user events, and stops accepting new ones
+func (receiver *UserEventsBufferedWriter) Close() {
+	receiver.writer.Close()
+}
+
+func (receiver *UserEventsBufferedWriter) flush(records []interface{}) []error {
+	userEvents := make([]*UserEvent, len(records))
+	for i, record := range records {
+		userEvents[i] = record.(*UserEvent)
+	}
+	request := &WriteUserEventsRequest{UserEvents: userEvents}
+	// records are no more than one chunk, chunk error is also reported in response errors
+	response, _ := ChunkWriteUserEvents(receiver.client, request, 1, receiver.opts...)
+	errs := make([]error, len(records))
+	for _, userEventError := range response.Errors {
+		if userEventError.Index < 0 {
+			logs.Warn("user event error can't match any written one, msg:%s", userEventError.Message)
+			continue
+		}
+		errs[userEventError.Index] = errors.New(userEventError.Message)
+	}
+	return errs
+}
+
+func sizeOfUserEvent(record interface{}) int {
+	return proto.Size(record.(*UserEvent))
+}
@@ -1,7 +1,10 @@
 package retailv2
 
 import (
+	"context"
+
 	"github.com/byteplus-sdk/sdk-go/common"
+	"github.com/byteplus-sdk/sdk-go/core"
 	"github.com/byteplus-sdk/sdk-go/core/option"
 	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
 )
@@ -10,9 +13,32 @@ type Client interface {
 	common.Client
 
 	// Release
-	// release resources
+	// release resources, which drains client like Close
+	// with the deadline of core.DefaultReleaseTimeout, so it
+	// blocks for up to 10 seconds if calls are still in flight.
+	// Use Close to drain with a deadline of your own.
 	Release()
 
+	// Close
+	//
+	// Stops accepting new calls, and waits for the calls in flight and
+	// the background workers until ctx is done, *core.CloseTimeoutError naming
+	// the stage not drained is returned if they're not drained in time, which
+	// matches core.ErrCloseTimeout by errors.Is. It could be called more than once.
+	Close(ctx context.Context) error
+
+	// Diagnostics
+	//
+	// Gets the snapshot of the hosts, calls in flight and recent errors of client,
+	// which could be served as JSON by core.DiagnosticsHandler.
+	Diagnostics() *core.Diagnostics
+
+	// SceneRegistry
+	//
+	// Gets the registry of scenes declared by ClientBuilder.Scenes,
+	// the admin tooling could inspect or register scenes through it.
+	SceneRegistry() *core.SceneRegistry
+
 	// WriteUsers
 	//
 	// Writes at most 100 users at a time. Exceeding 100 in a request protocol.results protocol.in
@@ -1,7 +1,7 @@
 package retailv2
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 
@@ -14,7 +14,7 @@ import (
 
 var (
 	writeMsgFormat  = "Only can receive max to %d items in one write request"
-	writeTooManyErr = errors.New(fmt.Sprintf(writeMsgFormat, MaxWriteItemCount))
+	writeTooManyErr = NewPermanentError(fmt.Sprintf(writeMsgFormat, MaxWriteItemCount))
 )
 
 type clientImpl struct {
@@ -22,10 +22,31 @@ type clientImpl struct {
 	hCaller *HttpCaller
 	ru      *retailURL
 	hostAva *HostAvailabler
+	scenes  *SceneRegistry
+	// validate requests before sending, see ClientBuilder.ValidateRequests
+	validate bool
+	// drain the components depending on client when released
+	hooks ReleaseHooks
 }
 
 func (c *clientImpl) Release() {
-	c.hostAva.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReleaseTimeout)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		logs.Warn("release client fail, err:%s", err.Error())
+	}
+}
+
+func (c *clientImpl) Close(ctx context.Context) error {
+	return CloseClient(ctx, &c.hooks, c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) Diagnostics() *Diagnostics {
+	return NewDiagnostics(c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) SceneRegistry() *SceneRegistry {
+	return c.scenes
 }
 
 func (c *clientImpl) WriteUsers(request *WriteUsersRequest,
@@ -33,9 +54,14 @@ func (c *clientImpl) WriteUsers(request *WriteUsersRequest,
 	if len(request.Users) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateUsers(request.GetUsers()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeUsersURL
 	response := &WriteUsersResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +74,14 @@ func (c *clientImpl) WriteProducts(request *WriteProductsRequest,
 	if len(request.Products) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateProducts(request.GetProducts()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeProductsURL
 	response := &WriteProductsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +94,14 @@ func (c *clientImpl) WriteUserEvents(request *WriteUserEventsRequest,
 	if len(request.UserEvents) > MaxWriteItemCount {
 		return nil, writeTooManyErr
 	}
+	if c.validate {
+		if err := ValidateUserEvents(request.GetUserEvents()); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.writeUserEventsURL
 	response := &WriteUserEventsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +111,29 @@ func (c *clientImpl) WriteUserEvents(request *WriteUserEventsRequest,
 
 func (c *clientImpl) Predict(request *PredictRequest, scene string,
 	opts ...option.Option) (*PredictResponse, error) {
-	url := strings.ReplaceAll(c.ru.predictURLFormat, "{}", scene)
+	// validated before Execute, the invalid request is not retried with fallback scenes
+	if c.validate {
+		if err := ValidatePredictRequest(request); err != nil {
+			return nil, err
+		}
+	}
+	var response *PredictResponse
+	err := c.scenes.Execute(scene, func(sceneConf *SceneConfig) error {
+		var err error
+		response, err = c.doPredict(ApplySceneDefaults(request, sceneConf).(*PredictRequest), sceneConf, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *clientImpl) doPredict(request *PredictRequest, scene *SceneConfig,
+	opts []option.Option) (*PredictResponse, error) {
+	url := strings.ReplaceAll(c.ru.predictURLFormat, "{}", scene.Name)
 	response := &PredictResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(scene.WithOptions(opts)...))
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +143,14 @@ func (c *clientImpl) Predict(request *PredictRequest, scene string,
 
 func (c *clientImpl) AckServerImpressions(request *AckServerImpressionsRequest,
 	opts ...option.Option) (*AckServerImpressionsResponse, error) {
+	if c.validate {
+		if err := ValidateAckServerImpressionsRequest(request); err != nil {
+			return nil, err
+		}
+	}
 	url := c.ru.ackImpressionURL
 	response := &AckServerImpressionsResponse{}
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
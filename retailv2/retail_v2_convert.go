@@ -0,0 +1,111 @@
+package retailv2
+
+import (
+	retail "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// The messages of retail and retailv2 have the same fields and numbers,
+// so they're converted by the wire format, the unknown fields are kept.
+
+func FromRetailUsers(users []*retail.User) ([]*User, error) {
+	result := make([]*User, len(users))
+	for i, user := range users {
+		result[i] = &User{}
+		if err := convertMessage(user, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func ToRetailUsers(users []*User) ([]*retail.User, error) {
+	result := make([]*retail.User, len(users))
+	for i, user := range users {
+		result[i] = &retail.User{}
+		if err := convertMessage(user, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func FromRetailProducts(products []*retail.Product) ([]*Product, error) {
+	result := make([]*Product, len(products))
+	for i, product := range products {
+		result[i] = &Product{}
+		if err := convertMessage(product, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func ToRetailProducts(products []*Product) ([]*retail.Product, error) {
+	result := make([]*retail.Product, len(products))
+	for i, product := range products {
+		result[i] = &retail.Product{}
+		if err := convertMessage(product, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func FromRetailUserEvents(userEvents []*retail.UserEvent) ([]*UserEvent, error) {
+	result := make([]*UserEvent, len(userEvents))
+	for i, userEvent := range userEvents {
+		result[i] = &UserEvent{}
+		if err := convertMessage(userEvent, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func ToRetailUserEvents(userEvents []*UserEvent) ([]*retail.UserEvent, error) {
+	result := make([]*retail.UserEvent, len(userEvents))
+	for i, userEvent := range userEvents {
+		result[i] = &retail.UserEvent{}
+		if err := convertMessage(userEvent, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// FromRetailPredictRequest converts the predict request of retail,
+// the scene of retail client is passed to Predict as it is
+func FromRetailPredictRequest(request *retail.PredictRequest) (*PredictRequest, error) {
+	result := &PredictRequest{}
+	if err := convertMessage(request, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ToRetailPredictResponse(response *PredictResponse) (*retail.PredictResponse, error) {
+	result := &retail.PredictResponse{}
+	if err := convertMessage(response, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func FromRetailAckServerImpressionsRequest(
+	request *retail.AckServerImpressionsRequest) (*AckServerImpressionsRequest, error) {
+	result := &AckServerImpressionsRequest{}
+	if err := convertMessage(request, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func convertMessage(from proto.Message, to proto.Message) error {
+	bytes, err := proto.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(bytes, to)
+}
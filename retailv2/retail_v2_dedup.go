@@ -0,0 +1,73 @@
+package retailv2
+
+import (
+	"strconv"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+// UserEventKeyFunc returns the identity of user event for deduplication
+type UserEventKeyFunc func(userEvent *UserEvent) string
+
+// DefaultUserEventKey identifies user event by user, event type, product and timestamp
+func DefaultUserEventKey(userEvent *UserEvent) string {
+	return JoinDedupKey(userEvent.GetUserId(), userEvent.GetEventType(),
+		userEvent.GetProductId(), strconv.FormatInt(userEvent.GetEventTimestamp(), 10))
+}
+
+// NewUserEventDeduper
+//
+// Creates a deduper dropping the user events sent within the window of config,
+// the server doesn't deduplicate user events. Key is DefaultUserEventKey if nil.
+func NewUserEventDeduper(client Client, config *DedupConfig, key UserEventKeyFunc) *UserEventDeduper {
+	if key == nil {
+		key = DefaultUserEventKey
+	}
+	return &UserEventDeduper{client: client, key: key, deduplicator: NewDeduplicator(config)}
+}
+
+// UserEventDeduper drops duplicated user events before WriteUserEvents,
+// the events failed to write are forgotten, so that they could be retried
+type UserEventDeduper struct {
+	client       Client
+	key          UserEventKeyFunc
+	deduplicator *Deduplicator
+}
+
+// WriteUserEvents writes the user events not sent before,
+// success is returned without calling server if all of them are duplicated
+func (receiver *UserEventDeduper) WriteUserEvents(request *WriteUserEventsRequest,
+	opts ...option.Option) (*WriteUserEventsResponse, error) {
+	userEvents := request.GetUserEvents()
+	keys := make([]string, len(userEvents))
+	for i, userEvent := range userEvents {
+		keys[i] = receiver.key(userEvent)
+	}
+	var response *WriteUserEventsResponse
+	sent, err := receiver.deduplicator.Write(keys, func(kept []int) (*Status, []string, error) {
+		keptEvents := make([]*UserEvent, len(kept))
+		for i, idx := range kept {
+			keptEvents[i] = userEvents[idx]
+		}
+		dedupRequest := &WriteUserEventsRequest{UserEvents: keptEvents, Extra: request.GetExtra()}
+		var err error
+		response, err = receiver.client.WriteUserEvents(dedupRequest, opts...)
+		failedKeys := make([]string, len(response.GetErrors()))
+		for i, userEventError := range response.GetErrors() {
+			failedKeys[i] = receiver.key(userEventError.GetUserEvent())
+		}
+		return response.GetStatus(), failedKeys, err
+	})
+	if !sent {
+		return &WriteUserEventsResponse{Status: &Status{Code: StatusCodeSuccess}}, nil
+	}
+	return response, err
+}
+
+// Dropped returns the count of duplicated user events dropped
+func (receiver *UserEventDeduper) Dropped() int64 {
+	return receiver.deduplicator.Dropped()
+}
@@ -0,0 +1,215 @@
+package retailv2
+
+import (
+	"io"
+	"math"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+func NewUserJsonlReader(reader io.Reader) *UserReader {
+	return &UserReader{NewRecordBatchReader(NewJsonlReader(reader, func() proto.Message { return &User{} }))}
+}
+
+func NewUserCsvReader(reader io.Reader, mapping *CsvMapping) *UserReader {
+	return &UserReader{NewRecordBatchReader(NewCsvReader(reader, mapping, func() proto.Message { return &User{} }))}
+}
+
+func NewProductJsonlReader(reader io.Reader) *ProductReader {
+	return &ProductReader{NewRecordBatchReader(NewJsonlReader(reader, func() proto.Message { return &Product{} }))}
+}
+
+// NewProductCsvReader reads products from csv, the nested fields are mapped by path,
+// such as "price.current_price", "categories[0].category_nodes[0].id_or_name", "extra.color"
+func NewProductCsvReader(reader io.Reader, mapping *CsvMapping) *ProductReader {
+	return &ProductReader{NewRecordBatchReader(NewCsvReader(reader, mapping, func() proto.Message { return &Product{} }))}
+}
+
+func NewUserEventJsonlReader(reader io.Reader) *UserEventReader {
+	return &UserEventReader{NewRecordBatchReader(NewJsonlReader(reader, func() proto.Message { return &UserEvent{} }))}
+}
+
+func NewUserEventCsvReader(reader io.Reader, mapping *CsvMapping) *UserEventReader {
+	return &UserEventReader{NewRecordBatchReader(NewCsvReader(reader, mapping, func() proto.Message { return &UserEvent{} }))}
+}
+
+// UserReader reads users one by one or batch by batch, the lines
+// can't be parsed are skipped and reported by LineErrors
+type UserReader struct {
+	*RecordBatchReader
+}
+
+// Read returns the next user, see MessageReader for the errors
+func (receiver *UserReader) Read() (*User, error) {
+	message, err := receiver.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return message.(*User), nil
+}
+
+// ReadBatch reads at most size users, io.EOF is returned with the last batch
+func (receiver *UserReader) ReadBatch(size int) ([]*User, error) {
+	var users []*User
+	err := receiver.ReadMessages(size, func(message proto.Message) {
+		users = append(users, message.(*User))
+	})
+	return users, err
+}
+
+// ReadAll reads all users, the lines can't be parsed are skipped
+func (receiver *UserReader) ReadAll() ([]*User, error) {
+	users, err := receiver.ReadBatch(math.MaxInt32)
+	if err == io.EOF {
+		return users, nil
+	}
+	return users, err
+}
+
+// ProductReader reads products one by one or batch by batch, the lines
+// can't be parsed are skipped and reported by LineErrors
+type ProductReader struct {
+	*RecordBatchReader
+}
+
+// Read returns the next product, see MessageReader for the errors
+func (receiver *ProductReader) Read() (*Product, error) {
+	message, err := receiver.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return message.(*Product), nil
+}
+
+// ReadBatch reads at most size products, io.EOF is returned with the last batch
+func (receiver *ProductReader) ReadBatch(size int) ([]*Product, error) {
+	var products []*Product
+	err := receiver.ReadMessages(size, func(message proto.Message) {
+		products = append(products, message.(*Product))
+	})
+	return products, err
+}
+
+// ReadAll reads all products, the lines can't be parsed are skipped
+func (receiver *ProductReader) ReadAll() ([]*Product, error) {
+	products, err := receiver.ReadBatch(math.MaxInt32)
+	if err == io.EOF {
+		return products, nil
+	}
+	return products, err
+}
+
+// UserEventReader reads user events one by one or batch by batch, the lines
+// can't be parsed are skipped and reported by LineErrors
+type UserEventReader struct {
+	*RecordBatchReader
+}
+
+// Read returns the next user event, see MessageReader for the errors
+func (receiver *UserEventReader) Read() (*UserEvent, error) {
+	message, err := receiver.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return message.(*UserEvent), nil
+}
+
+// ReadBatch reads at most size user events, io.EOF is returned with the last batch
+func (receiver *UserEventReader) ReadBatch(size int) ([]*UserEvent, error) {
+	var userEvents []*UserEvent
+	err := receiver.ReadMessages(size, func(message proto.Message) {
+		userEvents = append(userEvents, message.(*UserEvent))
+	})
+	return userEvents, err
+}
+
+// ReadAll reads all user events, the lines can't be parsed are skipped
+func (receiver *UserEventReader) ReadAll() ([]*UserEvent, error) {
+	userEvents, err := receiver.ReadBatch(math.MaxInt32)
+	if err == io.EOF {
+		return userEvents, nil
+	}
+	return userEvents, err
+}
+
+// WriteUsersFrom
+//
+// Reads users batch by batch and writes them by ChunkWriteUsers.
+// The Index of errors is the position of user in the successfully parsed users,
+// the lines can't be parsed are reported by reader.LineErrors().
+// Reading stops at the first read error, which is returned.
+func WriteUsersFrom(client Client, reader *UserReader, concurrency int,
+	opts ...option.Option) (*ChunkWriteUsersResponse, error) {
+	result := &ChunkWriteUsersResponse{}
+	var users []*User
+	read := func(size int) (int, error) {
+		var err error
+		users, err = reader.ReadBatch(size)
+		return len(users), err
+	}
+	var err error
+	result.Status, err = WriteBatches(read, func(batch Chunk) (*Status, error) {
+		response, err := ChunkWriteUsers(client, &WriteUsersRequest{Users: users},
+			concurrency, ChunkOptions(opts, batch)...)
+		for _, userError := range response.Errors {
+			result.Errors = append(result.Errors, &IndexedUserError{
+				Index: OffsetIndex(userError.Index, batch.Start), UserError: userError.UserError})
+		}
+		return response.Status, err
+	})
+	return result, err
+}
+
+// WriteProductsFrom
+//
+// Same as WriteUsersFrom, but writes products.
+func WriteProductsFrom(client Client, reader *ProductReader, concurrency int,
+	opts ...option.Option) (*ChunkWriteProductsResponse, error) {
+	result := &ChunkWriteProductsResponse{}
+	var products []*Product
+	read := func(size int) (int, error) {
+		var err error
+		products, err = reader.ReadBatch(size)
+		return len(products), err
+	}
+	var err error
+	result.Status, err = WriteBatches(read, func(batch Chunk) (*Status, error) {
+		response, err := ChunkWriteProducts(client, &WriteProductsRequest{Products: products},
+			concurrency, ChunkOptions(opts, batch)...)
+		for _, productError := range response.Errors {
+			result.Errors = append(result.Errors, &IndexedProductError{
+				Index: OffsetIndex(productError.Index, batch.Start), ProductError: productError.ProductError})
+		}
+		return response.Status, err
+	})
+	return result, err
+}
+
+// WriteUserEventsFrom
+//
+// Same as WriteUsersFrom, but writes user events.
+func WriteUserEventsFrom(client Client, reader *UserEventReader, concurrency int,
+	opts ...option.Option) (*ChunkWriteUserEventsResponse, error) {
+	result := &ChunkWriteUserEventsResponse{}
+	var userEvents []*UserEvent
+	read := func(size int) (int, error) {
+		var err error
+		userEvents, err = reader.ReadBatch(size)
+		return len(userEvents), err
+	}
+	var err error
+	result.Status, err = WriteBatches(read, func(batch Chunk) (*Status, error) {
+		response, err := ChunkWriteUserEvents(client, &WriteUserEventsRequest{UserEvents: userEvents},
+			concurrency, ChunkOptions(opts, batch)...)
+		for _, userEventError := range response.Errors {
+			result.Errors = append(result.Errors, &IndexedUserEventError{
+				Index: OffsetIndex(userEventError.Index, batch.Start), UserEventError: userEventError.UserEventError})
+		}
+		return response.Status, err
+	})
+	return result, err
+}
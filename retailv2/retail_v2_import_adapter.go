@@ -0,0 +1,259 @@
+package retailv2
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	retail "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+const (
+	defaultImportMaxErrorSamples = 100
+
+	// The status code of import response when a part of items failed
+	importPartialFailureCode = 1001
+)
+
+type ImportAdapterConfig struct {
+	// Max count of write requests sent at the same time, default 1
+	Concurrency int
+
+	// Max count of error samples kept in response, default 100
+	MaxErrorSamples int
+
+	// Called after every batch written, done is the count of items written
+	Progress func(done int, total int)
+}
+
+// NewImportAdapter
+//
+// Creates an adapter which takes the import requests of retail,
+// so that the import jobs of retail could run on retailv2 without change.
+func NewImportAdapter(client Client, config *ImportAdapterConfig) *ImportAdapter {
+	adapter := &ImportAdapter{client: client}
+	if config != nil {
+		adapter.config = *config
+	}
+	if adapter.config.Concurrency <= 0 {
+		adapter.config.Concurrency = 1
+	}
+	if adapter.config.MaxErrorSamples <= 0 {
+		adapter.config.MaxErrorSamples = defaultImportMaxErrorSamples
+	}
+	return adapter
+}
+
+// ImportAdapter writes the items of retail import request by chunks of 100 items,
+// and returns the result shaped like the unpacked import response and metadata of
+// retail operation. Every write carries the date of DateConfig, and if is_end is
+// set, the end of date is sent by an extra empty write after all writes succeed.
+type ImportAdapter struct {
+	client Client
+	config ImportAdapterConfig
+}
+
+type ImportUsersResult struct {
+	Response *retail.ImportUsersResponse
+	Metadata *Metadata
+}
+
+type ImportProductsResult struct {
+	Response *retail.ImportProductsResponse
+	Metadata *Metadata
+}
+
+type ImportUserEventsResult struct {
+	Response *retail.ImportUserEventsResponse
+	Metadata *Metadata
+}
+
+// importBatchFunc writes the items of batch, and returns the count of failed items
+type importBatchFunc func(batch Chunk, opts []option.Option) (int, error)
+
+// importEndFunc sends an empty write with the end of date
+type importEndFunc func(opts []option.Option) (*Status, error)
+
+// ImportUsers
+//
+// Writes all users of request, the first error of writes
+// is returned together with the result.
+func (receiver *ImportAdapter) ImportUsers(request *retail.ImportUsersRequest,
+	opts ...option.Option) (*ImportUsersResult, error) {
+	users, err := FromRetailUsers(request.GetInputConfig().GetUsersInlineSource().GetUsers())
+	if err != nil {
+		return nil, err
+	}
+	response := &retail.ImportUsersResponse{}
+	write := func(batch Chunk, opts []option.Option) (int, error) {
+		batchRequest := &WriteUsersRequest{Users: users[batch.Start:batch.End], Extra: request.GetExtra()}
+		writeResponse, err := ChunkWriteUsers(receiver.client, batchRequest, receiver.config.Concurrency, opts...)
+		room := receiver.sampleRoom(len(response.ErrorSamples), len(writeResponse.Errors))
+		for _, userError := range writeResponse.Errors[:room] {
+			sample := &retail.UserError{Message: userError.GetMessage(), User: &retail.User{}}
+			// the messages are wire compatible, the conversion never fails
+			_ = convertMessage(userError.GetUser(), sample.User)
+			response.ErrorSamples = append(response.ErrorSamples, sample)
+		}
+		return len(writeResponse.Errors), err
+	}
+	end := func(opts []option.Option) (*Status, error) {
+		writeResponse, err := receiver.client.WriteUsers(&WriteUsersRequest{Extra: request.GetExtra()}, opts...)
+		return writeResponse.GetStatus(), err
+	}
+	metadata, err := receiver.run(len(users), request.GetDateConfig(), write, end, opts)
+	if metadata == nil {
+		return nil, err
+	}
+	response.Status = importStatus(metadata)
+	return &ImportUsersResult{Response: response, Metadata: metadata}, err
+}
+
+// ImportProducts
+//
+// Writes all products of request, the first error of writes
+// is returned together with the result.
+func (receiver *ImportAdapter) ImportProducts(request *retail.ImportProductsRequest,
+	opts ...option.Option) (*ImportProductsResult, error) {
+	products, err := FromRetailProducts(request.GetInputConfig().GetProductsInlineSource().GetProducts())
+	if err != nil {
+		return nil, err
+	}
+	response := &retail.ImportProductsResponse{}
+	write := func(batch Chunk, opts []option.Option) (int, error) {
+		batchRequest := &WriteProductsRequest{Products: products[batch.Start:batch.End], Extra: request.GetExtra()}
+		writeResponse, err := ChunkWriteProducts(receiver.client, batchRequest, receiver.config.Concurrency, opts...)
+		room := receiver.sampleRoom(len(response.ErrorSamples), len(writeResponse.Errors))
+		for _, productError := range writeResponse.Errors[:room] {
+			sample := &retail.ProductError{Message: productError.GetMessage(), Product: &retail.Product{}}
+			_ = convertMessage(productError.GetProduct(), sample.Product)
+			response.ErrorSamples = append(response.ErrorSamples, sample)
+		}
+		return len(writeResponse.Errors), err
+	}
+	end := func(opts []option.Option) (*Status, error) {
+		writeResponse, err := receiver.client.WriteProducts(&WriteProductsRequest{Extra: request.GetExtra()}, opts...)
+		return writeResponse.GetStatus(), err
+	}
+	metadata, err := receiver.run(len(products), request.GetDateConfig(), write, end, opts)
+	if metadata == nil {
+		return nil, err
+	}
+	response.Status = importStatus(metadata)
+	return &ImportProductsResult{Response: response, Metadata: metadata}, err
+}
+
+// ImportUserEvents
+//
+// Writes all user events of request, the first error of writes
+// is returned together with the result.
+func (receiver *ImportAdapter) ImportUserEvents(request *retail.ImportUserEventsRequest,
+	opts ...option.Option) (*ImportUserEventsResult, error) {
+	userEvents, err := FromRetailUserEvents(request.GetInputConfig().GetUserEventsInlineSource().GetUserEvents())
+	if err != nil {
+		return nil, err
+	}
+	response := &retail.ImportUserEventsResponse{}
+	write := func(batch Chunk, opts []option.Option) (int, error) {
+		batchRequest := &WriteUserEventsRequest{UserEvents: userEvents[batch.Start:batch.End], Extra: request.GetExtra()}
+		writeResponse, err := ChunkWriteUserEvents(receiver.client, batchRequest, receiver.config.Concurrency, opts...)
+		room := receiver.sampleRoom(len(response.ErrorSamples), len(writeResponse.Errors))
+		for _, userEventError := range writeResponse.Errors[:room] {
+			sample := &retail.UserEventError{Message: userEventError.GetMessage(), UserEvent: &retail.UserEvent{}}
+			_ = convertMessage(userEventError.GetUserEvent(), sample.UserEvent)
+			response.ErrorSamples = append(response.ErrorSamples, sample)
+		}
+		return len(writeResponse.Errors), err
+	}
+	end := func(opts []option.Option) (*Status, error) {
+		writeResponse, err := receiver.client.WriteUserEvents(&WriteUserEventsRequest{Extra: request.GetExtra()}, opts...)
+		return writeResponse.GetStatus(), err
+	}
+	metadata, err := receiver.run(len(userEvents), request.GetDateConfig(), write, end, opts)
+	if metadata == nil {
+		return nil, err
+	}
+	response.Status = importStatus(metadata)
+	return &ImportUserEventsResult{Response: response, Metadata: metadata}, err
+}
+
+// run writes the items batch by batch, so that the progress could be reported,
+// every batch is written by `concurrency` goroutines. Nil metadata is returned
+// if nothing is written as the DateConfig is invalid
+func (receiver *ImportAdapter) run(total int, dateConfig *retail.DateConfig,
+	write importBatchFunc, end importEndFunc, opts []option.Option) (*Metadata, error) {
+	opts, err := withImportDate(opts, dateConfig)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &Metadata{Date: dateConfig.GetDate(), TotalCount: int64(total)}
+	batches := SplitChunks(total, MaxWriteItemCount*receiver.config.Concurrency)
+	var firstErr error
+	for _, batch := range batches {
+		failed, err := write(batch, ChunkOptions(opts, batch))
+		if firstErr == nil {
+			firstErr = err
+		}
+		metadata.FailureCount += int64(failed)
+		if receiver.config.Progress != nil {
+			receiver.config.Progress(batch.End, total)
+		}
+	}
+	metadata.SuccessCount = metadata.TotalCount - metadata.FailureCount
+	if firstErr != nil || !dateConfig.GetIsEnd() {
+		return metadata, firstErr
+	}
+	endChunk := Chunk{Index: len(batches), Start: total, End: total}
+	status, err := end(ChunkOptions(append(opts, option.WithDateEnd(true)), endChunk))
+	if err == nil && status.GetCode() != StatusCodeSuccess {
+		err = errors.New(fmt.Sprintf("[ImportAdapter] send end of date fail, code:%d msg:%s",
+			status.GetCode(), status.GetMessage()))
+	}
+	return metadata, err
+}
+
+// withImportDate appends the date of dateConfig to a copy of opts,
+// the writes without date take effect in real time
+func withImportDate(opts []option.Option, dateConfig *retail.DateConfig) ([]option.Option, error) {
+	if dateConfig.GetDate() == "" {
+		if dateConfig.GetIsEnd() {
+			return nil, NewPermanentError("is_end of import requires date")
+		}
+		return opts, nil
+	}
+	date, err := time.Parse("2006-01-02", dateConfig.GetDate())
+	if err != nil {
+		return nil, NewPermanentError(fmt.Sprintf("invalid date of import '%s', expect like 2021-06-10",
+			dateConfig.GetDate()))
+	}
+	result := make([]option.Option, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, option.WithDataDate(date)), nil
+}
+
+func importStatus(metadata *Metadata) *Status {
+	if metadata.GetFailureCount() == 0 {
+		return &Status{Code: StatusCodeSuccess}
+	}
+	return &Status{
+		Code: importPartialFailureCode,
+		Message: fmt.Sprintf("%d of %d items failed",
+			metadata.GetFailureCount(), metadata.GetTotalCount()),
+	}
+}
+
+// sampleRoom returns how many of the added error samples could be kept in response
+func (receiver *ImportAdapter) sampleRoom(current int, added int) int {
+	room := receiver.config.MaxErrorSamples - current
+	if room < 0 {
+		return 0
+	}
+	if room > added {
+		return added
+	}
+	return room
+}
@@ -0,0 +1,124 @@
+package retailv2
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	retailsdk "github.com/byteplus-sdk/sdk-go/retail"
+	retail "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+// fakeWriteClient rejects the users whose id ends with "9"
+// and records the options of every write
+type fakeWriteClient struct {
+	Client
+	lock    sync.Mutex
+	writes  int
+	options []*option.Options
+}
+
+func (c *fakeWriteClient) WriteUsers(request *WriteUsersRequest,
+	opts ...option.Option) (*WriteUsersResponse, error) {
+	c.lock.Lock()
+	c.writes++
+	c.options = append(c.options, option.Conv2Options(opts...))
+	c.lock.Unlock()
+	response := &WriteUsersResponse{Status: &Status{Code: 0}}
+	for _, user := range request.GetUsers() {
+		if user.GetUserId()[len(user.GetUserId())-1] == '9' {
+			response.Status.Code = 1001
+			response.Errors = append(response.Errors, &UserError{Message: "invalid", User: user})
+		}
+	}
+	return response, nil
+}
+
+func TestImportAdapterImportUsers(t *testing.T) {
+	users := make([]*retail.User, 250)
+	for i := range users {
+		users[i] = &retail.User{UserId: strconv.Itoa(i), Extra: map[string]string{"k": "v"}}
+	}
+	request := &retail.ImportUsersRequest{
+		InputConfig: &retail.UsersInputConfig{Source: &retail.UsersInputConfig_UsersInlineSource{
+			UsersInlineSource: &retail.UsersInlineSource{Users: users},
+		}},
+		DateConfig: &retail.DateConfig{Date: "2021-06-10"},
+	}
+	client := &fakeWriteClient{}
+	var progress []int
+	adapter := NewImportAdapter(client, &ImportAdapterConfig{
+		Concurrency:     2,
+		MaxErrorSamples: 10,
+		Progress:        func(done int, total int) { progress = append(progress, done) },
+	})
+	result, err := adapter.ImportUsers(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.writes != 3 || len(progress) != 2 || progress[0] != 200 || progress[1] != 250 {
+		t.Fatalf("unexpected writes:%d progress:%v", client.writes, progress)
+	}
+	date := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	for _, options := range client.options {
+		if !options.DataDate.Equal(date) || options.DataIsEnd {
+			t.Fatalf("expect every write dated without end, got %+v", options)
+		}
+	}
+	metadata := result.Metadata
+	if metadata.TotalCount != 250 || metadata.FailureCount != 25 || metadata.SuccessCount != 225 {
+		t.Fatalf("unexpected metadata %v", metadata)
+	}
+	response := result.Response
+	if response.GetStatus().GetCode() != 1001 || len(response.GetErrorSamples()) != 10 {
+		t.Fatalf("unexpected response %v", response)
+	}
+	if sample := response.GetErrorSamples()[0].GetUser(); sample.GetUserId() != "9" || sample.GetExtra()["k"] != "v" {
+		t.Fatalf("unexpected sample %v", sample)
+	}
+}
+
+func TestImportAdapterRetailImporter(t *testing.T) {
+	users := make([]*retail.User, 150)
+	for i := range users {
+		users[i] = &retail.User{UserId: strconv.Itoa(i)}
+	}
+	request := &retail.ImportUsersRequest{
+		InputConfig: &retail.UsersInputConfig{Source: &retail.UsersInputConfig_UsersInlineSource{
+			UsersInlineSource: &retail.UsersInlineSource{Users: users},
+		}},
+		DateConfig: &retail.DateConfig{Date: "2021-06-10", IsEnd: true},
+	}
+	client := &fakeWriteClient{}
+	adapter := NewImportAdapter(client, nil)
+	report, err := retailsdk.NewImporter(adapter.RetailImportClient(), nil).ImportUsers(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Finalized || report.TotalCount != 150 || report.FailureCount != 15 || len(report.ErrorSamples) != 15 {
+		t.Fatalf("unexpected report %+v", report.ImportReport)
+	}
+	// two writes of users, then the end of date once
+	date := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	if len(client.options) != 3 {
+		t.Fatalf("expect 3 writes, got %d", len(client.options))
+	}
+	for i, options := range client.options {
+		if !options.DataDate.Equal(date) || options.DataIsEnd != (i == 2) {
+			t.Errorf("unexpected options of write %d: %+v", i, options)
+		}
+	}
+
+	request.DateConfig = &retail.DateConfig{Date: "2021/06/10"}
+	if _, err := adapter.ImportUsers(request); !IsPermanentError(err) {
+		t.Errorf("expect permanent error of malformed date, got %v", err)
+	}
+	if len(client.options) != 3 {
+		t.Errorf("nothing should be written with malformed date")
+	}
+}
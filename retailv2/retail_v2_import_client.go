@@ -0,0 +1,101 @@
+package retailv2
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/byteplus-sdk/sdk-go/common"
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/retail"
+	retailpb "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// RetailImportClient
+//
+// Returns the adapter as retail.ImportClient, so that it could be used by retail.Importer.
+// Every import writes the items at once, and returns a done operation carrying the
+// result, which is kept until it's waited by WaitOperation.
+func (receiver *ImportAdapter) RetailImportClient() retail.ImportClient {
+	return &importClient{adapter: receiver, operations: make(map[string]*Operation)}
+}
+
+type importClient struct {
+	adapter    *ImportAdapter
+	lock       sync.Mutex
+	operations map[string]*Operation
+}
+
+func (receiver *importClient) ImportUsers(request *retailpb.ImportUsersRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	result, err := receiver.adapter.ImportUsers(request, opts...)
+	if result == nil {
+		return nil, err
+	}
+	return receiver.done(result.Response, result.Metadata, err)
+}
+
+func (receiver *importClient) ImportProducts(request *retailpb.ImportProductsRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	result, err := receiver.adapter.ImportProducts(request, opts...)
+	if result == nil {
+		return nil, err
+	}
+	return receiver.done(result.Response, result.Metadata, err)
+}
+
+func (receiver *importClient) ImportUserEvents(request *retailpb.ImportUserEventsRequest,
+	opts ...option.Option) (*OperationResponse, error) {
+	result, err := receiver.adapter.ImportUserEvents(request, opts...)
+	if result == nil {
+		return nil, err
+	}
+	return receiver.done(result.Response, result.Metadata, err)
+}
+
+// done synthesizes the operation of result, the error of writes is already
+// counted as the failed items in metadata, so it's only logged. The error
+// not counted, such as failing to send the end of date, is returned.
+func (receiver *importClient) done(response proto.Message, metadata *Metadata,
+	writeErr error) (*OperationResponse, error) {
+	if writeErr != nil {
+		if metadata.GetFailureCount() == 0 {
+			return nil, writeErr
+		}
+		logs.Warn("some writes of import fail, err:%s", writeErr.Error())
+	}
+	packed, err := anypb.New(response)
+	if err != nil {
+		return nil, err
+	}
+	operation := &Operation{
+		Name:     "retailv2-import-" + uuid.NewString(),
+		Metadata: metadata,
+		Done:     true,
+		Response: packed,
+	}
+	receiver.lock.Lock()
+	receiver.operations[operation.Name] = operation
+	receiver.lock.Unlock()
+	return &OperationResponse{Status: &Status{Code: StatusCodeSuccess}, Operation: operation}, nil
+}
+
+// WaitOperation returns the operation synthesized by import at once, and forgets it
+func (receiver *importClient) WaitOperation(name string, policy *common.WaitPolicy,
+	opts ...option.Option) (*common.OperationResult, error) {
+	receiver.lock.Lock()
+	operation, exist := receiver.operations[name]
+	delete(receiver.operations, name)
+	receiver.lock.Unlock()
+	if !exist {
+		return nil, errors.New(fmt.Sprintf("[WaitOperation] unknown operation, name:%s", name))
+	}
+	response, err := common.UnpackOperationResponse(operation)
+	return &common.OperationResult{Operation: operation, Response: response}, err
+}
@@ -0,0 +1,101 @@
+package retailv2
+
+import (
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewResubmitter
+//
+// Creates a resubmitter which resends the retryable failed items of writes with backoff,
+// and routes the permanent failures into the sink of config.
+func NewResubmitter(client Client, config *ResubmitConfig) *Resubmitter {
+	return &Resubmitter{client: client, runner: NewResubmitRunner(config)}
+}
+
+// Resubmitter writes at most 100 items at a time like Client, the opts of
+// writes should not contain request id, as every attempt needs a new one
+type Resubmitter struct {
+	client Client
+	runner *ResubmitRunner
+}
+
+func (receiver *Resubmitter) WriteUsers(request *WriteUsersRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	users := request.GetUsers()
+	item := func(index int) interface{} { return users[index] }
+	send := func(indexes []int, requestId string) (*Status, []*ItemFailure, error) {
+		subset := make([]*User, len(indexes))
+		for i, index := range indexes {
+			subset[i] = users[index]
+		}
+		subsetRequest := &WriteUsersRequest{Users: subset, Extra: request.GetExtra()}
+		response, err := receiver.client.WriteUsers(subsetRequest, ResubmitOptions(opts, requestId)...)
+		userErrors := response.GetErrors()
+		matched := MatchResubmitIndexes(indexes, len(userErrors), func(failedIdx, itemIdx int) bool {
+			failed, user := userErrors[failedIdx].GetUser(), users[itemIdx]
+			return proto.Equal(failed, user) || failed.GetUserId() == user.GetUserId()
+		})
+		failures := make([]*ItemFailure, len(userErrors))
+		for i, userError := range userErrors {
+			failures[i] = &ItemFailure{Index: matched[i], Item: userError.GetUser(), Message: userError.GetMessage()}
+		}
+		return response.GetStatus(), failures, err
+	}
+	return receiver.runner.Run(TopicUser, len(users), item, send)
+}
+
+func (receiver *Resubmitter) WriteProducts(request *WriteProductsRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	products := request.GetProducts()
+	item := func(index int) interface{} { return products[index] }
+	send := func(indexes []int, requestId string) (*Status, []*ItemFailure, error) {
+		subset := make([]*Product, len(indexes))
+		for i, index := range indexes {
+			subset[i] = products[index]
+		}
+		subsetRequest := &WriteProductsRequest{Products: subset, Extra: request.GetExtra()}
+		response, err := receiver.client.WriteProducts(subsetRequest, ResubmitOptions(opts, requestId)...)
+		productErrors := response.GetErrors()
+		matched := MatchResubmitIndexes(indexes, len(productErrors), func(failedIdx, itemIdx int) bool {
+			failed, product := productErrors[failedIdx].GetProduct(), products[itemIdx]
+			return proto.Equal(failed, product) || failed.GetProductId() == product.GetProductId()
+		})
+		failures := make([]*ItemFailure, len(productErrors))
+		for i, productError := range productErrors {
+			failures[i] = &ItemFailure{Index: matched[i],
+				Item: productError.GetProduct(), Message: productError.GetMessage()}
+		}
+		return response.GetStatus(), failures, err
+	}
+	return receiver.runner.Run(TopicProduct, len(products), item, send)
+}
+
+func (receiver *Resubmitter) WriteUserEvents(request *WriteUserEventsRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	userEvents := request.GetUserEvents()
+	item := func(index int) interface{} { return userEvents[index] }
+	send := func(indexes []int, requestId string) (*Status, []*ItemFailure, error) {
+		subset := make([]*UserEvent, len(indexes))
+		for i, index := range indexes {
+			subset[i] = userEvents[index]
+		}
+		subsetRequest := &WriteUserEventsRequest{UserEvents: subset, Extra: request.GetExtra()}
+		response, err := receiver.client.WriteUserEvents(subsetRequest, ResubmitOptions(opts, requestId)...)
+		userEventErrors := response.GetErrors()
+		matched := MatchResubmitIndexes(indexes, len(userEventErrors), func(failedIdx, itemIdx int) bool {
+			failed, userEvent := userEventErrors[failedIdx].GetUserEvent(), userEvents[itemIdx]
+			return proto.Equal(failed, userEvent) || isSameUserEvent(failed, userEvent)
+		})
+		failures := make([]*ItemFailure, len(userEventErrors))
+		for i, userEventError := range userEventErrors {
+			failures[i] = &ItemFailure{Index: matched[i],
+				Item: userEventError.GetUserEvent(), Message: userEventError.GetMessage()}
+		}
+		return response.GetStatus(), failures, err
+	}
+	return receiver.runner.Run(TopicUserEvent, len(userEvents), item, send)
+}
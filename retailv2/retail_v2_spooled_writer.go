@@ -0,0 +1,143 @@
+package retailv2
+
+import (
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	spoolMethodWriteUsers      = "WriteUsers"
+	spoolMethodWriteProducts   = "WriteProducts"
+	spoolMethodWriteUserEvents = "WriteUserEvents"
+)
+
+// NewSpooledWriter opens the spool in config.Dir, the requests that were
+// not sent successfully before last exit will be sent again through client.
+// The spool is closed when client released, or it could be closed by caller.
+// The opts are used by every request, so it should not contain request id.
+func NewSpooledWriter(client Client, config *SpoolConfig, opts ...option.Option) (*SpooledWriter, error) {
+	writer := &SpooledWriter{client: client, opts: opts}
+	spool, err := OpenSpool(config, writer.send)
+	if err != nil {
+		return nil, err
+	}
+	writer.spool = spool
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer, nil
+}
+
+// SpooledWriter persists write requests into local spool before sending them,
+// the request is deleted from spool after server returns success.
+// Requests with more than 100 items are split into chunks.
+type SpooledWriter struct {
+	client Client
+	opts   []option.Option
+	spool  *Spool
+}
+
+func (receiver *SpooledWriter) WriteUsers(request *WriteUsersRequest) error {
+	users := request.GetUsers()
+	for _, chunk := range SplitChunks(len(users), MaxWriteItemCount) {
+		chunkRequest := &WriteUsersRequest{Users: users[chunk.Start:chunk.End], Extra: request.GetExtra()}
+		if err := receiver.append(spoolMethodWriteUsers, chunkRequest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (receiver *SpooledWriter) WriteProducts(request *WriteProductsRequest) error {
+	products := request.GetProducts()
+	for _, chunk := range SplitChunks(len(products), MaxWriteItemCount) {
+		chunkRequest := &WriteProductsRequest{Products: products[chunk.Start:chunk.End], Extra: request.GetExtra()}
+		if err := receiver.append(spoolMethodWriteProducts, chunkRequest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (receiver *SpooledWriter) WriteUserEvents(request *WriteUserEventsRequest) error {
+	userEvents := request.GetUserEvents()
+	for _, chunk := range SplitChunks(len(userEvents), MaxWriteItemCount) {
+		chunkRequest := &WriteUserEventsRequest{UserEvents: userEvents[chunk.Start:chunk.End], Extra: request.GetExtra()}
+		if err := receiver.append(spoolMethodWriteUserEvents, chunkRequest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the count of requests not sent successfully
+func (receiver *SpooledWriter) Pending() int {
+	return receiver.spool.Pending()
+}
+
+// Close stops sending, the unsent requests will be sent after reopened
+func (receiver *SpooledWriter) Close() {
+	receiver.spool.Close()
+}
+
+func (receiver *SpooledWriter) append(method string, request proto.Message) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	payload, err := EncodeSpoolEnvelope(&SpoolEnvelope{
+		Method:    method,
+		RequestId: uuid.NewString(),
+		Body:      body,
+	})
+	if err != nil {
+		return err
+	}
+	return receiver.spool.Append(payload)
+}
+
+func (receiver *SpooledWriter) send(payload []byte) error {
+	envelope, err := DecodeSpoolEnvelope(payload)
+	if err != nil {
+		return skipSpooledRequest(nil, err)
+	}
+	opts := make([]option.Option, 0, len(receiver.opts)+1)
+	opts = append(opts, receiver.opts...)
+	opts = append(opts, option.WithRequestId(envelope.RequestId))
+	switch envelope.Method {
+	case spoolMethodWriteUsers:
+		request := &WriteUsersRequest{}
+		if err := proto.Unmarshal(envelope.Body, request); err != nil {
+			return skipSpooledRequest(envelope, err)
+		}
+		response, err := receiver.client.WriteUsers(request, opts...)
+		return SpoolSendResult(envelope, response.GetStatus(), err)
+	case spoolMethodWriteProducts:
+		request := &WriteProductsRequest{}
+		if err := proto.Unmarshal(envelope.Body, request); err != nil {
+			return skipSpooledRequest(envelope, err)
+		}
+		response, err := receiver.client.WriteProducts(request, opts...)
+		return SpoolSendResult(envelope, response.GetStatus(), err)
+	case spoolMethodWriteUserEvents:
+		request := &WriteUserEventsRequest{}
+		if err := proto.Unmarshal(envelope.Body, request); err != nil {
+			return skipSpooledRequest(envelope, err)
+		}
+		response, err := receiver.client.WriteUserEvents(request, opts...)
+		return SpoolSendResult(envelope, response.GetStatus(), err)
+	}
+	return skipSpooledRequest(envelope, errors.New("unknown method"))
+}
+
+// The broken request never succeeds, it's skipped to avoid blocking the later ones
+func skipSpooledRequest(envelope *SpoolEnvelope, err error) error {
+	logs.Error("skip broken spooled request, method:%s err:%s", envelope.GetMethod(), err.Error())
+	return nil
+}
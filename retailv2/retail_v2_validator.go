@@ -0,0 +1,82 @@
+package retailv2
+
+import (
+	"github.com/byteplus-sdk/sdk-go/retail"
+	retailpb "github.com/byteplus-sdk/sdk-go/retail/protocol"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+// The messages of retailv2 follow the same rules as retail, so they're
+// converted into the retail messages and checked by the retail validators.
+
+// The acceptable values of UserEvent.event_type
+const (
+	EventTypeImpression          = retail.EventTypeImpression
+	EventTypeClick               = retail.EventTypeClick
+	EventTypeAddToCart           = retail.EventTypeAddToCart
+	EventTypeRemoveFromCart      = retail.EventTypeRemoveFromCart
+	EventTypeAddToFavorites      = retail.EventTypeAddToFavorites
+	EventTypeRemoveFromFavorites = retail.EventTypeRemoveFromFavorites
+	EventTypePurchase            = retail.EventTypePurchase
+	EventTypeSearch              = retail.EventTypeSearch
+	EventTypeStayDetailPage      = retail.EventTypeStayDetailPage
+)
+
+// The acceptable values of AckServerImpressionsRequest.AlteredProduct.altered_reason
+const (
+	AlteredReasonKept     = retail.AlteredReasonKept
+	AlteredReasonFiltered = retail.AlteredReasonFiltered
+	AlteredReasonInserted = retail.AlteredReasonInserted
+)
+
+// ValidateUsers checks the rules documented in the proto of users,
+// FieldErrors is returned if any user is invalid
+func ValidateUsers(users []*User) error {
+	retailUsers, err := ToRetailUsers(users)
+	if err != nil {
+		return err
+	}
+	return retail.ValidateUsers(retailUsers)
+}
+
+// ValidateProducts checks the rules documented in the proto of products,
+// FieldErrors is returned if any product is invalid
+func ValidateProducts(products []*Product) error {
+	retailProducts, err := ToRetailProducts(products)
+	if err != nil {
+		return err
+	}
+	return retail.ValidateProducts(retailProducts)
+}
+
+// ValidateUserEvents checks the rules documented in the proto of user events,
+// such as product_id is required except search event, which requires context.query.
+// FieldErrors is returned if any user event is invalid
+func ValidateUserEvents(userEvents []*UserEvent) error {
+	retailUserEvents, err := ToRetailUserEvents(userEvents)
+	if err != nil {
+		return err
+	}
+	return retail.ValidateUserEvents(retailUserEvents)
+}
+
+// ValidatePredictRequest checks the fields of request, the FieldErrors is
+// reported with Index -1. Size is not checked as it may be filled by scene defaults
+func ValidatePredictRequest(request *PredictRequest) error {
+	retailRequest := &retailpb.PredictRequest{}
+	if err := convertMessage(request, retailRequest); err != nil {
+		return err
+	}
+	return retail.ValidatePredictRequest(retailRequest)
+}
+
+// ValidateAckServerImpressionsRequest checks the fields of request, such as
+// altered_reason is one of "kept", "filtered" and "inserted".
+// The FieldErrors is reported with Index -1
+func ValidateAckServerImpressionsRequest(request *AckServerImpressionsRequest) error {
+	retailRequest := &retailpb.AckServerImpressionsRequest{}
+	if err := convertMessage(request, retailRequest); err != nil {
+		return err
+	}
+	return retail.ValidateAckServerImpressionsRequest(retailRequest)
+}
@@ -0,0 +1,48 @@
+package retailv2
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	. "github.com/byteplus-sdk/sdk-go/retailv2/protocol"
+)
+
+func TestValidateUserEvents(t *testing.T) {
+	device := &UserEvent_Device{Platform: "app"}
+	userEvents := []*UserEvent{
+		{UserId: "u1", EventType: EventTypeClick, EventTimestamp: 1, ProductId: "p1",
+			Scene: &UserEvent_Scene{SceneName: "home"}, Device: device},
+		{UserId: "u2", EventType: EventTypeSearch, EventTimestamp: 1, ProductId: "p2", Device: device},
+	}
+	err := ValidateUserEvents(userEvents)
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("expect FieldErrors, got %v", err)
+	}
+	var paths []string
+	for _, fieldError := range fieldErrors {
+		paths = append(paths, fieldError.Error())
+	}
+	expected := []string{
+		"[1].product_id: should be empty for search event",
+		"[1].context.query: is required",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("unexpected errors %v", paths)
+	}
+}
+
+func TestPredictValidatedBeforeFallback(t *testing.T) {
+	scenes, err := NewSceneRegistry([]*SceneConfig{{Name: "home", Fallback: []string{"default"}}, {Name: "default"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the client has no caller, predicting with any scene panics
+	client := &clientImpl{scenes: scenes, validate: true}
+	_, err = client.Predict(&PredictRequest{Scene: &UserEvent_Scene{SceneName: "home"}}, "home")
+	fieldErrors, ok := err.(FieldErrors)
+	if !ok || len(fieldErrors) != 1 || fieldErrors[0].Path != "user_id" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
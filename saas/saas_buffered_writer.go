@@ -0,0 +1,85 @@
+package saas
+
+import (
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// DataCallback reports the result of data written by DataBufferedWriter,
+// err is nil if the data is accepted by server
+type DataCallback func(data string, err error)
+
+// NewUserEventsBufferedWriter creates a writer that buffers single json serialized
+// user event, and writes them in batch through client.WriteUserEvents.
+// The writer is drained when client released, or it could be closed by caller.
+// The opts are used by every flush, so it should not contain request id.
+func NewUserEventsBufferedWriter(client Client, projectId string, stage string,
+	config *BufferedWriterConfig, callback DataCallback, opts ...option.Option) *DataBufferedWriter {
+	writer := &DataBufferedWriter{
+		client:    client,
+		projectId: projectId,
+		stage:     stage,
+		opts:      opts,
+	}
+	var recordCallback RecordCallback
+	if callback != nil {
+		recordCallback = func(record interface{}, err error) {
+			callback(record.(string), err)
+		}
+	}
+	writer.writer = NewBufferedWriter(config, MaxImportWriteCount, writer.flush, sizeOfData, recordCallback)
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer
+}
+
+type DataBufferedWriter struct {
+	client    Client
+	projectId string
+	stage     string
+	opts      []option.Option
+	writer    *BufferedWriter
+}
+
+// Write puts the json serialized data into buffer, it blocks when buffer is full
+func (receiver *DataBufferedWriter) Write(data string) error {
+	return receiver.writer.Write(data)
+}
+
+// Close flushes all buffered data, and stops accepting new ones
+func (receiver *DataBufferedWriter) Close() {
+	receiver.writer.Close()
+}
+
+func (receiver *DataBufferedWriter) flush(records []interface{}) []error {
+	dataList := make([]string, len(records))
+	for i, record := range records {
+		dataList[i] = record.(string)
+	}
+	request := &protocol.WriteDataRequest{
+		ProjectId: receiver.projectId,
+		Stage:     receiver.stage,
+		Data:      dataList,
+	}
+	// records are no more than one chunk, chunk error is also reported in response errors
+	response, _ := ChunkWriteUserEvents(receiver.client, request, 1, receiver.opts...)
+	errs := make([]error, len(records))
+	for _, dataError := range response.Errors {
+		if dataError.Index < 0 {
+			logs.Warn("data error can't match any written one, msg:%s data:%s",
+				dataError.Message, dataError.Data)
+			continue
+		}
+		errs[dataError.Index] = errors.New(dataError.Message)
+	}
+	return errs
+}
+
+func sizeOfData(record interface{}) int {
+	return len(record.(string))
+}
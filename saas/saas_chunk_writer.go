@@ -0,0 +1,91 @@
+package saas
+
+import (
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// IndexedDataError is DataError with the index of data in the
+// caller's WriteDataRequest.Data, Index is -1 if it can't be matched
+type IndexedDataError struct {
+	Index int
+	*protocol.DataError
+}
+
+type ChunkWriteResponse struct {
+	// The first failure status of chunks, or success status if all chunks succeed
+	Status *Status
+	Errors []*IndexedDataError
+}
+
+// ChunkWriteUsers
+//
+// Splits users data into chunks of at most 2000 data, writes the chunks with at most
+// `concurrency` goroutines, then merges the responses of all chunks.
+// If a whole chunk fails, every data of it is reported in response errors,
+// and the first chunk error is returned together with the merged response.
+func ChunkWriteUsers(client Client, request *protocol.WriteDataRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteResponse, error) {
+	return chunkWriteData(client.WriteUsers, request, concurrency, opts)
+}
+
+// ChunkWriteProducts
+//
+// Same as ChunkWriteUsers, but writes products data.
+func ChunkWriteProducts(client Client, request *protocol.WriteDataRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteResponse, error) {
+	return chunkWriteData(client.WriteProducts, request, concurrency, opts)
+}
+
+// ChunkWriteUserEvents
+//
+// Same as ChunkWriteUsers, but writes user events data.
+func ChunkWriteUserEvents(client Client, request *protocol.WriteDataRequest, concurrency int,
+	opts ...option.Option) (*ChunkWriteResponse, error) {
+	return chunkWriteData(client.WriteUserEvents, request, concurrency, opts)
+}
+
+type writeDataFunc func(request *protocol.WriteDataRequest, opts ...option.Option) (*protocol.WriteResponse, error)
+
+func chunkWriteData(write writeDataFunc, request *protocol.WriteDataRequest,
+	concurrency int, opts []option.Option) (*ChunkWriteResponse, error) {
+	dataList := request.GetData()
+	responses := make([]*protocol.WriteResponse, ChunkCount(len(dataList), MaxImportWriteCount))
+	result := &ChunkWriteResponse{}
+	var err error
+	result.Status, err = WriteChunks(len(dataList), MaxImportWriteCount, concurrency, opts, &ChunkWriter{
+		Write: func(chunk Chunk, opts []option.Option) (*Status, int, error) {
+			chunkRequest := &protocol.WriteDataRequest{
+				ProjectId: request.GetProjectId(),
+				Stage:     request.GetStage(),
+				Data:      dataList[chunk.Start:chunk.End],
+				Extra:     request.GetExtra(),
+			}
+			response, err := write(chunkRequest, opts...)
+			responses[chunk.Index] = response
+			return response.GetStatus(), len(response.GetErrors()), err
+		},
+		FailChunk: func(chunk Chunk, message string) {
+			for i := chunk.Start; i < chunk.End; i++ {
+				dataError := &protocol.DataError{Message: message, Data: dataList[i]}
+				result.Errors = append(result.Errors, &IndexedDataError{Index: i, DataError: dataError})
+			}
+		},
+		CollectErrors: func(chunk Chunk) {
+			dataErrors := responses[chunk.Index].GetErrors()
+			failedData := make([]string, len(dataErrors))
+			for i, dataError := range dataErrors {
+				failedData[i] = dataError.GetData()
+			}
+			indexes := MatchJsonIndexes(chunk, failedData, func(itemIdx int) interface{} {
+				return NormalizeJsonString(dataList[itemIdx])
+			})
+			for i, dataError := range dataErrors {
+				result.Errors = append(result.Errors, &IndexedDataError{Index: indexes[i], DataError: dataError})
+			}
+		},
+	})
+	return result, err
+}
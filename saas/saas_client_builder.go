@@ -3,10 +3,12 @@ package saas
 import (
 	"github.com/byteplus-sdk/sdk-go/common"
 	"github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
 )
 
 type ClientBuilder struct {
-	param core.ContextParam
+	param  core.ContextParam
+	scenes []*core.SceneConfig
 }
 
 func (receiver *ClientBuilder) AK(ak string) *ClientBuilder {
@@ -54,6 +56,44 @@ func (receiver *ClientBuilder) Region(region core.Region) *ClientBuilder {
 	return receiver
 }
 
+// RecordMode makes client record calls into dir, or replay calls
+// recorded in dir without network, which is useful for offline tests
+func (receiver *ClientBuilder) RecordMode(mode core.RecordMode, dir string) *ClientBuilder {
+	receiver.param.RecordMode = mode
+	receiver.param.RecordDir = dir
+	return receiver
+}
+
+// Transport makes client share the hosts, connections and host health checking
+// of transport with the other clients, the host fields of builder are ignored
+func (receiver *ClientBuilder) Transport(transport *core.SharedTransport) *ClientBuilder {
+	receiver.param.Transport = transport
+	return receiver
+}
+
+// Config sets the non-empty values of config loaded by core.LoadClientConfig or
+// core.LoadClientConfigFromEnv, the timeouts of config are added to DefaultOptions
+func (receiver *ClientBuilder) Config(config *core.ClientConfig) *ClientBuilder {
+	config.FillContextParam(&receiver.param)
+	return receiver
+}
+
+// DefaultOptions sets the options applied to all calls of client, such as
+// stage, timeout or server timeout, the options of call override them,
+// and the headers and queries of both are merged
+func (receiver *ClientBuilder) DefaultOptions(opts ...option.Option) *ClientBuilder {
+	receiver.param.DefaultOptions = append(receiver.param.DefaultOptions, opts...)
+	return receiver
+}
+
+// Scenes declares the scenes allowed to predict with their defaults, the scene
+// is the Scene.scene_name of predict request, predicting with an undeclared
+// scene will be rejected once any scene is declared
+func (receiver *ClientBuilder) Scenes(scenes ...*core.SceneConfig) *ClientBuilder {
+	receiver.scenes = append(receiver.scenes, scenes...)
+	return receiver
+}
+
 const saasTenant = "saas"
 
 func (receiver *ClientBuilder) Build() (Client, error) {
@@ -62,6 +102,10 @@ func (receiver *ClientBuilder) Build() (Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	scenes, err := core.NewSceneRegistry(receiver.scenes)
+	if err != nil {
+		return nil, err
+	}
 	su := receiver.buildSaasURL(context)
 	httpCaller := core.NewHttpCaller(context)
 	client := &clientImpl{
@@ -69,6 +113,7 @@ func (receiver *ClientBuilder) Build() (Client, error) {
 		hCaller: httpCaller,
 		su:      su,
 		hostAva: core.NewHostAvailabler(su, context),
+		scenes:  scenes,
 	}
 	return client, nil
 }
@@ -1,19 +1,20 @@
 package saas
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/byteplus-sdk/sdk-go/common"
 	. "github.com/byteplus-sdk/sdk-go/core"
 	"github.com/byteplus-sdk/sdk-go/core/logs"
 	"github.com/byteplus-sdk/sdk-go/core/option"
 	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+	"google.golang.org/protobuf/proto"
 	"strings"
 )
 
 var (
 	writeMsgFormat  = "Only can receive max to %d items in one write request"
-	writeTooManyErr = errors.New(fmt.Sprintf(writeMsgFormat, MaxImportWriteCount))
+	writeTooManyErr = NewPermanentError(fmt.Sprintf(writeMsgFormat, MaxImportWriteCount))
 )
 
 const (
@@ -26,10 +27,29 @@ type clientImpl struct {
 	hCaller *HttpCaller
 	su      *saasURL
 	hostAva *HostAvailabler
+	scenes  *SceneRegistry
+	// drain the components depending on client when released
+	hooks ReleaseHooks
 }
 
 func (c *clientImpl) Release() {
-	c.hostAva.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReleaseTimeout)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		logs.Warn("release client fail, err:%s", err.Error())
+	}
+}
+
+func (c *clientImpl) Close(ctx context.Context) error {
+	return CloseClient(ctx, &c.hooks, c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) Diagnostics() *Diagnostics {
+	return NewDiagnostics(c.hCaller, c.hostAva)
+}
+
+func (c *clientImpl) SceneRegistry() *SceneRegistry {
+	return c.scenes
 }
 
 func checkProjectIdAndModelId(projectId string, modelId string) error {
@@ -48,7 +68,7 @@ func checkProjectIdAndModelId(projectId string, modelId string) error {
 	if modelId == "" {
 		emptyParams = append(emptyParams, errFieldModelId)
 	}
-	return errors.New(fmt.Sprintf(errMsgFormat, strings.Join(emptyParams, ",")))
+	return NewPermanentError(fmt.Sprintf(errMsgFormat, strings.Join(emptyParams, ",")))
 }
 
 func checkProjectIdAndStage(projectId string, stage string) error {
@@ -67,25 +87,25 @@ func checkProjectIdAndStage(projectId string, stage string) error {
 	if stage == "" {
 		emptyParams = append(emptyParams, errFieldStage)
 	}
-	return errors.New(fmt.Sprintf(errMsgFormat, strings.Join(emptyParams, ",")))
+	return NewPermanentError(fmt.Sprintf(errMsgFormat, strings.Join(emptyParams, ",")))
 }
 
+// addSaasFlag copies opts, as appending to opts may
+// overwrite the spare capacity of the slice passed by caller
 func addSaasFlag(opts []option.Option) []option.Option {
-	return append(opts, withSaasHeader())
+	result := make([]option.Option, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, withSaasHeader())
 }
 
+// withSaasHeader is applied after the options of caller, the headers passed
+// by caller are merged into a new map, so they're never modified
 func withSaasHeader() option.Option {
 	const (
 		HTTPHeaderServerFrom = "Server-From"
 		SaasFlag             = "saas"
 	)
-	return func(opt *option.Options) {
-		if len(opt.Headers) == 0 {
-			opt.Headers = map[string]string{HTTPHeaderServerFrom: SaasFlag}
-			return
-		}
-		opt.Headers[HTTPHeaderServerFrom] = SaasFlag
-	}
+	return option.WithHeaders(map[string]string{HTTPHeaderServerFrom: SaasFlag})
 }
 
 func (c *clientImpl) doWrite(request *protocol.WriteDataRequest, url string, opts ...option.Option) (*protocol.WriteResponse, error) {
@@ -100,7 +120,7 @@ func (c *clientImpl) doWrite(request *protocol.WriteDataRequest, url string, opt
 	}
 	response := &protocol.WriteResponse{}
 	opts = addSaasFlag(opts)
-	err := c.hCaller.DoPbRequest(url, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(url, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -127,9 +147,23 @@ func (c *clientImpl) Predict(request *protocol.PredictRequest, opts ...option.Op
 	if len(opts) == 0 {
 		opts = make([]option.Option, 0, predictInitOptionCount)
 	}
-	response := &protocol.PredictResponse{}
 	opts = addSaasFlag(opts)
-	err := c.hCaller.DoPbRequest(c.su.predictURL, request, response, option.Conv2Options(opts...))
+	var response *protocol.PredictResponse
+	err := c.scenes.Execute(request.GetScene().GetSceneName(), func(sceneConf *SceneConfig) error {
+		var err error
+		response, err = c.doPredict(withScene(request, sceneConf), sceneConf, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *clientImpl) doPredict(request *protocol.PredictRequest, scene *SceneConfig,
+	opts []option.Option) (*protocol.PredictResponse, error) {
+	response := &protocol.PredictResponse{}
+	err := c.hCaller.DoPbRequest(c.su.predictURL, request, response, c.hCaller.Options(scene.WithOptions(opts)...))
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +171,23 @@ func (c *clientImpl) Predict(request *protocol.PredictRequest, opts ...option.Op
 	return response, nil
 }
 
+// withScene fills the defaults of scene into a copy of request, and
+// the scene name as well, which differs from request when falling back
+func withScene(request *protocol.PredictRequest, scene *SceneConfig) *protocol.PredictRequest {
+	filled := ApplySceneDefaults(request, scene).(*protocol.PredictRequest)
+	if filled.GetScene().GetSceneName() == scene.Name {
+		return filled
+	}
+	if filled == request {
+		filled = proto.Clone(request).(*protocol.PredictRequest)
+	}
+	if filled.Scene == nil {
+		filled.Scene = &protocol.Scene{}
+	}
+	filled.Scene.SceneName = scene.Name
+	return filled
+}
+
 func (c *clientImpl) AckServerImpressions(request *protocol.AckServerImpressionsRequest,
 	opts ...option.Option) (*protocol.AckServerImpressionsResponse, error) {
 	if err := checkProjectIdAndModelId(request.ProjectId, request.ModelId); err != nil {
@@ -147,7 +198,7 @@ func (c *clientImpl) AckServerImpressions(request *protocol.AckServerImpressions
 	}
 	response := &protocol.AckServerImpressionsResponse{}
 	opts = addSaasFlag(opts)
-	err := c.hCaller.DoPbRequest(c.su.ackImpressionURL, request, response, option.Conv2Options(opts...))
+	err := c.hCaller.DoPbRequest(c.su.ackImpressionURL, request, response, c.hCaller.Options(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,36 @@
+package saas
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+)
+
+func TestAddSaasFlag(t *testing.T) {
+	context, err := NewContext(&ContextParam{
+		Tenant:         saasTenant,
+		TenantId:       "1234",
+		AK:             "ak",
+		SK:             "sk",
+		Region:         RegionSaasSg,
+		DefaultOptions: []option.Option{option.WithHeaders(map[string]string{"Env": "prod"})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := NewHttpCaller(context)
+	callHeaders := map[string]string{"Team": "rec"}
+	// the spare capacity should not be written by addSaasFlag
+	opts := make([]option.Option, 1, 2)
+	opts[0] = option.WithHeaders(callHeaders)
+	options := caller.Options(addSaasFlag(opts)...)
+	expected := map[string]string{"Env": "prod", "Team": "rec", "Server-From": "saas"}
+	if !reflect.DeepEqual(options.Headers, expected) {
+		t.Fatalf("unexpected headers %v", options.Headers)
+	}
+	if len(callHeaders) != 1 || opts[:2][1] != nil {
+		t.Fatal("the headers and options of caller should not be modified")
+	}
+}
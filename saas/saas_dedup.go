@@ -0,0 +1,84 @@
+package saas
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// NewUserEventDeduper
+//
+// Creates a deduper dropping the user events sent within the window of config,
+// the server doesn't deduplicate user events. Key is DataKeyFields(DefaultDataKeyFields...)
+// if nil, the data can't be decoded as json object is identified by itself.
+func NewUserEventDeduper(client Client, config *DedupConfig, key DataKeyFunc) *UserEventDeduper {
+	if key == nil {
+		key = DataKeyFields(DefaultDataKeyFields...)
+	}
+	return &UserEventDeduper{client: client, key: key, deduplicator: NewDeduplicator(config)}
+}
+
+// UserEventDeduper drops duplicated user events before WriteUserEvents,
+// the events failed to write are forgotten, so that they could be retried
+type UserEventDeduper struct {
+	client       Client
+	key          DataKeyFunc
+	deduplicator *Deduplicator
+}
+
+// WriteUserEvents writes the user events not sent before,
+// success is returned without calling server if all of them are duplicated
+func (receiver *UserEventDeduper) WriteUserEvents(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*protocol.WriteResponse, error) {
+	if err := checkProjectIdAndStage(request.ProjectId, request.Stage); err != nil {
+		return nil, err
+	}
+	dataList := request.GetData()
+	keys := make([]string, len(dataList))
+	for i, data := range dataList {
+		keys[i] = receiver.dataKey(data)
+	}
+	var response *protocol.WriteResponse
+	sent, err := receiver.deduplicator.Write(keys, func(kept []int) (*Status, []string, error) {
+		keptData := make([]string, len(kept))
+		for i, idx := range kept {
+			keptData[i] = dataList[idx]
+		}
+		dedupRequest := &protocol.WriteDataRequest{
+			ProjectId: request.GetProjectId(),
+			Stage:     request.GetStage(),
+			Data:      keptData,
+			Extra:     request.GetExtra(),
+		}
+		var err error
+		response, err = receiver.client.WriteUserEvents(dedupRequest, opts...)
+		failedKeys := make([]string, len(response.GetErrors()))
+		for i, dataError := range response.GetErrors() {
+			failedKeys[i] = receiver.dataKey(dataError.GetData())
+		}
+		return response.GetStatus(), failedKeys, err
+	})
+	if !sent {
+		return &protocol.WriteResponse{Status: &Status{Code: StatusCodeSuccess}}, nil
+	}
+	return response, err
+}
+
+// Dropped returns the count of duplicated user events dropped
+func (receiver *UserEventDeduper) Dropped() int64 {
+	return receiver.deduplicator.Dropped()
+}
+
+func (receiver *UserEventDeduper) dataKey(data string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	var dataMap map[string]interface{}
+	if err := decoder.Decode(&dataMap); err != nil {
+		return data
+	}
+	return receiver.key(dataMap)
+}
@@ -0,0 +1,78 @@
+package saas
+
+import (
+	"reflect"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// NewResubmitter
+//
+// Creates a resubmitter which resends the retryable failed data of writes with backoff,
+// and routes the permanent failures into the sink of config.
+func NewResubmitter(client Client, config *ResubmitConfig) *Resubmitter {
+	return &Resubmitter{client: client, runner: NewResubmitRunner(config)}
+}
+
+// Resubmitter writes at most 2000 data at a time like Client, the opts of
+// writes should not contain request id, as every attempt needs a new one
+type Resubmitter struct {
+	client Client
+	runner *ResubmitRunner
+}
+
+func (receiver *Resubmitter) WriteUsers(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	return receiver.write(TopicUser, receiver.client.WriteUsers, request, opts)
+}
+
+func (receiver *Resubmitter) WriteProducts(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	return receiver.write(TopicProduct, receiver.client.WriteProducts, request, opts)
+}
+
+func (receiver *Resubmitter) WriteUserEvents(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*ResubmitReport, error) {
+	return receiver.write(TopicUserEvent, receiver.client.WriteUserEvents, request, opts)
+}
+
+func (receiver *Resubmitter) write(topic string, write writeDataFunc,
+	request *protocol.WriteDataRequest, opts []option.Option) (*ResubmitReport, error) {
+	// the request without project or stage never succeeds
+	if err := checkProjectIdAndStage(request.ProjectId, request.Stage); err != nil {
+		return nil, err
+	}
+	dataList := request.GetData()
+	item := func(index int) interface{} { return dataList[index] }
+	send := func(indexes []int, requestId string) (*Status, []*ItemFailure, error) {
+		subset := make([]string, len(indexes))
+		for i, index := range indexes {
+			subset[i] = dataList[index]
+		}
+		subsetRequest := &protocol.WriteDataRequest{
+			ProjectId: request.GetProjectId(),
+			Stage:     request.GetStage(),
+			Data:      subset,
+			Extra:     request.GetExtra(),
+		}
+		response, err := write(subsetRequest, ResubmitOptions(opts, requestId)...)
+		dataErrors := response.GetErrors()
+		matched := MatchResubmitIndexes(indexes, len(dataErrors), func(failedIdx, itemIdx int) bool {
+			failed, data := dataErrors[failedIdx].GetData(), dataList[itemIdx]
+			if failed == data {
+				return true
+			}
+			normalizedFailed := NormalizeJsonString(failed)
+			return normalizedFailed != nil && reflect.DeepEqual(normalizedFailed, NormalizeJsonString(data))
+		})
+		failures := make([]*ItemFailure, len(dataErrors))
+		for i, dataError := range dataErrors {
+			failures[i] = &ItemFailure{Index: matched[i], Item: dataError.GetData(), Message: dataError.GetMessage()}
+		}
+		return response.GetStatus(), failures, err
+	}
+	return receiver.runner.Run(topic, len(dataList), item, send)
+}
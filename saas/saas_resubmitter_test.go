@@ -0,0 +1,50 @@
+package saas
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+// fakeResubmitClient returns the failed data with keys reordered like server,
+// the first write of every data fails with a retryable error
+type fakeResubmitClient struct {
+	Client
+	writes int
+}
+
+func (c *fakeResubmitClient) WriteUsers(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*protocol.WriteResponse, error) {
+	c.writes++
+	if c.writes > 1 {
+		return &protocol.WriteResponse{Status: &Status{Code: StatusCodeSuccess}}, nil
+	}
+	return &protocol.WriteResponse{
+		Status: &Status{Code: 1001},
+		Errors: []*protocol.DataError{{Message: "server busy", Data: `{"gender":"m","user_id":"u1"}`}},
+	}, nil
+}
+
+func TestResubmitterWriteUsers(t *testing.T) {
+	client := &fakeResubmitClient{}
+	resubmitter := NewResubmitter(client, &ResubmitConfig{Backoff: &Backoff{Initial: time.Millisecond}})
+	request := &protocol.WriteDataRequest{
+		ProjectId: "p",
+		Stage:     StageIncremental,
+		Data:      []string{`{"user_id":"u0"}`, `{"user_id":"u1","gender":"m"}`},
+	}
+	report, err := resubmitter.WriteUsers(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.writes != 2 || report.SuccessCount != 2 || len(report.DeadLetters) != 0 {
+		t.Fatalf("expect the busy data resent, writes:%d report:%+v", client.writes, report)
+	}
+	if _, err := resubmitter.WriteUsers(&protocol.WriteDataRequest{ProjectId: "p"}); !IsPermanentError(err) {
+		t.Fatalf("expect permanent error without stage, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package saas
+
+import (
+	"errors"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/logs"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	spoolMethodWriteUsers      = "WriteUsers"
+	spoolMethodWriteProducts   = "WriteProducts"
+	spoolMethodWriteUserEvents = "WriteUserEvents"
+)
+
+// NewSpooledWriter opens the spool in config.Dir, the requests that were
+// not sent successfully before last exit will be sent again through client.
+// The spool is closed when client released, or it could be closed by caller.
+// The opts are used by every request, so it should not contain request id.
+func NewSpooledWriter(client Client, config *SpoolConfig, opts ...option.Option) (*SpooledWriter, error) {
+	writer := &SpooledWriter{client: client, opts: opts}
+	spool, err := OpenSpool(config, writer.send)
+	if err != nil {
+		return nil, err
+	}
+	writer.spool = spool
+	if impl, ok := client.(*clientImpl); ok {
+		impl.hooks.Add(writer.Close)
+	}
+	return writer, nil
+}
+
+// SpooledWriter persists write requests into local spool before sending them,
+// the request is deleted from spool after server returns success.
+// Requests with more than 2000 items are split into chunks.
+type SpooledWriter struct {
+	client Client
+	opts   []option.Option
+	spool  *Spool
+}
+
+func (receiver *SpooledWriter) WriteUsers(request *protocol.WriteDataRequest) error {
+	return receiver.append(spoolMethodWriteUsers, request)
+}
+
+func (receiver *SpooledWriter) WriteProducts(request *protocol.WriteDataRequest) error {
+	return receiver.append(spoolMethodWriteProducts, request)
+}
+
+func (receiver *SpooledWriter) WriteUserEvents(request *protocol.WriteDataRequest) error {
+	return receiver.append(spoolMethodWriteUserEvents, request)
+}
+
+// Pending returns the count of requests not sent successfully
+func (receiver *SpooledWriter) Pending() int {
+	return receiver.spool.Pending()
+}
+
+// Close stops sending, the unsent requests will be sent after reopened
+func (receiver *SpooledWriter) Close() {
+	receiver.spool.Close()
+}
+
+func (receiver *SpooledWriter) append(method string, request *protocol.WriteDataRequest) error {
+	// reject it before persisted, otherwise it will never succeed
+	if err := checkProjectIdAndStage(request.ProjectId, request.Stage); err != nil {
+		return err
+	}
+	dataList := request.GetData()
+	for _, chunk := range SplitChunks(len(dataList), MaxImportWriteCount) {
+		body, err := proto.Marshal(&protocol.WriteDataRequest{
+			ProjectId: request.GetProjectId(),
+			Stage:     request.GetStage(),
+			Data:      dataList[chunk.Start:chunk.End],
+			Extra:     request.GetExtra(),
+		})
+		if err != nil {
+			return err
+		}
+		payload, err := EncodeSpoolEnvelope(&SpoolEnvelope{
+			Method:    method,
+			RequestId: uuid.NewString(),
+			Body:      body,
+		})
+		if err != nil {
+			return err
+		}
+		if err := receiver.spool.Append(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (receiver *SpooledWriter) send(payload []byte) error {
+	envelope, err := DecodeSpoolEnvelope(payload)
+	if err != nil {
+		return skipSpooledRequest(nil, err)
+	}
+	var write writeDataFunc
+	switch envelope.Method {
+	case spoolMethodWriteUsers:
+		write = receiver.client.WriteUsers
+	case spoolMethodWriteProducts:
+		write = receiver.client.WriteProducts
+	case spoolMethodWriteUserEvents:
+		write = receiver.client.WriteUserEvents
+	default:
+		return skipSpooledRequest(envelope, errors.New("unknown method"))
+	}
+	request := &protocol.WriteDataRequest{}
+	if err := proto.Unmarshal(envelope.Body, request); err != nil {
+		return skipSpooledRequest(envelope, err)
+	}
+	opts := make([]option.Option, 0, len(receiver.opts)+1)
+	opts = append(opts, receiver.opts...)
+	opts = append(opts, option.WithRequestId(envelope.RequestId))
+	response, err := write(request, opts...)
+	return SpoolSendResult(envelope, response.GetStatus(), err)
+}
+
+// The broken request never succeeds, it's skipped to avoid blocking the later ones
+func skipSpooledRequest(envelope *SpoolEnvelope, err error) error {
+	logs.Error("skip broken spooled request, method:%s err:%s", envelope.GetMethod(), err.Error())
+	return nil
+}
@@ -0,0 +1,169 @@
+package saas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The stages of WriteDataRequest accepted by server
+const (
+	StageTrial       = "trial"
+	StageHistorical  = "historical"
+	StageIncremental = "incremental"
+)
+
+// WriteProductItems
+//
+// Encodes products by EncodeWriteData, then writes them by ChunkWriteProducts.
+// Products could be []*protocol.Product, or a slice of structs tagged by `data`.
+// The Index of errors is the position of item in products.
+// Stage should be one of StageTrial, StageHistorical and StageIncremental.
+func WriteProductItems(client Client, projectId string, stage string, products interface{},
+	concurrency int, opts ...option.Option) (*ChunkWriteResponse, error) {
+	return writeItems(client.WriteProducts, projectId, stage, products, concurrency, opts)
+}
+
+// WriteUserItems
+//
+// Same as WriteProductItems, but writes users, which could be
+// a slice of messages or structs.
+func WriteUserItems(client Client, projectId string, stage string, users interface{},
+	concurrency int, opts ...option.Option) (*ChunkWriteResponse, error) {
+	return writeItems(client.WriteUsers, projectId, stage, users, concurrency, opts)
+}
+
+// WriteUserEventItems
+//
+// Same as WriteProductItems, but writes user events, the Scene and Device
+// messages could be used as the fields of user event struct.
+func WriteUserEventItems(client Client, projectId string, stage string, userEvents interface{},
+	concurrency int, opts ...option.Option) (*ChunkWriteResponse, error) {
+	return writeItems(client.WriteUserEvents, projectId, stage, userEvents, concurrency, opts)
+}
+
+func writeItems(write writeDataFunc, projectId string, stage string, items interface{},
+	concurrency int, opts []option.Option) (*ChunkWriteResponse, error) {
+	if err := checkProjectIdAndStage(projectId, stage); err != nil {
+		return nil, err
+	}
+	if err := checkStage(stage); err != nil {
+		return nil, err
+	}
+	dataList, err := EncodeWriteData(items)
+	if err != nil {
+		return nil, err
+	}
+	request := &protocol.WriteDataRequest{ProjectId: projectId, Stage: stage, Data: dataList}
+	return chunkWriteData(write, request, concurrency, opts)
+}
+
+func checkStage(stage string) error {
+	switch stage {
+	case StageTrial, StageHistorical, StageIncremental:
+		return nil
+	}
+	return NewPermanentError(fmt.Sprintf("unknown stage '%s', expect one of %s, %s and %s",
+		stage, StageTrial, StageHistorical, StageIncremental))
+}
+
+// EncodeWriteData converts items into the json data of WriteDataRequest.
+// The proto message is encoded with the proto field names, such as "product_id",
+// and the integers are kept as numbers. Other items are encoded by EncodeData,
+// so the message fields of struct, such as *protocol.Scene, are encoded the same way.
+func EncodeWriteData(items interface{}) ([]string, error) {
+	slice := reflect.ValueOf(items)
+	if slice.Kind() != reflect.Slice {
+		return nil, errors.New(fmt.Sprintf("expect slice of item, got %T", items))
+	}
+	dataList := make([]string, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		item := slice.Index(i).Interface()
+		data, err := encodeItem(item)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("[%d]: %s", i, err.Error()))
+		}
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("[%d]: %s", i, err.Error()))
+		}
+		dataList[i] = string(bytes)
+	}
+	return dataList, nil
+}
+
+func encodeItem(item interface{}) (interface{}, error) {
+	if message, ok := item.(proto.Message); ok {
+		if message == nil || !message.ProtoReflect().IsValid() {
+			return nil, errors.New("item is nil")
+		}
+		return messageData(message.ProtoReflect()), nil
+	}
+	dataList, err := EncodeData([]interface{}{item})
+	if err != nil {
+		return nil, err
+	}
+	data := dataList[0]
+	for name, value := range data {
+		if message, ok := value.(proto.Message); ok {
+			if message.ProtoReflect().IsValid() {
+				data[name] = messageData(message.ProtoReflect())
+			} else {
+				data[name] = nil
+			}
+		}
+	}
+	return data, nil
+}
+
+// messageData walks the populated fields of message like protojson with
+// UseProtoNames, but int64 is not quoted, which is expected by server
+func messageData(message protoreflect.Message) map[string]interface{} {
+	data := make(map[string]interface{})
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		name := string(field.Name())
+		switch {
+		case field.IsMap():
+			mapData := make(map[string]interface{}, value.Map().Len())
+			value.Map().Range(func(key protoreflect.MapKey, mapValue protoreflect.Value) bool {
+				mapData[key.String()] = scalarData(field.MapValue(), mapValue)
+				return true
+			})
+			data[name] = mapData
+		case field.IsList():
+			list := value.List()
+			listData := make([]interface{}, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				listData[i] = scalarData(field, list.Get(i))
+			}
+			data[name] = listData
+		default:
+			data[name] = scalarData(field, value)
+		}
+		return true
+	})
+	return data
+}
+
+func scalarData(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageData(value.Message())
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return int32(value.Enum())
+	case protoreflect.BytesKind:
+		return value.Bytes()
+	default:
+		return value.Interface()
+	}
+}
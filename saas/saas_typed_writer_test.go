@@ -0,0 +1,83 @@
+package saas
+
+import (
+	"testing"
+
+	. "github.com/byteplus-sdk/sdk-go/common/protocol"
+	. "github.com/byteplus-sdk/sdk-go/core"
+	"github.com/byteplus-sdk/sdk-go/core/option"
+	"github.com/byteplus-sdk/sdk-go/saas/protocol"
+)
+
+func TestEncodeWriteData(t *testing.T) {
+	products := []*protocol.Product{{
+		ProductId: "p1",
+		Price:     &protocol.Product_Price{CurrentPrice: 100},
+		Extra:     map[string]string{"color": "red"},
+	}}
+	dataList, err := EncodeWriteData(products)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"extra":{"color":"red"},"price":{"current_price":100},"product_id":"p1"}`
+	if dataList[0] != expected {
+		t.Fatalf("unexpected data %s", dataList[0])
+	}
+
+	type userEvent struct {
+		UserId string           `data:"user_id"`
+		Scene  *protocol.Scene  `data:"scene"`
+		Device *protocol.Device `data:"device,omitempty"`
+	}
+	events := []userEvent{{UserId: "u1", Scene: &protocol.Scene{SceneName: "home", Offset: 3}}}
+	dataList, err = EncodeWriteData(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"scene":{"offset":3,"scene_name":"home"},"user_id":"u1"}`
+	if dataList[0] != expected {
+		t.Fatalf("unexpected data %s", dataList[0])
+	}
+}
+
+// fakeTypedWriteClient fails the data of user "u2", which is returned with keys reordered
+type fakeTypedWriteClient struct {
+	Client
+	requests []*protocol.WriteDataRequest
+}
+
+func (c *fakeTypedWriteClient) WriteUsers(request *protocol.WriteDataRequest,
+	opts ...option.Option) (*protocol.WriteResponse, error) {
+	c.requests = append(c.requests, request)
+	return &protocol.WriteResponse{
+		Status: &Status{Code: 1001},
+		Errors: []*protocol.DataError{{Message: "invalid age", Data: `{"user_id":"u2","age":-1}`}},
+	}, nil
+}
+
+func TestWriteUserItems(t *testing.T) {
+	type user struct {
+		UserId string `data:"user_id"`
+		Age    int64  `data:"age"`
+	}
+	users := []user{{UserId: "u1", Age: 20}, {UserId: "u2", Age: -1}}
+	client := &fakeTypedWriteClient{}
+	response, err := WriteUserItems(client, "p", StageIncremental, users, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.requests) != 1 || client.requests[0].GetStage() != StageIncremental {
+		t.Fatalf("unexpected requests %v", client.requests)
+	}
+	if data := client.requests[0].GetData(); len(data) != 2 || data[1] != `{"age":-1,"user_id":"u2"}` {
+		t.Fatalf("unexpected data %v", data)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Index != 1 {
+		t.Fatalf("expect error of the second user, got %v", response.Errors)
+	}
+
+	_, err = WriteUserItems(client, "p", "online", users, 1)
+	if !IsPermanentError(err) || len(client.requests) != 1 {
+		t.Fatalf("expect permanent error of unknown stage without write, got %v", err)
+	}
+}